@@ -2,14 +2,20 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
 	"github.com/project-code-io/crypto-trading-bot-go/generator"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
 	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/portfolio"
+	"github.com/project-code-io/crypto-trading-bot-go/strategy"
 	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
 
@@ -21,8 +27,23 @@ type App struct {
 	pair        trading.Pair
 	prefix      string
 	idGenerator IDGenerator
+	clock       Clock
+	strategy    strategy.Strategy
+	markets     *trading.Registry
+	normalizer  *order.Normalizer
+	triggers    *triggerEngine
+	expiries    *expiryCanceller
+	links       *linkManager
+	trailing    *trailingTracker
+	orders      *orderTracker
+	portfolio   *portfolio.Portfolio
+	marketData  *marketFeed
 }
 
+// ErrUnknownIntent describes an error in which a strategy returns an intent
+// that the application does not know how to carry out.
+var ErrUnknownIntent = errors.New("unknown intent")
+
 // New acts as the default constructor for the application. Use this method
 // to create a new instance of the applcation. This method should
 // be called over directly instantiating the App struct as it initializes
@@ -33,7 +54,16 @@ func New(logger *zap.Logger, exchange ExchangeClient, opts ...Option) *App {
 		pair:        trading.BTCUSD,
 		exchange:    exchange,
 		prefix:      "go-trading-bot",
-		idGenerator: &generator.RandomUUIDGenerator{},
+		idGenerator: &generator.RandomIDGenerator{},
+		clock:       &generator.SystemClock{},
+		strategy:    strategy.NewHalfPrice(),
+		normalizer:  order.NewNormalizer(),
+		triggers:    &triggerEngine{},
+		expiries:    &expiryCanceller{},
+		links:       &linkManager{},
+		trailing:    &trailingTracker{},
+		orders:      &orderTracker{},
+		marketData:  &marketFeed{},
 	}
 
 	for _, opt := range opts {
@@ -49,24 +79,55 @@ func New(logger *zap.Logger, exchange ExchangeClient, opts ...Option) *App {
 func (a *App) Start(ctx context.Context) {
 	a.logger.Info("application starting")
 
+	if err := a.restoreStops(); err != nil {
+		a.logger.Error("could not restore stop orders", zap.Error(err))
+		return
+	}
+
+	if err := a.restoreLinks(); err != nil {
+		a.logger.Error("could not restore linked orders", zap.Error(err))
+		return
+	}
+
+	if err := a.restoreTrailingStops(); err != nil {
+		a.logger.Error("could not restore trailing stops", zap.Error(err))
+		return
+	}
+
 	if err := a.clearOldOrders(ctx); err != nil {
 		a.logger.Error("could not clear old olders", zap.Error(err))
 		return
 	}
 
+	events, err := a.marketData.subscribe(ctx, a.pair)
+	if err != nil {
+		a.logger.Error("could not subscribe to market data", zap.Error(err))
+		return
+	}
+
+	a.run(ctx, events)
+}
+
+// run ticks once per second and handles the events of the market data stream
+// as they arrive, until the context is cancelled or an error that the
+// application cannot recover from occurs. The wait for the next tick is only
+// started again once a tick has run, so that a stream whose events arrive
+// more often than once per second does not hold the ticks off.
+func (a *App) run(ctx context.Context, events <-chan marketdata.Event) {
+	next := a.clock.After(time.Second)
+
 	for {
 		select {
-		case <-time.After(time.Second):
-			price, err := a.exchange.GetLastPrice(ctx, a.pair)
-			if err != nil {
-				a.logger.Error("failed to get price", zap.Any("pair", a.pair), zap.Error(err))
-				break
+		case <-next:
+			if err := a.Tick(ctx); err != nil {
+				a.logger.Error("failed to run trading loop, exiting early", zap.Error(err))
+				return
 			}
 
-			a.logger.Info("last price", zap.String("price", price), zap.Any("pair", a.pair))
-
-			if err := a.createAndClearOrder(ctx, price); err != nil {
-				a.logger.Error("failed to create and clear order, exiting early", zap.Error(err))
+			next = a.clock.After(time.Second)
+		case e := <-events:
+			if err := a.consumeMarketData(ctx, e); err != nil {
+				a.logger.Error("failed to handle market data, exiting early", zap.Error(err))
 				return
 			}
 		case <-ctx.Done():
@@ -76,6 +137,100 @@ func (a *App) Start(ctx context.Context) {
 	}
 }
 
+// Tick runs a single iteration of the trading loop, which Start calls once
+// per second. Errors that the application can recover from are handled
+// within, any other error is returned. The last price is taken from the
+// market data stream once it has streamed one. This method is exported so
+// that the loop can be driven by something other than the wall clock, such as
+// a backtest.
+func (a *App) Tick(ctx context.Context) error {
+	price, err := a.lastPrice(ctx)
+	if err != nil {
+		a.logger.Error("failed to get price", zap.Any("pair", a.pair), zap.Error(err))
+
+		if errors.Is(err, exchange.ErrAuthFailed) {
+			return fmt.Errorf("get last price: %w", err)
+		}
+
+		a.backoff(ctx, err)
+
+		return nil
+	}
+
+	a.logger.Info("last price", zap.String("price", price), zap.Any("pair", a.pair))
+
+	if err := a.runStrategy(ctx, price); err != nil && !a.handleError(ctx, err) {
+		return fmt.Errorf("run strategy: %w", err)
+	}
+
+	return nil
+}
+
+// handleError decides how the application reacts to an error from the
+// exchange. It returns true if the application can carry on running, once any
+// backoff that the error calls for has passed.
+func (a *App) handleError(ctx context.Context, err error) bool {
+	switch {
+	case errors.Is(err, exchange.ErrRateLimited), errors.Is(err, exchange.ErrMaintenance):
+		a.backoff(ctx, err)
+		return true
+	case errors.Is(err, exchange.ErrInsufficientFunds), errors.Is(err, exchange.ErrInvalidOrder):
+		a.logger.Warn("order rejected by exchange", zap.Error(err))
+		return true
+	case errors.Is(err, order.ErrInvalidPrice),
+		errors.Is(err, order.ErrMarketSize),
+		errors.Is(err, order.ErrNegativeAmount),
+		errors.Is(err, order.ErrInvalidDirection),
+		errors.Is(err, order.ErrInvalidTimeInForce),
+		errors.Is(err, order.ErrInvalidOCO),
+		errors.Is(err, order.ErrBelowMinSize),
+		errors.Is(err, order.ErrBelowMinNotional):
+		a.logger.Warn("order rejected by market filters", zap.Error(err))
+		return true
+	case errors.Is(err, exchange.ErrUnknownOrder):
+		a.logger.Info("order no longer exists on exchange", zap.Error(err))
+		return true
+	case errors.Is(err, ErrLegOpen):
+		a.logger.Warn("oco leg not cancelled yet, retrying on the next tick", zap.Error(err))
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff waits before the next request to the exchange when the error shows
+// that the exchange is rate limiting or unavailable. The wait time is taken
+// from the error if the exchange has specified one.
+func (a *App) backoff(ctx context.Context, err error) {
+	const (
+		rateLimitWait   = time.Second * 10
+		maintenanceWait = time.Minute
+	)
+
+	var wait time.Duration
+
+	switch {
+	case errors.Is(err, exchange.ErrRateLimited):
+		wait = rateLimitWait
+	case errors.Is(err, exchange.ErrMaintenance):
+		wait = maintenanceWait
+	default:
+		return
+	}
+
+	var apiErr *exchange.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		wait = apiErr.RetryAfter
+	}
+
+	a.logger.Warn("backing off from exchange", zap.Duration("wait", wait), zap.Error(err))
+
+	select {
+	case <-a.clock.After(wait):
+	case <-ctx.Done():
+	}
+}
+
 func (a *App) clearOldOrders(ctx context.Context) error {
 	a.logger.Info("clearing old orders")
 
@@ -85,15 +240,26 @@ func (a *App) clearOldOrders(ctx context.Context) error {
 	}
 
 	orderIDs := make([]string, 0)
+	open := make(map[string]bool, len(orders))
+	linked := a.links.orderIDs()
+	stops := a.triggers.orderIDs()
 
 	for _, order := range orders {
-		if !strings.HasPrefix(order.ClientID, a.prefix) {
+		open[order.ID] = true
+
+		if !strings.HasPrefix(order.ClientID, a.prefix) || linked[order.ID] || stops[order.ID] {
 			continue
 		}
 
 		orderIDs = append(orderIDs, order.ID)
 	}
 
+	// The native stops that were filled or cancelled while the app was not
+	// running are no longer held.
+	if err := a.triggers.prune(open); err != nil {
+		return err
+	}
+
 	if err := a.exchange.CancelOrders(ctx, orderIDs...); err != nil {
 		return fmt.Errorf("cancel orders: %w", err)
 	}
@@ -103,59 +269,247 @@ func (a *App) clearOldOrders(ctx context.Context) error {
 	return nil
 }
 
-func (a *App) createAndClearOrder(ctx context.Context, price string) error {
-	const fundUse = float64(0.1)
+// runStrategy brings the portfolio and the orders that the app manages up to
+// date with the price, such as stops, linked orders and the orders that are
+// tracked for the strategy, then passes the tick to the strategy and carries
+// out the intents that it returns.
+func (a *App) runStrategy(ctx context.Context, price string) error {
+	amount, err := trading.ParseAmount(price)
+	if err != nil {
+		return fmt.Errorf("parse price: %w", err)
+	}
+
+	if err := a.cancelExpired(ctx); err != nil {
+		return err
+	}
 
-	a.logger.Info("loading balance")
+	if err := a.updatePortfolio(ctx, amount); err != nil {
+		return err
+	}
 
-	balance, err := a.exchange.GetBalance(ctx, a.pair.Quote)
-	if err != nil {
-		return fmt.Errorf("get balance: %w", err)
+	if err := a.fireStops(ctx, amount); err != nil {
+		return err
 	}
 
-	quoteAmount := balance / int64(1/fundUse)
+	if err := a.trailStops(ctx, amount); err != nil {
+		return err
+	}
 
-	quotePrice, err := a.pair.Quote.UnitStr(price)
+	if err := a.manageLinks(ctx); err != nil {
+		return err
+	}
+
+	if err := a.reconcileOrders(ctx); err != nil {
+		return err
+	}
+
+	tick := strategy.Tick{
+		Pair:  a.pair,
+		Price: amount,
+		Time:  a.clock.Now(),
+	}
+
+	intents, err := a.strategy.OnTick(ctx, a.exchange, tick)
 	if err != nil {
-		return fmt.Errorf("quote price: %w", err)
+		return fmt.Errorf("run strategy: %w", err)
 	}
 
-	// Try to buy at half the current price.
-	const priceDivisor = 2
-	desiredPrice := quotePrice / priceDivisor
+	return a.execute(ctx, intents)
+}
+
+// pendingCancel is an order that is to be cancelled once a delay has passed.
+type pendingCancel struct {
+	orderID string
+	after   time.Duration
+}
 
-	baseSize := a.pair.Base.Unit(float64(quoteAmount) / float64(desiredPrice))
+// execute carries out the intents of the strategy in the order they were
+// given. Orders that are to be cancelled after a delay are cancelled once
+// every intent has been carried out, soonest first.
+func (a *App) execute(ctx context.Context, intents []strategy.Intent) error {
+	start := a.clock.Now()
+	pending := make([]pendingCancel, 0)
 
-	fmt.Println(quoteAmount, "/", desiredPrice, "=", baseSize)
+	for _, intent := range intents {
+		cancelAfter, err := a.carryOut(ctx, intent)
+		if err != nil {
+			return err
+		}
 
-	o := order.Limit{
-		ClientID: a.idGenerator.GenerateID(a.prefix),
-		Pair:     a.pair,
-		Side:     order.SideBuy,
-		BaseSize: a.pair.Base.Format(baseSize),
-		Price:    a.pair.Quote.Format(desiredPrice),
-		PostOnly: true,
+		if cancelAfter != nil {
+			pending = append(pending, *cancelAfter)
+		}
 	}
 
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].after < pending[j].after
+	})
+
+	for _, p := range pending {
+		if wait := p.after - a.clock.Now().Sub(start); wait > 0 {
+			select {
+			case <-a.clock.After(wait):
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		if err := a.exchange.CancelOrders(ctx, p.orderID); err != nil {
+			return fmt.Errorf("cancel order: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// carryOut carries out a single intent of the strategy. A limit order that is
+// to be cancelled after a delay is returned as a pending cancel.
+func (a *App) carryOut(ctx context.Context, intent strategy.Intent) (*pendingCancel, error) {
+	var err error
+
+	switch i := intent.(type) {
+	case strategy.PlaceLimit:
+		eOrder, err := a.placeLimit(ctx, i.Order)
+		if err != nil || i.CancelAfter <= 0 {
+			return nil, err
+		}
+
+		return &pendingCancel{orderID: eOrder.ID, after: i.CancelAfter}, nil
+	case strategy.PlaceMarket:
+		_, err = a.placeMarket(ctx, i.Order)
+	case strategy.PlaceStopLimit:
+		err = a.placeStopLimit(ctx, i.Order)
+	case strategy.PlaceStopMarket:
+		err = a.placeStopMarket(ctx, i.Order)
+	case strategy.PlaceOCO:
+		err = a.placeOCO(ctx, i.Order)
+	case strategy.PlaceBracket:
+		err = a.placeBracket(ctx, i.Order)
+	case strategy.PlaceTrailingStop:
+		err = a.placeTrailingStop(i.Order)
+	case strategy.CancelOrders:
+		err = a.cancelOrders(ctx, i.OrderIDs...)
+	default:
+		err = fmt.Errorf("%T: %w", intent, ErrUnknownIntent)
+	}
+
+	return nil, err
+}
+
+// placeLimit normalizes the order to the filters of its market and tags it
+// with a client ID that the application can later recognise, before placing
+// it on the exchange. Good till date orders on exchanges that do not support
+// them are cancelled by the app once they expire.
+func (a *App) placeLimit(ctx context.Context, o order.Limit) (exchange.Order, error) {
+	o, err := a.normalizer.Normalize(a.market(o.Pair), o)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("normalize order: %w", err)
+	}
+
+	o.ClientID = a.idGenerator.GenerateID(a.prefix)
+
+	eOrder, err := a.createLimit(ctx, o)
+	if errors.Is(err, exchange.ErrUnsupportedOrder) && o.EffectiveTimeInForce() == order.TimeInForceGTD {
+		return a.placeExpiring(ctx, o)
+	}
+
+	return eOrder, err
+}
+
+// createLimit places the limit order on the exchange.
+func (a *App) createLimit(ctx context.Context, o order.Limit) (exchange.Order, error) {
 	a.logger.Info("creating order", zap.Any("order", o))
 
 	eOrder, err := a.exchange.CreateLimitOrder(ctx, o)
 	if err != nil {
-		return fmt.Errorf("create limit order: %w", err)
+		return exchange.Order{}, fmt.Errorf("create limit order: %w", err)
 	}
 
 	a.logger.Info("order created", zap.Any("exchange_order", eOrder))
+	a.trackOrder(eOrder)
 
-	const waitTime = time.Millisecond * 200
+	return eOrder, nil
+}
 
-	select {
-	case <-time.After(waitTime):
-		err := a.exchange.CancelOrders(ctx, eOrder.ID)
-		if err != nil {
-			return fmt.Errorf("cancel order: %w", err)
-		}
-	case <-ctx.Done():
-		break
+// placeMarket normalizes the market order to the filters of its market and
+// tags it with a client ID, before placing it on the exchange.
+func (a *App) placeMarket(ctx context.Context, o order.Market) (exchange.Order, error) {
+	o, err := a.normalizer.NormalizeMarket(a.market(o.Pair), o)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("normalize order: %w", err)
+	}
+
+	o.ClientID = a.idGenerator.GenerateID(a.prefix)
+
+	return a.createMarket(ctx, o)
+}
+
+// createMarket places the market order on the exchange.
+func (a *App) createMarket(ctx context.Context, o order.Market) (exchange.Order, error) {
+	a.logger.Info("creating market order", zap.Any("order", o))
+
+	eOrder, err := a.exchange.CreateMarketOrder(ctx, o)
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("create market order: %w", err)
+	}
+
+	a.logger.Info("order created", zap.Any("exchange_order", eOrder))
+	a.trackOrder(eOrder)
+
+	return eOrder, nil
+}
+
+// clientID returns the client ID that the strategy gave an order, which it
+// can later cancel the order by, or else a new client ID for the app.
+func (a *App) clientID(id string) string {
+	if id != "" {
+		return id
+	}
+
+	return a.idGenerator.GenerateID(a.prefix)
+}
+
+// market returns the market of the pair from the registry of the app. Pairs
+// without a market, or an app without a registry, get a market with no
+// filters.
+func (a *App) market(p trading.Pair) trading.Market {
+	if a.markets == nil {
+		return trading.Market{Pair: p}
+	}
+
+	m, err := a.markets.Market(p)
+	if err != nil {
+		return trading.Market{Pair: p}
+	}
+
+	return m
+}
+
+// cancelOrders stops watching the stops and trailing stops with the given IDs
+// that are tracked by the app, and cancels the rest on the exchange.
+func (a *App) cancelOrders(ctx context.Context, orderIDs ...string) error {
+	a.expiries.remove(orderIDs...)
+
+	if err := a.links.removeEntries(orderIDs...); err != nil {
+		return err
+	}
+
+	remaining, err := a.trailing.remove(orderIDs...)
+	if err != nil {
+		return err
+	}
+
+	remaining, err = a.triggers.remove(remaining...)
+	if err != nil {
+		return err
+	}
+
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	if err := a.exchange.CancelOrders(ctx, remaining...); err != nil {
+		return fmt.Errorf("cancel orders: %w", err)
 	}
 
 	return nil
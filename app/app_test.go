@@ -2,15 +2,24 @@ package app_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
 
 	"github.com/project-code-io/crypto-trading-bot-go/app"
 	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
 	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/orderbook"
+	"github.com/project-code-io/crypto-trading-bot-go/portfolio"
+	"github.com/project-code-io/crypto-trading-bot-go/strategy"
 	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
 
@@ -25,14 +34,22 @@ func TestAppStart(t *testing.T) {
 
 		mockExchange := app.NewmockExchangeClient(ctrl)
 		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil)
+		mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil)
 
 		a := app.New(logger, mockExchange)
 
 		ctx, cancel := context.WithCancel(context.Background())
 
-		go a.Start(ctx)
+		done := make(chan struct{})
+
+		go func() {
+			a.Start(ctx)
+			close(done)
+		}()
+
 		time.Sleep(time.Millisecond * 500)
 		cancel()
+		<-done
 	})
 
 	t.Run("app should call get exchange once per second", func(t *testing.T) {
@@ -45,21 +62,22 @@ func TestAppStart(t *testing.T) {
 
 		mockExchange := app.NewmockExchangeClient(ctrl)
 		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil)
+		mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil)
 		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Times(1).Return("1000.00", nil)
-		mockExchange.EXPECT().GetBalance(gomock.Any(), trading.USD).Times(1).Return(int64(5000), nil)
+		mockExchange.EXPECT().GetBalance(gomock.Any(), trading.USD).Times(1).Return(trading.MustParseAmount("50"), nil)
 
 		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), order.Limit{
 			ClientID: "foobar",
 			Pair:     trading.BTCUSD,
 			Side:     order.SideBuy,
-			BaseSize: "0.01",
-			Price:    "500",
+			BaseSize: trading.MustParseAmount("0.01"),
+			Price:    trading.MustParseAmount("500"),
 			PostOnly: true,
 		}).Times(1).Return(exchange.Order{
 			ID: "myorder",
 		}, nil)
 
-		mockExchange.EXPECT().CancelOrder(gomock.Any(), "myorder").Return(nil)
+		mockExchange.EXPECT().CancelOrders(gomock.Any(), "myorder").Return(nil)
 
 		idGen := app.NewmockIDGenerator(ctrl)
 		idGen.EXPECT().GenerateID("go-trading-bot").Times(1).Return("foobar")
@@ -68,9 +86,1805 @@ func TestAppStart(t *testing.T) {
 
 		ctx, cancel := context.WithCancel(context.Background())
 
-		go a.Start(ctx)
+		done := make(chan struct{})
+
+		go func() {
+			a.Start(ctx)
+			close(done)
+		}()
 
 		time.Sleep(time.Second*1 + time.Millisecond*320)
 		cancel()
+		<-done
+	})
+	t.Run("app should exit when authentication with the exchange fails", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		logger := zaptest.NewLogger(t)
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil)
+		mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Times(1).Return("", &exchange.APIError{
+			StatusCode: http.StatusUnauthorized,
+			Kind:       exchange.ErrAuthFailed,
+		})
+
+		a := app.New(logger, mockExchange)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		done := make(chan struct{})
+
+		go func() {
+			a.Start(ctx)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second * 2):
+			t.Error("app did not exit after authentication failed")
+		}
+	})
+
+	t.Run("app should carry on when the exchange rejects an order", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		logger := zaptest.NewLogger(t)
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil)
+		mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Times(2).Return("1000.00", nil)
+		mockExchange.EXPECT().GetBalance(gomock.Any(), trading.USD).Times(2).Return(trading.MustParseAmount("50"), nil)
+		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), gomock.Any()).Times(2).Return(
+			exchange.Order{}, fmt.Errorf("create order: %w", exchange.ErrInsufficientFunds),
+		)
+
+		a := app.New(logger, mockExchange)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan struct{})
+
+		go func() {
+			a.Start(ctx)
+			close(done)
+		}()
+
+		time.Sleep(time.Second*2 + time.Millisecond*320)
+		cancel()
+		<-done
+	})
+}
+
+type strategyFunc func(ctx context.Context, account strategy.Account, tick strategy.Tick) ([]strategy.Intent, error)
+
+func (f strategyFunc) OnTick(
+	ctx context.Context, account strategy.Account, tick strategy.Tick,
+) ([]strategy.Intent, error) {
+	return f(ctx, account, tick)
+}
+
+func TestAppTick(t *testing.T) {
+	t.Run("app should carry out the intents of the strategy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		logger := zaptest.NewLogger(t)
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.ETHUSD).Return("1500.00", nil)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), order.Limit{
+				ClientID: "foobar",
+				Pair:     trading.ETHUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("1"),
+				Price:    trading.MustParseAmount("1600"),
+			}).Return(exchange.Order{ID: "myorder"}, nil),
+			mockExchange.EXPECT().CancelOrders(gomock.Any(), "oldorder").Return(nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		var ticks []strategy.Tick
+
+		s := strategyFunc(func(
+			ctx context.Context, account strategy.Account, tick strategy.Tick,
+		) ([]strategy.Intent, error) {
+			ticks = append(ticks, tick)
+
+			return []strategy.Intent{
+				strategy.PlaceLimit{
+					Order: order.Limit{
+						Pair:     tick.Pair,
+						Side:     order.SideSell,
+						BaseSize: trading.MustParseAmount("1"),
+						Price:    trading.MustParseAmount("1600"),
+					},
+				},
+				strategy.CancelOrders{OrderIDs: []string{"oldorder"}},
+			}, nil
+		})
+
+		a := app.New(logger, mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithPair(trading.ETHUSD),
+			app.WithStrategy(s),
+		)
+
+		assert.NoError(t, a.Tick(context.Background()))
+
+		assert.Len(t, ticks, 1)
+		assert.Equal(t, trading.ETHUSD, ticks[0].Pair)
+		assert.Equal(t, trading.MustParseAmount("1500"), ticks[0].Price)
+	})
+
+	t.Run("app should normalize orders to the filters of the market", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		logger := zaptest.NewLogger(t)
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil)
+		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), order.Limit{
+			ClientID: "foobar",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideBuy,
+			BaseSize: trading.MustParseAmount("0.0012"),
+			Price:    trading.MustParseAmount("8500.5"),
+		}).Return(exchange.Order{ID: "myorder"}, nil)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		s := strategyFunc(func(
+			ctx context.Context, account strategy.Account, tick strategy.Tick,
+		) ([]strategy.Intent, error) {
+			return []strategy.Intent{
+				strategy.PlaceLimit{
+					Order: order.Limit{
+						Pair:     tick.Pair,
+						Side:     order.SideBuy,
+						BaseSize: trading.MustParseAmount("0.00129999"),
+						Price:    trading.MustParseAmount("8500.99"),
+					},
+				},
+			}, nil
+		})
+
+		markets := trading.NewRegistry(trading.Market{
+			Pair:     trading.BTCUSD,
+			Symbol:   "BTCUSD",
+			TickSize: trading.MustParseAmount("0.5"),
+			LotSize:  trading.MustParseAmount("0.0001"),
+		})
+
+		a := app.New(logger, mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(s),
+			app.WithMarkets(markets),
+		)
+
+		assert.NoError(t, a.Tick(context.Background()))
+	})
+
+	t.Run("app should skip orders below the minimum notional", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		logger := zaptest.NewLogger(t)
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil)
+
+		s := strategyFunc(func(
+			ctx context.Context, account strategy.Account, tick strategy.Tick,
+		) ([]strategy.Intent, error) {
+			return []strategy.Intent{
+				strategy.PlaceLimit{
+					Order: order.Limit{
+						Pair:     tick.Pair,
+						Side:     order.SideBuy,
+						BaseSize: trading.MustParseAmount("0.0001"),
+						Price:    trading.MustParseAmount("8500"),
+					},
+				},
+			}, nil
+		})
+
+		markets := trading.NewRegistry(trading.Market{
+			Pair:        trading.BTCUSD,
+			Symbol:      "BTCUSD",
+			MinNotional: trading.MustParseAmount("10"),
+		})
+
+		a := app.New(logger, mockExchange, app.WithStrategy(s), app.WithMarkets(markets))
+
+		assert.NoError(t, a.Tick(context.Background()))
+	})
+
+	t.Run("app should place market orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		logger := zaptest.NewLogger(t)
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil)
+		mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), order.Market{
+			ClientID:   "foobar",
+			Pair:       trading.BTCUSD,
+			Side:       order.SideSell,
+			BaseSize:   trading.MustParseAmount("0.5"),
+			PriceLimit: trading.MustParseAmount("16830"),
+		}).Return(exchange.Order{ID: "myorder"}, nil)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		s := strategyFunc(func(
+			ctx context.Context, account strategy.Account, tick strategy.Tick,
+		) ([]strategy.Intent, error) {
+			return []strategy.Intent{
+				strategy.PlaceMarket{
+					Order: order.Market{
+						Pair:       tick.Pair,
+						Side:       order.SideSell,
+						BaseSize:   trading.MustParseAmount("0.5"),
+						PriceLimit: tick.Price.Mul(trading.MustParseAmount("0.99")),
+					},
+				},
+			}, nil
+		})
+
+		a := app.New(logger, mockExchange, app.WithIDGenerator(idGen), app.WithStrategy(s))
+
+		assert.NoError(t, a.Tick(context.Background()))
+	})
+
+	t.Run("app should return errors from the strategy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		logger := zaptest.NewLogger(t)
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("1000.00", nil)
+
+		errStrategy := errors.New("strategy failed")
+
+		s := strategyFunc(func(
+			ctx context.Context, account strategy.Account, tick strategy.Tick,
+		) ([]strategy.Intent, error) {
+			return nil, errStrategy
+		})
+
+		a := app.New(logger, mockExchange, app.WithStrategy(s))
+
+		assert.ErrorIs(t, a.Tick(context.Background()), errStrategy)
+	})
+}
+
+// stopExchange is an exchange client that supports stop orders natively.
+type stopExchange struct {
+	app.ExchangeClient
+	app.StopOrderCreator
+}
+
+// intentsOnce returns a strategy that returns the intents on the first tick
+// only.
+func intentsOnce(intents ...strategy.Intent) strategy.Strategy {
+	return strategyFunc(func(
+		ctx context.Context, account strategy.Account, tick strategy.Tick,
+	) ([]strategy.Intent, error) {
+		res := intents
+		intents = nil
+
+		return res, nil
+	})
+}
+
+func TestAppStopOrders(t *testing.T) {
+	stopLoss := order.StopMarket{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.5"),
+		StopPrice: trading.MustParseAmount("16000"),
+		Direction: order.DirectionBelow,
+	}
+
+	t.Run("app should place stop orders natively", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil)
+
+		expected := stopLoss
+		expected.ClientID = "foobar"
+
+		mockStops := app.NewmockStopOrderCreator(ctrl)
+		mockStops.EXPECT().CreateStopMarketOrder(gomock.Any(), expected).Return(exchange.Order{ID: "stop"}, nil)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		a := app.New(zaptest.NewLogger(t), stopExchange{mockExchange, mockStops},
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(strategy.PlaceStopMarket{Order: stopLoss})),
+		)
+
+		assert.NoError(t, a.Tick(context.Background()))
+	})
+
+	t.Run("app should trigger stops that the exchange does not support", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("16000.01", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15990.00", nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), order.Market{
+				ClientID: "foobar",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+			}).Return(exchange.Order{ID: "myorder"}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15000.00", nil),
+		)
+
+		mockStops := app.NewmockStopOrderCreator(ctrl)
+		mockStops.EXPECT().CreateStopMarketOrder(gomock.Any(), gomock.Any()).
+			Return(exchange.Order{}, fmt.Errorf("stop market: %w", exchange.ErrUnsupportedOrder))
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		a := app.New(zaptest.NewLogger(t), stopExchange{mockExchange, mockStops},
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(strategy.PlaceStopMarket{Order: stopLoss})),
+		)
+
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+	})
+
+	t.Run("app should fire stops again when their order could not be placed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clock := app.NewmockClock(ctrl)
+		clock.EXPECT().Now().Return(time.Time{}).AnyTimes()
+		clock.EXPECT().After(10 * time.Second).DoAndReturn(func(time.Duration) <-chan time.Time {
+			c := make(chan time.Time, 1)
+			c <- time.Time{}
+
+			return c
+		})
+
+		first := order.Market{ClientID: "first", Pair: trading.BTCUSD, Side: order.SideSell,
+			BaseSize: trading.MustParseAmount("0.5")}
+		second := first
+		second.ClientID = "second"
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		// The rate limit stops the first stop from firing, which leaves both
+		// stops to fire on the next tick.
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15990.00", nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), first).
+				Return(exchange.Order{}, fmt.Errorf("create order: %w", exchange.ErrRateLimited)),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15990.00", nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), first).Return(exchange.Order{ID: "1"}, nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), second).Return(exchange.Order{ID: "2"}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15000.00", nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		gomock.InOrder(
+			idGen.EXPECT().GenerateID("go-trading-bot").Return("first"),
+			idGen.EXPECT().GenerateID("go-trading-bot").Return("second"),
+		)
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithClock(clock),
+			app.WithStrategy(intentsOnce(
+				strategy.PlaceStopMarket{Order: stopLoss},
+				strategy.PlaceStopMarket{Order: stopLoss},
+			)),
+		)
+
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+	})
+
+	t.Run("app should cancel stops that it is watching", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15000.00", nil)
+		mockExchange.EXPECT().CancelOrders(gomock.Any(), "other").Return(nil)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		stopLimit := order.StopLimit{
+			Pair:      trading.BTCUSD,
+			Side:      order.SideSell,
+			BaseSize:  trading.MustParseAmount("0.5"),
+			Price:     trading.MustParseAmount("15900"),
+			StopPrice: trading.MustParseAmount("16000"),
+			Direction: order.DirectionBelow,
+		}
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(
+				strategy.PlaceStopLimit{Order: stopLimit},
+				strategy.CancelOrders{OrderIDs: []string{"foobar", "other"}},
+			)),
+		)
+
+		assert.NoError(t, a.Tick(context.Background()))
+		assert.NoError(t, a.Tick(context.Background()))
+	})
+
+	t.Run("app should cancel stops by the client ID that the strategy gave them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17500.00", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15000.00", nil),
+		)
+
+		stop := stopLoss
+		stop.ClientID = "my-stop"
+
+		trailing := order.TrailingStop{
+			ClientID:    "my-trail",
+			Pair:        trading.BTCUSD,
+			Side:        order.SideSell,
+			BaseSize:    trading.MustParseAmount("0.5"),
+			TrailAmount: trading.MustParseAmount("500"),
+		}
+
+		ticks := [][]strategy.Intent{
+			{strategy.PlaceStopMarket{Order: stop}, strategy.PlaceTrailingStop{Order: trailing}},
+			{strategy.CancelOrders{OrderIDs: []string{"my-stop", "my-trail"}}},
+		}
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(app.NewmockIDGenerator(ctrl)),
+			app.WithStrategy(strategyFunc(func(
+				ctx context.Context, account strategy.Account, tick strategy.Tick,
+			) ([]strategy.Intent, error) {
+				if len(ticks) == 0 {
+					return nil, nil
+				}
+
+				intents := ticks[0]
+				ticks = ticks[1:]
+
+				return intents, nil
+			})),
+		)
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()), "cancelled stops should not fire")
+		}
+	})
+}
+
+func TestAppRestoreStops(t *testing.T) {
+	stopLoss := order.StopMarket{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.5"),
+		StopPrice: trading.MustParseAmount("16000"),
+		Direction: order.DirectionBelow,
+	}
+
+	start := func(a *app.App) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		a.Start(ctx)
+	}
+
+	t.Run("watched stops should fire after a restart", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := app.NewFileWatchedStopStore(filepath.Join(t.TempDir(), "stops.json"))
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+			mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15990.00", nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), order.Market{
+				ClientID: "foobar",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+			}).Return(exchange.Order{ID: "myorder"}, nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithWatchedStopStore(store),
+			app.WithStrategy(intentsOnce(strategy.PlaceStopMarket{Order: stopLoss})),
+		)
+
+		assert.NoError(t, a.Tick(context.Background()))
+
+		restarted := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithWatchedStopStore(store),
+			app.WithStrategy(intentsOnce()),
+		)
+
+		start(restarted)
+		assert.NoError(t, restarted.Tick(context.Background()))
+
+		stops, err := store.LoadWatchedStops()
+		assert.NoError(t, err)
+		assert.Empty(t, stops)
+	})
+
+	t.Run("native stops should not be cancelled as old orders", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := app.NewFileWatchedStopStore(filepath.Join(t.TempDir(), "stops.json"))
+
+		assert.NoError(t, store.SaveWatchedStops([]app.WatchedStop{{
+			ClientID: "go-trading-bot-filled",
+			Pair:     trading.BTCUSD,
+			OrderID:  "filled",
+		}}))
+
+		expected := stopLoss
+		expected.ClientID = "go-trading-bot-stop"
+
+		open := []exchange.Order{
+			{ID: "stop", ClientID: "go-trading-bot-stop"},
+			{ID: "old", ClientID: "go-trading-bot-old"},
+		}
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockStops := app.NewmockStopOrderCreator(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockStops.EXPECT().CreateStopMarketOrder(gomock.Any(), expected).Return(exchange.Order{ID: "stop"}, nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(open, nil),
+			mockExchange.EXPECT().CancelOrders(gomock.Any(), "old").Return(nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("go-trading-bot-stop")
+
+		a := app.New(zaptest.NewLogger(t), stopExchange{mockExchange, mockStops},
+			app.WithIDGenerator(idGen),
+			app.WithWatchedStopStore(store),
+			app.WithStrategy(intentsOnce(strategy.PlaceStopMarket{Order: stopLoss})),
+		)
+
+		assert.NoError(t, a.Tick(context.Background()))
+
+		start(app.New(zaptest.NewLogger(t), stopExchange{mockExchange, mockStops},
+			app.WithWatchedStopStore(store),
+		))
+
+		stops, err := store.LoadWatchedStops()
+		assert.NoError(t, err)
+		assert.Len(t, stops, 1, "stops that are no longer open should be dropped")
+		assert.Equal(t, "stop", stops[0].OrderID)
 	})
 }
+
+func TestAppExpiringOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	expires := start.Add(time.Minute)
+	now := start
+
+	clock := app.NewmockClock(ctrl)
+	clock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+
+	limit := order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("500"),
+		Expires:  &expires,
+	}
+
+	gtd := limit
+	gtd.ClientID = "foobar"
+
+	gtc := gtd
+	gtc.Expires = nil
+	gtc.TimeInForce = order.TimeInForceGTC
+
+	mockExchange := app.NewmockExchangeClient(ctrl)
+	mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("1000.00", nil).Times(3)
+
+	gomock.InOrder(
+		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), gtd).
+			Return(exchange.Order{}, fmt.Errorf("good till date: %w", exchange.ErrUnsupportedOrder)),
+		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), gtc).Return(exchange.Order{ID: "myorder"}, nil),
+		mockExchange.EXPECT().CancelOrders(gomock.Any(), "myorder").Return(nil),
+	)
+
+	idGen := app.NewmockIDGenerator(ctrl)
+	idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+	a := app.New(zaptest.NewLogger(t), mockExchange,
+		app.WithIDGenerator(idGen),
+		app.WithClock(clock),
+		app.WithStrategy(intentsOnce(strategy.PlaceLimit{Order: limit})),
+	)
+
+	assert.NoError(t, a.Tick(context.Background()))
+
+	now = expires.Add(-time.Second)
+	assert.NoError(t, a.Tick(context.Background()), "order should not be cancelled before it expires")
+
+	now = expires
+	assert.NoError(t, a.Tick(context.Background()))
+}
+
+// ocoExchange is an exchange client that supports OCO orders natively.
+type ocoExchange struct {
+	app.ExchangeClient
+	app.OCOCreator
+}
+
+func TestAppOCOOrders(t *testing.T) {
+	oco := order.OCO{
+		Pair:       trading.BTCUSD,
+		Side:       order.SideSell,
+		BaseSize:   trading.MustParseAmount("0.5"),
+		TakeProfit: trading.MustParseAmount("20000"),
+		StopPrice:  trading.MustParseAmount("16000"),
+	}
+
+	t.Run("app should place oco orders natively", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		legs := []exchange.Order{{ID: "tp", ClientID: "foobar-tp"}, {ID: "sl", ClientID: "foobar-sl"}}
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		expected := oco
+		expected.ClientID = "foobar"
+
+		mockOCO := app.NewmockOCOCreator(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockOCO.EXPECT().CreateOCOOrder(gomock.Any(), expected).
+				Return(exchange.OrderList{ID: "list", Orders: legs}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(legs, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20000.00", nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		store := app.NewFileLinkStore(filepath.Join(t.TempDir(), "links.json"))
+
+		a := app.New(zaptest.NewLogger(t), ocoExchange{mockExchange, mockOCO},
+			app.WithIDGenerator(idGen),
+			app.WithLinkStore(store),
+			app.WithStrategy(intentsOnce(strategy.PlaceOCO{Order: oco})),
+		)
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		links, err := store.LoadLinks()
+		assert.NoError(t, err)
+		assert.Equal(t, []app.Link{{ClientID: "foobar", ListID: "list", OrderIDs: []string{"tp", "sl"}}}, links)
+
+		assert.NoError(t, a.Tick(context.Background()), "exchange should cancel the other leg itself")
+
+		links, err = store.LoadLinks()
+		assert.NoError(t, err)
+		assert.Empty(t, links)
+	})
+
+	t.Run("app should keep native oco orders across a restart", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		store := app.NewFileLinkStore(filepath.Join(t.TempDir(), "links.json"))
+
+		assert.NoError(t, store.SaveLinks([]app.Link{{
+			ClientID: "go-trading-bot-oco",
+			ListID:   "list",
+			OrderIDs: []string{"tp", "sl"},
+		}}))
+
+		open := []exchange.Order{
+			{ID: "tp", ClientID: "go-trading-bot-oco-tp"},
+			{ID: "sl", ClientID: "go-trading-bot-oco-sl"},
+			{ID: "old", ClientID: "go-trading-bot-old"},
+		}
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(open, nil)
+		mockExchange.EXPECT().CancelOrders(gomock.Any(), "old").Return(nil)
+
+		a := app.New(zaptest.NewLogger(t), ocoExchange{mockExchange, app.NewmockOCOCreator(ctrl)},
+			app.WithLinkStore(store),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		a.Start(ctx)
+	})
+
+	t.Run("app should cancel the take profit once the stop fires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), order.Limit{
+				ClientID: "foobar-tp",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+				Price:    trading.MustParseAmount("20000"),
+			}).Return(exchange.Order{ID: "tp"}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17500.00", nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{{ID: "tp"}}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15990.00", nil),
+			mockExchange.EXPECT().CancelOrders(gomock.Any(), "tp").Return(nil),
+			mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "tp").
+				Return(exchange.Order{ID: "tp", Status: order.StatusCancelled}, nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), order.Market{
+				ClientID: "foobar-sl",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+			}).Return(exchange.Order{ID: "sl"}, nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15000.00", nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(strategy.PlaceOCO{Order: oco})),
+		)
+
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+	})
+
+	t.Run("app should wait for the take profit to be cancelled before the stop sells", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		sl := order.Market{
+			ClientID: "foobar-sl",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideSell,
+			BaseSize: trading.MustParseAmount("0.3"),
+		}
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: "tp"}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15990.00", nil),
+			mockExchange.EXPECT().CancelOrders(gomock.Any(), "tp").Return(nil),
+			mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "tp").
+				Return(exchange.Order{ID: "tp", Status: order.StatusNew}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15980.00", nil),
+			mockExchange.EXPECT().CancelOrders(gomock.Any(), "tp").Return(nil),
+			mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "tp").Return(exchange.Order{
+				ID:         "tp",
+				Status:     order.StatusCancelled,
+				FilledSize: trading.MustParseAmount("0.2"),
+			}, nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), sl).Return(exchange.Order{ID: "sl"}, nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(strategy.PlaceOCO{Order: oco})),
+		)
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+	})
+
+	t.Run("app should keep watching the stop once the take profit is cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		sl := order.Market{
+			ClientID: "foobar-sl",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideSell,
+			BaseSize: trading.MustParseAmount("0.3"),
+		}
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: "tp"}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15990.00", nil),
+			mockExchange.EXPECT().CancelOrders(gomock.Any(), "tp").Return(nil),
+			mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "tp").Return(exchange.Order{
+				ID:         "tp",
+				Status:     order.StatusCancelled,
+				FilledSize: trading.MustParseAmount("0.2"),
+			}, nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), sl).Return(exchange.Order{}, exchange.ErrInsufficientFunds),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("16500.00", nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15900.00", nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), sl).Return(exchange.Order{ID: "sl"}, nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		store := app.NewFileLinkStore(filepath.Join(t.TempDir(), "links.json"))
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithLinkStore(store),
+			app.WithStrategy(intentsOnce(strategy.PlaceOCO{Order: oco})),
+		)
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		links, err := store.LoadLinks()
+		assert.NoError(t, err)
+		assert.Len(t, links, 1)
+		assert.Empty(t, links[0].OrderIDs)
+		assert.Equal(t, sl, *links[0].Stop.Market, "stop should be left with what the take profit did not fill")
+
+		assert.NoError(t, a.Tick(context.Background()))
+
+		links, err = store.LoadLinks()
+		assert.NoError(t, err)
+		assert.Empty(t, links)
+	})
+
+	t.Run("app should stop watching the stop once the take profit fills", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17000.00", nil),
+			mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: "tp"}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20000.00", nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15000.00", nil),
+		)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		a := app.New(zaptest.NewLogger(t), mockExchange,
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(strategy.PlaceOCO{Order: oco})),
+		)
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+	})
+	t.Run("app should free the funds of the take profit before the stop sells", func(t *testing.T) {
+		e := exchange.NewPaper(
+			exchange.NewRecordedPrices(map[trading.Pair][]string{
+				trading.BTCUSD: {"17000.00", "15990.00"},
+			}),
+			exchange.WithPaperBalance(trading.BTC, trading.MustParseAmount("0.5")),
+		)
+
+		ctrl := gomock.NewController(t)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+		a := app.New(zaptest.NewLogger(t), e,
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(strategy.PlaceOCO{Order: oco})),
+		)
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		fills := e.Fills()
+		assert.Len(t, fills, 1)
+		assert.Equal(t, "foobar-sl", fills[0].ClientID)
+		assert.Equal(t, trading.MustParseAmount("0.5"), fills[0].Size)
+		assert.Equal(t, trading.MustParseAmount("15990"), fills[0].Price)
+
+		open, err := e.ListOpenOrders(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, open, "take profit should be cancelled")
+		assert.True(t, e.Balances()[trading.BTC].IsZero())
+	})
+}
+
+func TestAppBracketOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	entry := order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.5"),
+		Price:    trading.MustParseAmount("17000"),
+	}
+
+	placed := entry
+	placed.ClientID = "foobar"
+
+	mockExchange := app.NewmockExchangeClient(ctrl)
+
+	gomock.InOrder(
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17500.00", nil),
+		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), placed).Return(exchange.Order{ID: "entry"}, nil),
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17200.00", nil),
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{{ID: "entry"}}, nil),
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("16900.00", nil),
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+		mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "entry").
+			Return(exchange.Order{ID: "entry", Status: order.StatusFilled}, nil),
+		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), order.Limit{
+			ClientID: "foobar-exit-tp",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideSell,
+			BaseSize: trading.MustParseAmount("0.5"),
+			Price:    trading.MustParseAmount("20000"),
+		}).Return(exchange.Order{ID: "tp"}, nil),
+	)
+
+	idGen := app.NewmockIDGenerator(ctrl)
+	idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+	store := app.NewFileLinkStore(filepath.Join(t.TempDir(), "links.json"))
+
+	a := app.New(zaptest.NewLogger(t), mockExchange,
+		app.WithIDGenerator(idGen),
+		app.WithLinkStore(store),
+		app.WithStrategy(intentsOnce(strategy.PlaceBracket{Order: order.Bracket{
+			Entry:      entry,
+			TakeProfit: trading.MustParseAmount("20000"),
+			StopPrice:  trading.MustParseAmount("16000"),
+		}})),
+	)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, a.Tick(context.Background()))
+	}
+
+	links, err := store.LoadLinks()
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "foobar-exit", links[0].ClientID)
+	assert.Equal(t, []string{"tp"}, links[0].OrderIDs)
+	assert.Equal(t, "foobar-exit-sl", links[0].Stop.ClientID)
+}
+
+func TestAppBracketEntryCancelled(t *testing.T) {
+	entry := order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.5"),
+		Price:    trading.MustParseAmount("17000"),
+	}
+
+	tests := []struct {
+		name   string
+		filled string
+		links  int
+	}{
+		{name: "exit should be sized to the partial fill of the entry", filled: "0.2", links: 1},
+		{name: "link should be dropped if the entry did not fill", filled: "0", links: 0},
+		{name: "link should be dropped if the filled size is below the minimum", filled: "0.04", links: 0},
+	}
+
+	markets := trading.NewRegistry(trading.Market{
+		Pair:        trading.BTCUSD,
+		Symbol:      "BTCUSD",
+		MinNotional: trading.MustParseAmount("1000"),
+	})
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			placed := entry
+			placed.ClientID = "foobar"
+
+			filled := trading.MustParseAmount(tt.filled)
+
+			mockExchange := app.NewmockExchangeClient(ctrl)
+
+			gomock.InOrder(
+				mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17500.00", nil),
+				mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), placed).Return(exchange.Order{ID: "entry"}, nil),
+				mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("17200.00", nil),
+				mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+				mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "entry").Return(exchange.Order{
+					ID:         "entry",
+					Status:     order.StatusCancelled,
+					FilledSize: filled,
+				}, nil),
+			)
+
+			if tt.links > 0 {
+				mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), order.Limit{
+					ClientID: "foobar-exit-tp",
+					Pair:     trading.BTCUSD,
+					Side:     order.SideSell,
+					BaseSize: filled,
+					Price:    trading.MustParseAmount("20000"),
+				}).Return(exchange.Order{ID: "tp"}, nil)
+			}
+
+			idGen := app.NewmockIDGenerator(ctrl)
+			idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar")
+
+			store := app.NewFileLinkStore(filepath.Join(t.TempDir(), "links.json"))
+
+			a := app.New(zaptest.NewLogger(t), mockExchange,
+				app.WithIDGenerator(idGen),
+				app.WithLinkStore(store),
+				app.WithMarkets(markets),
+				app.WithStrategy(intentsOnce(strategy.PlaceBracket{Order: order.Bracket{
+					Entry:      entry,
+					TakeProfit: trading.MustParseAmount("20000"),
+					StopPrice:  trading.MustParseAmount("16000"),
+				}})),
+			)
+
+			for i := 0; i < 2; i++ {
+				assert.NoError(t, a.Tick(context.Background()))
+			}
+
+			links, err := store.LoadLinks()
+			assert.NoError(t, err)
+			assert.Len(t, links, tt.links)
+		})
+	}
+}
+
+func TestAppRestoreLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := app.NewFileLinkStore(filepath.Join(t.TempDir(), "links.json"))
+
+	assert.NoError(t, store.SaveLinks([]app.Link{{
+		ClientID: "go-trading-bot-oco",
+		OrderIDs: []string{"tp"},
+		Stop: &app.WatchedStop{
+			ClientID:  "go-trading-bot-oco-sl",
+			Pair:      trading.BTCUSD,
+			StopPrice: trading.MustParseAmount("16000"),
+			Direction: order.DirectionBelow,
+			Market: &order.Market{
+				ClientID: "go-trading-bot-oco-sl",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+			},
+		},
+	}}))
+
+	open := []exchange.Order{
+		{ID: "tp", ClientID: "go-trading-bot-oco-tp"},
+		{ID: "old", ClientID: "go-trading-bot-old"},
+	}
+
+	mockExchange := app.NewmockExchangeClient(ctrl)
+
+	gomock.InOrder(
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(open, nil),
+		mockExchange.EXPECT().CancelOrders(gomock.Any(), "old").Return(nil),
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("15000.00", nil),
+		mockExchange.EXPECT().CancelOrders(gomock.Any(), "tp").Return(nil),
+		mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "tp").
+			Return(exchange.Order{ID: "tp", Status: order.StatusCancelled}, nil),
+		mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), order.Market{
+			ClientID: "go-trading-bot-oco-sl",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideSell,
+			BaseSize: trading.MustParseAmount("0.5"),
+		}).Return(exchange.Order{ID: "sl"}, nil),
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+	)
+
+	a := app.New(zaptest.NewLogger(t), mockExchange,
+		app.WithLinkStore(store),
+		app.WithStrategy(strategyFunc(func(
+			ctx context.Context, account strategy.Account, tick strategy.Tick,
+		) ([]strategy.Intent, error) {
+			return nil, nil
+		})),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	a.Start(ctx)
+
+	assert.NoError(t, a.Tick(context.Background()))
+
+	links, err := store.LoadLinks()
+	assert.NoError(t, err)
+	assert.Empty(t, links)
+}
+
+func TestAppTrailingStops(t *testing.T) {
+	trailing := order.TrailingStop{
+		Pair:        trading.BTCUSD,
+		Side:        order.SideSell,
+		BaseSize:    trading.MustParseAmount("0.5"),
+		TrailAmount: trading.MustParseAmount("500"),
+	}
+
+	newPaper := func(prices ...string) *exchange.Paper {
+		return exchange.NewPaper(
+			exchange.NewRecordedPrices(map[trading.Pair][]string{trading.BTCUSD: prices}),
+			exchange.WithPaperBalance(trading.BTC, trading.MustParseAmount("1")),
+		)
+	}
+
+	newApp := func(t *testing.T, e app.ExchangeClient, opts ...app.Option) *app.App {
+		t.Helper()
+
+		ctrl := gomock.NewController(t)
+
+		idGen := app.NewmockIDGenerator(ctrl)
+		idGen.EXPECT().GenerateID("go-trading-bot").Return("foobar").AnyTimes()
+
+		return app.New(zaptest.NewLogger(t), e, append([]app.Option{
+			app.WithIDGenerator(idGen),
+			app.WithStrategy(intentsOnce(strategy.PlaceTrailingStop{Order: trailing})),
+		}, opts...)...)
+	}
+
+	t.Run("trailing stop should exit once the price retraces", func(t *testing.T) {
+		e := newPaper("20000.00", "20500.00", "21000.00", "20700.00", "20500.00")
+		a := newApp(t, e)
+
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		assert.Empty(t, e.Fills(), "stop should not trigger before the price retraces by the trail")
+
+		assert.NoError(t, a.Tick(context.Background()))
+
+		fills := e.Fills()
+		assert.Len(t, fills, 1)
+		assert.Equal(t, "foobar", fills[0].ClientID)
+		assert.Equal(t, order.SideSell, fills[0].Side)
+		assert.Equal(t, trading.MustParseAmount("20500"), fills[0].Price)
+		assert.Equal(t, trading.MustParseAmount("0.5"), fills[0].Size)
+	})
+
+	t.Run("trailing stop should keep its best price across a restart", func(t *testing.T) {
+		e := newPaper("20000.00", "20500.00", "21000.00", "20700.00", "20500.00")
+		store := app.NewFileTrailingStopStore(filepath.Join(t.TempDir(), "trailing.json"))
+
+		a := newApp(t, e, app.WithTrailingStopStore(store))
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		stops, err := store.LoadTrailingStops()
+		assert.NoError(t, err)
+		assert.Len(t, stops, 1)
+		assert.Equal(t, trading.MustParseAmount("21000"), stops[0].Best)
+
+		restarted := app.New(zaptest.NewLogger(t), e,
+			app.WithTrailingStopStore(store),
+			app.WithStrategy(intentsOnce()),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		restarted.Start(ctx)
+
+		assert.NoError(t, restarted.Tick(context.Background()))
+		assert.NoError(t, restarted.Tick(context.Background()))
+
+		fills := e.Fills()
+		assert.Len(t, fills, 1)
+		assert.Equal(t, "foobar", fills[0].ClientID)
+
+		stops, err = store.LoadTrailingStops()
+		assert.NoError(t, err)
+		assert.Empty(t, stops)
+	})
+
+	t.Run("trailing stop should be kept until its exit has been placed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clock := app.NewmockClock(ctrl)
+		clock.EXPECT().Now().Return(time.Time{}).AnyTimes()
+		clock.EXPECT().After(10 * time.Second).DoAndReturn(func(time.Duration) <-chan time.Time {
+			c := make(chan time.Time, 1)
+			c <- time.Time{}
+
+			return c
+		})
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20000.00", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("21000.00", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20400.00", nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), gomock.Any()).
+				Return(exchange.Order{}, fmt.Errorf("create order: %w", exchange.ErrRateLimited)),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20450.00", nil),
+			mockExchange.EXPECT().CreateMarketOrder(gomock.Any(), gomock.Any()).Return(exchange.Order{ID: "exit"}, nil),
+		)
+
+		store := app.NewFileTrailingStopStore(filepath.Join(t.TempDir(), "trailing.json"))
+		a := newApp(t, mockExchange, app.WithTrailingStopStore(store), app.WithClock(clock))
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		// The exit was rate limited, so the stop is kept with its best price
+		// to be triggered again.
+		stops, err := store.LoadTrailingStops()
+		assert.NoError(t, err)
+		assert.Len(t, stops, 1)
+		assert.Equal(t, trading.MustParseAmount("21000"), stops[0].Best)
+
+		assert.NoError(t, a.Tick(context.Background()))
+
+		stops, err = store.LoadTrailingStops()
+		assert.NoError(t, err)
+		assert.Empty(t, stops)
+	})
+
+	t.Run("trailing stop should exit with a limit order if it has an offset", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20000.00", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20123.45", nil),
+			mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("19500.00", nil),
+			mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), order.Limit{
+				ClientID: "foobar",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+				Price:    trading.MustParseAmount("19509.75"),
+			}).Return(exchange.Order{ID: "exit"}, nil),
+		)
+
+		withOffset := trailing
+		withOffset.TrailAmount = trading.Amount{}
+		withOffset.TrailPercent = trading.MustParseAmount("3")
+		withOffset.LimitOffset = trading.MustParseAmount("10")
+
+		a := newApp(t, mockExchange, app.WithStrategy(intentsOnce(strategy.PlaceTrailingStop{Order: withOffset})))
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+	})
+
+	t.Run("app should stop tracking trailing stops that are cancelled", func(t *testing.T) {
+		e := newPaper("20000.00", "20500.00", "19000.00")
+		a := newApp(t, e, app.WithStrategy(intentsOnce(
+			strategy.PlaceTrailingStop{Order: trailing},
+			strategy.CancelOrders{OrderIDs: []string{"foobar"}},
+		)))
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		assert.Empty(t, e.Fills())
+	})
+}
+
+// observingStrategy is a strategy that records the order updates that it is
+// given, and carries out the intents of its onUpdate func.
+type observingStrategy struct {
+	strategy.Strategy
+
+	updates  []strategy.OrderUpdate
+	onUpdate func(update strategy.OrderUpdate) []strategy.Intent
+}
+
+func (s *observingStrategy) OnOrderUpdate(
+	ctx context.Context, account strategy.Account, update strategy.OrderUpdate,
+) ([]strategy.Intent, error) {
+	s.updates = append(s.updates, update)
+
+	if s.onUpdate == nil {
+		return nil, nil
+	}
+
+	return s.onUpdate(update), nil
+}
+
+func TestAppOrderTracking(t *testing.T) {
+	buy := order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("18000"),
+	}
+
+	t.Run("strategy should be told when its orders are filled", func(t *testing.T) {
+		e := exchange.NewPaper(
+			exchange.NewRecordedPrices(map[trading.Pair][]string{
+				trading.BTCUSD: {"20000.00", "19000.00", "17999.99", "18500.00"},
+			}),
+			exchange.WithPaperBalance(trading.USD, trading.MustParseAmount("1000")),
+		)
+
+		s := &observingStrategy{
+			Strategy: intentsOnce(strategy.PlaceLimit{Order: buy}),
+			onUpdate: func(update strategy.OrderUpdate) []strategy.Intent {
+				if update.Order.Status != order.StatusFilled {
+					return nil
+				}
+
+				sell := buy
+				sell.Side = order.SideSell
+				sell.Price = trading.MustParseAmount("19000")
+
+				return []strategy.Intent{strategy.PlaceLimit{Order: sell}}
+			},
+		}
+
+		a := app.New(zaptest.NewLogger(t), e, app.WithStrategy(s))
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		assert.Empty(t, s.updates, "strategy should not be told about orders which have not changed")
+
+		assert.NoError(t, a.Tick(context.Background()))
+
+		assert.Len(t, s.updates, 1)
+		assert.Equal(t, order.StatusNew, s.updates[0].Previous)
+		assert.Equal(t, order.StatusFilled, s.updates[0].Order.Status)
+		assert.Equal(t, trading.MustParseAmount("0.01"), s.updates[0].Order.FilledSize)
+		assert.Equal(t, trading.MustParseAmount("18000"), s.updates[0].Order.AveragePrice)
+
+		open, err := e.ListOpenOrders(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, open, 1)
+		assert.Equal(t, order.SideSell, open[0].Side)
+
+		assert.NoError(t, a.Tick(context.Background()))
+		assert.Len(t, s.updates, 1)
+	})
+
+	t.Run("strategy should be told of partial fills and cancellations", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		partial := exchange.Order{
+			ID:         "myorder",
+			Pair:       trading.BTCUSD,
+			Status:     order.StatusPartiallyFilled,
+			FilledSize: trading.MustParseAmount("0.004"),
+		}
+
+		cancelled := partial
+		cancelled.Status = order.StatusCancelled
+
+		mockExchange := app.NewmockExchangeClient(ctrl)
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("20000.00", nil).Times(4)
+
+		gomock.InOrder(
+			mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), gomock.Any()).
+				Return(exchange.Order{ID: "myorder", Pair: trading.BTCUSD, Status: order.StatusNew}, nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{partial}, nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{partial}, nil),
+			mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return([]exchange.Order{}, nil),
+			mockExchange.EXPECT().GetOrder(gomock.Any(), trading.BTCUSD, "myorder").Return(cancelled, nil),
+		)
+
+		s := &observingStrategy{Strategy: intentsOnce(strategy.PlaceLimit{Order: buy})}
+
+		a := app.New(zaptest.NewLogger(t), mockExchange, app.WithStrategy(s))
+
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, a.Tick(context.Background()))
+		}
+
+		assert.Equal(t, []strategy.OrderUpdate{
+			{Order: partial, Previous: order.StatusNew},
+			{Order: cancelled, Previous: order.StatusPartiallyFilled},
+		}, s.updates)
+	})
+}
+
+func TestAppPortfolio(t *testing.T) {
+	e := exchange.NewPaper(
+		exchange.NewRecordedPrices(map[trading.Pair][]string{
+			trading.BTCUSD: {"20000.00", "21000.00", "22000.00"},
+		}),
+		exchange.WithPaperBalance(trading.USD, trading.MustParseAmount("1000")),
+	)
+
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	a := app.New(zaptest.NewLogger(t), e,
+		app.WithPortfolio(p),
+		app.WithStrategy(intentsOnce(strategy.PlaceMarket{Order: order.Market{
+			Pair:     trading.BTCUSD,
+			Side:     order.SideBuy,
+			BaseSize: trading.MustParseAmount("0.01"),
+		}})),
+	)
+
+	assert.NoError(t, a.Tick(context.Background()))
+	assert.True(t, p.Position(trading.BTCUSD).Size.IsZero(), "fills should be applied on the next tick")
+
+	assert.NoError(t, a.Tick(context.Background()))
+	assert.NoError(t, a.Tick(context.Background()))
+
+	pos := p.Position(trading.BTCUSD)
+	assert.Equal(t, trading.MustParseAmount("0.01"), pos.Size)
+	assert.Equal(t, trading.MustParseAmount("200"), pos.CostBasis)
+	assert.Equal(t, trading.MustParseAmount("22000"), pos.MarkPrice)
+	assert.Equal(t, trading.MustParseAmount("20"), pos.UnrealizedPnL)
+}
+
+// streamingStrategy is a strategy that sends the ticks, market data events
+// and order updates that it is given on channels, and places a limit order
+// for each trade.
+type streamingStrategy struct {
+	ticks   chan strategy.Tick
+	events  chan marketdata.Event
+	updates chan strategy.OrderUpdate
+	order   order.Limit
+}
+
+func (s *streamingStrategy) OnTick(
+	ctx context.Context, account strategy.Account, tick strategy.Tick,
+) ([]strategy.Intent, error) {
+	s.ticks <- tick
+	return nil, nil
+}
+
+func (s *streamingStrategy) OnMarketData(
+	ctx context.Context, account strategy.Account, event marketdata.Event,
+) ([]strategy.Intent, error) {
+	s.events <- event
+
+	if _, ok := event.(marketdata.Trade); ok {
+		return []strategy.Intent{strategy.PlaceLimit{Order: s.order}}, nil
+	}
+
+	return nil, nil
+}
+
+func (s *streamingStrategy) OnOrderUpdate(
+	ctx context.Context, account strategy.Account, update strategy.OrderUpdate,
+) ([]strategy.Intent, error) {
+	s.updates <- update
+	return nil, nil
+}
+
+func TestAppMarketData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	buy := order.Limit{
+		ClientID: "foobar",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("19000"),
+	}
+
+	ticks := make(chan time.Time)
+	events := make(chan marketdata.Event)
+
+	clock := app.NewmockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)).AnyTimes()
+	clock.EXPECT().After(time.Second).Return(ticks).AnyTimes()
+
+	ids := app.NewmockIDGenerator(ctrl)
+	ids.EXPECT().GenerateID(gomock.Any()).Return("foobar").AnyTimes()
+
+	stream := app.NewmockMarketDataStream(ctrl)
+	stream.EXPECT().Subscribe(gomock.Any(), marketdata.Subscription{
+		Pairs:    []trading.Pair{trading.BTCUSD},
+		Channels: []marketdata.Channel{marketdata.ChannelTicker, marketdata.ChannelTrades, marketdata.ChannelUser},
+	}).Return(events, nil)
+
+	filled := exchange.Order{ID: "myorder", Pair: trading.BTCUSD, Status: order.StatusFilled}
+
+	mockExchange := app.NewmockExchangeClient(ctrl)
+	gomock.InOrder(
+		mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(nil, nil),
+		mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil),
+		mockExchange.EXPECT().CreateLimitOrder(gomock.Any(), buy).
+			Return(exchange.Order{ID: "myorder", Pair: trading.BTCUSD, Status: order.StatusNew}, nil),
+		mockExchange.EXPECT().GetLastPrice(gomock.Any(), trading.BTCUSD).Return("18000.00", nil),
+	)
+
+	s := &streamingStrategy{
+		ticks:   make(chan strategy.Tick, 1),
+		events:  make(chan marketdata.Event, 1),
+		updates: make(chan strategy.OrderUpdate, 1),
+		order:   buy,
+	}
+
+	a := app.New(zaptest.NewLogger(t), mockExchange,
+		app.WithClock(clock),
+		app.WithIDGenerator(ids),
+		app.WithStrategy(s),
+		app.WithMarketData(stream, marketdata.ChannelTrades, marketdata.ChannelTicker, marketdata.ChannelUser),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		a.Start(ctx)
+	}()
+
+	ticker := marketdata.Ticker{Pair: trading.BTCUSD, Price: trading.MustParseAmount("20000.00")}
+	events <- ticker
+	assert.Equal(t, ticker, <-s.events)
+
+	ticks <- time.Time{}
+	assert.Equal(t, trading.MustParseAmount("20000.00"), (<-s.ticks).Price, "tick should use the streamed price")
+
+	trade := marketdata.Trade{ID: "1", Pair: trading.BTCUSD, Price: trading.MustParseAmount("20100.00")}
+	events <- trade
+	assert.Equal(t, trade, <-s.events)
+
+	events <- marketdata.OrderUpdate{Order: filled}
+	<-s.events
+	assert.Equal(t, strategy.OrderUpdate{Order: filled, Previous: order.StatusNew}, <-s.updates)
+
+	// A ticker without a price, as binance streams, keeps the traded price.
+	events <- marketdata.Ticker{Pair: trading.BTCUSD, BestBid: trading.MustParseAmount("20099.99")}
+	<-s.events
+
+	ticks <- time.Time{}
+	assert.Equal(t, trading.MustParseAmount("20100.00"), (<-s.ticks).Price, "tick should use the traded price")
+
+	events <- marketdata.Reconnect{Err: errors.New("connection lost")}
+	<-s.events
+
+	ticks <- time.Time{}
+	assert.Equal(t, trading.MustParseAmount("18000.00"), (<-s.ticks).Price, "tick should ask the exchange once stale")
+
+	cancel()
+	<-done
+}
+
+func TestAppOrderBooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := make(chan marketdata.Event)
+
+	clock := app.NewmockClock(ctrl)
+	clock.EXPECT().Now().Return(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)).AnyTimes()
+	clock.EXPECT().After(gomock.Any()).Return(make(chan time.Time)).AnyTimes()
+
+	stream := app.NewmockMarketDataStream(ctrl)
+	stream.EXPECT().Subscribe(gomock.Any(), marketdata.Subscription{
+		Pairs:    []trading.Pair{trading.BTCUSD},
+		Channels: []marketdata.Channel{marketdata.ChannelTicker, marketdata.ChannelBook},
+	}).Return(events, nil)
+
+	mockExchange := app.NewmockExchangeClient(ctrl)
+	mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(nil, nil)
+	mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil)
+
+	s := &streamingStrategy{events: make(chan marketdata.Event, 1)}
+	books := orderbook.NewBooks(nil)
+
+	a := app.New(zaptest.NewLogger(t), mockExchange,
+		app.WithClock(clock),
+		app.WithStrategy(s),
+		app.WithMarketData(stream, marketdata.ChannelBook),
+		app.WithOrderBooks(books),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		a.Start(ctx)
+	}()
+
+	snapshot := marketdata.BookUpdate{
+		Pair:     trading.BTCUSD,
+		Snapshot: true,
+		Bids:     []marketdata.Level{{Price: trading.MustParseAmount("100"), Size: trading.MustParseAmount("1")}},
+	}
+	events <- snapshot
+
+	// The book is synced by the time that the strategy is told of the event.
+	assert.Equal(t, snapshot, <-s.events)
+
+	book, ok := books.Book(trading.BTCUSD)
+	assert.True(t, ok)
+
+	bid, _ := book.BestBid()
+	assert.Equal(t, trading.MustParseAmount("100"), bid.Price)
+
+	cancel()
+	<-done
+}
+
+func TestAppMarketDataBusyStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := make(chan marketdata.Event)
+
+	stream := app.NewmockMarketDataStream(ctrl)
+	stream.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Return(events, nil)
+
+	mockExchange := app.NewmockExchangeClient(ctrl)
+	mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(nil, nil)
+	mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil)
+
+	ticks := make(chan strategy.Tick, 1)
+
+	a := app.New(zaptest.NewLogger(t), mockExchange,
+		app.WithMarketData(stream),
+		app.WithStrategy(strategyFunc(func(
+			ctx context.Context, account strategy.Account, tick strategy.Tick,
+		) ([]strategy.Intent, error) {
+			select {
+			case ticks <- tick:
+			default:
+			}
+
+			return nil, nil
+		})),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		a.Start(ctx)
+	}()
+
+	// The stream sends an event every 100ms, as the depth streams of binance
+	// do, which should not hold off the tick that is due every second.
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case events <- marketdata.Ticker{Pair: trading.BTCUSD, Price: trading.MustParseAmount("20000.00")}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	select {
+	case tick := <-ticks:
+		assert.Equal(t, trading.MustParseAmount("20000.00"), tick.Price)
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "app should tick while the stream is busy")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestAppMarketDataSubscribeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stream := app.NewmockMarketDataStream(ctrl)
+	stream.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Return(nil, exchange.ErrMissingPair)
+
+	mockExchange := app.NewmockExchangeClient(ctrl)
+	mockExchange.EXPECT().ListOpenOrders(gomock.Any()).Return(nil, nil)
+	mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil)
+
+	a := app.New(zaptest.NewLogger(t), mockExchange, app.WithMarketData(stream))
+
+	// Start returns straight away rather than trading without the stream.
+	a.Start(context.Background())
+}
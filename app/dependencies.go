@@ -1,11 +1,13 @@
-//go:generate mockgen -source=dependencies.go -destination=./mocks.go -package=app -mock_names ExchangeClient=mockExchangeClient,IDGenerator=mockIDGenerator
+//go:generate mockgen -source=dependencies.go -destination=./mocks.go -package=app -mock_names ExchangeClient=mockExchangeClient,IDGenerator=mockIDGenerator,StopOrderCreator=mockStopOrderCreator,OCOCreator=mockOCOCreator,LinkStore=mockLinkStore,TrailingStopStore=mockTrailingStopStore,WatchedStopStore=mockWatchedStopStore,MarketDataStream=mockMarketDataStream,Clock=mockClock
 
 package app
 
 import (
 	"context"
+	"time"
 
 	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
 	"github.com/project-code-io/crypto-trading-bot-go/order"
 	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
@@ -15,11 +17,89 @@ import (
 type ExchangeClient interface {
 	GetLastPrice(ctx context.Context, pair trading.Pair) (string, error)
 	CreateLimitOrder(ctx context.Context, order order.Limit) (exchange.Order, error)
+	CreateMarketOrder(ctx context.Context, order order.Market) (exchange.Order, error)
 	CancelOrders(ctx context.Context, orderIDs ...string) error
 	ListOpenOrders(ctx context.Context) ([]exchange.Order, error)
-	GetBalance(ctx context.Context, asset trading.Asset) (int64, error)
+	GetOrder(ctx context.Context, pair trading.Pair, orderID string) (exchange.Order, error)
+	ListFills(ctx context.Context, pair trading.Pair, since time.Time) ([]exchange.Fill, error)
+	GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error)
 }
 
+var (
+	_ ExchangeClient = (*exchange.Binance)(nil)
+	_ ExchangeClient = (*exchange.Coinbase)(nil)
+	_ ExchangeClient = (*exchange.Noop)(nil)
+	_ ExchangeClient = (*exchange.Paper)(nil)
+)
+
+// StopOrderCreator represents an exchange client that is able to place stop
+// orders natively. Stop orders on exchanges without native support are
+// watched and triggered by the application instead.
+type StopOrderCreator interface {
+	CreateStopLimitOrder(ctx context.Context, order order.StopLimit) (exchange.Order, error)
+	CreateStopMarketOrder(ctx context.Context, order order.StopMarket) (exchange.Order, error)
+}
+
+var (
+	_ StopOrderCreator = (*exchange.Binance)(nil)
+	_ StopOrderCreator = (*exchange.Coinbase)(nil)
+)
+
+// OCOCreator represents an exchange client that is able to place OCO orders
+// natively. The legs of OCO orders on exchanges without native support are
+// linked by the application instead.
+type OCOCreator interface {
+	CreateOCOOrder(ctx context.Context, order order.OCO) (exchange.OrderList, error)
+}
+
+var _ OCOCreator = (*exchange.Binance)(nil)
+
+// LinkStore represents a type that is able to persist the linked orders that
+// the application manages, so that their legs are not orphaned when the
+// application restarts.
+type LinkStore interface {
+	LoadLinks() ([]Link, error)
+	SaveLinks(links []Link) error
+}
+
+// TrailingStopStore represents a type that is able to persist the trailing
+// stops that the application tracks, along with the best price that each has
+// reached, so that they keep trailing from where they were when the
+// application restarts.
+type TrailingStopStore interface {
+	LoadTrailingStops() ([]TrailingStop, error)
+	SaveTrailingStops(stops []TrailingStop) error
+}
+
+// WatchedStopStore represents a type that is able to persist the stop orders
+// that the application watches on exchanges without native support, along
+// with the IDs of those placed natively, so that they are neither lost nor
+// cancelled as old orders when the application restarts.
+type WatchedStopStore interface {
+	LoadWatchedStops() ([]WatchedStop, error)
+	SaveWatchedStops(stops []WatchedStop) error
+}
+
+// MarketDataStream represents a type that is able to stream the market data
+// of an exchange, such as a websocket api, rather than the application
+// polling for the last price.
+type MarketDataStream interface {
+	Subscribe(ctx context.Context, s marketdata.Subscription) (<-chan marketdata.Event, error)
+}
+
+var (
+	_ MarketDataStream = (*marketdata.Coinbase)(nil)
+	_ MarketDataStream = (*marketdata.Binance)(nil)
+)
+
 type IDGenerator interface {
 	GenerateID(prefix string) string
 }
+
+// Clock represents a source of the current time. The application waits on
+// the clock between each iteration of the trading loop, which allows for the
+// loop to be driven by a simulated clock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
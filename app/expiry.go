@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+)
+
+// expiringOrder is an order on the exchange that is cancelled by the app once
+// it has expired.
+type expiringOrder struct {
+	id      string
+	expires time.Time
+}
+
+// expiryCanceller keeps track of the good till date orders that have been
+// placed on exchanges which do not support them, so that the app can cancel
+// them once they have expired. It is safe for concurrent use.
+type expiryCanceller struct {
+	mu     sync.Mutex
+	orders []expiringOrder
+}
+
+// add starts keeping track of the order.
+func (c *expiryCanceller) add(id string, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.orders = append(c.orders, expiringOrder{id: id, expires: expires})
+}
+
+// remove stops keeping track of the orders with the given IDs.
+func (c *expiryCanceller) remove(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		removed[id] = true
+	}
+
+	kept := c.orders[:0]
+
+	for _, o := range c.orders {
+		if !removed[o.id] {
+			kept = append(kept, o)
+		}
+	}
+
+	c.orders = kept
+}
+
+// expired stops keeping track of the orders that have expired by the given
+// time, and returns their IDs.
+func (c *expiryCanceller) expired(now time.Time) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := make([]string, 0)
+	kept := c.orders[:0]
+
+	for _, o := range c.orders {
+		if o.expires.After(now) {
+			kept = append(kept, o)
+			continue
+		}
+
+		ids = append(ids, o.id)
+	}
+
+	c.orders = kept
+
+	return ids
+}
+
+// placeExpiring places a good till date order as good till cancelled, for
+// exchanges that do not support good till date orders, and cancels it once it
+// has expired.
+func (a *App) placeExpiring(ctx context.Context, o order.Limit) (exchange.Order, error) {
+	expires := *o.Expires
+
+	o.Expires = nil
+	o.TimeInForce = order.TimeInForceGTC
+
+	eOrder, err := a.createLimit(ctx, o)
+	if err != nil {
+		return exchange.Order{}, err
+	}
+
+	a.logger.Info("cancelling order on the client side once it expires",
+		zap.String("id", eOrder.ID), zap.Time("expires", expires))
+
+	a.expiries.add(eOrder.ID, expires)
+
+	return eOrder, nil
+}
+
+// cancelExpired cancels the orders that the app is keeping track of which
+// have expired.
+func (a *App) cancelExpired(ctx context.Context) error {
+	ids := a.expiries.expired(a.clock.Now())
+	if len(ids) == 0 {
+		return nil
+	}
+
+	a.logger.Info("cancelling expired orders", zap.Strings("ids", ids))
+
+	if err := a.exchange.CancelOrders(ctx, ids...); err != nil {
+		return fmt.Errorf("cancel expired orders: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,506 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// ErrLegOpen describes an error in which a leg of an OCO order is still open
+// after it has been cancelled, so the stop loss cannot be placed yet.
+var ErrLegOpen = errors.New("oco leg still open")
+
+// Link holds linked orders that the app manages. A link is either the exit of
+// a bracket that is waiting on its entry to fill, or the legs of an OCO order,
+// where the fill of either leg cancels the other. The legs of OCO orders that
+// the exchange links natively are held as well, so that they are not taken
+// for old orders when the app restarts.
+type Link struct {
+	ClientID string `json:"client_id"`
+
+	// ListID is the ID of the order list of an OCO order that the exchange
+	// links natively, which cancels the other legs itself.
+	ListID string `json:"list_id,omitempty"`
+
+	// EntryOrderID is the ID of the entry order of a bracket. The Exit is
+	// placed once the entry has been filled.
+	EntryOrderID string     `json:"entry_order_id,omitempty"`
+	Exit         *order.OCO `json:"exit,omitempty"`
+
+	// OrderIDs are the IDs of the legs of an OCO order that have been placed
+	// on the exchange.
+	OrderIDs []string `json:"order_ids,omitempty"`
+
+	// Stop is the stop loss leg of an OCO order, which is watched by the app.
+	// The other legs are cancelled once it is triggered, before it is placed.
+	Stop *WatchedStop `json:"stop,omitempty"`
+}
+
+// linkManager holds the links of the app, saving them to the store whenever
+// they change. It is safe for concurrent use.
+type linkManager struct {
+	mu    sync.Mutex
+	links []Link
+	store LinkStore
+}
+
+// load replaces the links with those in the store.
+func (m *linkManager) load() ([]Link, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.store == nil {
+		return nil, nil
+	}
+
+	links, err := m.store.LoadLinks()
+	if err != nil {
+		return nil, fmt.Errorf("load links: %w", err)
+	}
+
+	m.links = links
+
+	return links, nil
+}
+
+// all returns a copy of the links.
+func (m *linkManager) all() []Link {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Link(nil), m.links...)
+}
+
+// add adds the link and saves it.
+func (m *linkManager) add(l Link) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.save(append(m.links, l))
+}
+
+// set replaces the links and saves them.
+func (m *linkManager) set(links []Link) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.save(links)
+}
+
+// removeEntries removes the brackets whose entry has one of the given IDs, as
+// their exit is no longer needed once the entry has been cancelled.
+func (m *linkManager) removeEntries(ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		removed[id] = true
+	}
+
+	kept := make([]Link, 0, len(m.links))
+
+	for _, l := range m.links {
+		if l.EntryOrderID == "" || !removed[l.EntryOrderID] {
+			kept = append(kept, l)
+		}
+	}
+
+	if len(kept) == len(m.links) {
+		return nil
+	}
+
+	return m.save(kept)
+}
+
+// orderIDs returns the IDs of every order on the exchange that is part of a
+// link.
+func (m *linkManager) orderIDs() map[string]bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make(map[string]bool)
+
+	for _, l := range m.links {
+		if l.EntryOrderID != "" {
+			ids[l.EntryOrderID] = true
+		}
+
+		for _, id := range l.OrderIDs {
+			ids[id] = true
+		}
+	}
+
+	return ids
+}
+
+// legs returns the IDs of the orders on the exchange that are linked to the
+// watched stop, which are the other legs of its OCO order.
+func (m *linkManager) legs(stopID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, l := range m.links {
+		if l.Stop != nil && l.Stop.ClientID == stopID {
+			return append([]string(nil), l.OrderIDs...)
+		}
+	}
+
+	return nil
+}
+
+// closeLegs replaces the stop of the OCO order that it belongs to once the
+// other legs of the order have been closed, so that the link is kept until the
+// stop has been placed.
+func (m *linkManager) closeLegs(s WatchedStop) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	links := append([]Link(nil), m.links...)
+
+	for i := range links {
+		if links[i].Stop != nil && links[i].Stop.ClientID == s.ClientID {
+			stop := s
+			links[i].Stop = &stop
+			links[i].OrderIDs = nil
+		}
+	}
+
+	return m.save(links)
+}
+
+func (m *linkManager) save(links []Link) error {
+	m.links = links
+
+	if m.store == nil {
+		return nil
+	}
+
+	if err := m.store.SaveLinks(links); err != nil {
+		return fmt.Errorf("save links: %w", err)
+	}
+
+	return nil
+}
+
+// restoreLinks loads the links from the store, and watches the stops of any
+// OCO orders again.
+func (a *App) restoreLinks() error {
+	links, err := a.links.load()
+	if err != nil {
+		return err
+	}
+
+	for _, l := range links {
+		// The stop may have been restored along with the other stops.
+		if l.Stop == nil || a.triggers.watching(l.Stop.ClientID) {
+			continue
+		}
+
+		if err := a.triggers.add(*l.Stop); err != nil {
+			return err
+		}
+	}
+
+	if len(links) > 0 {
+		a.logger.Info("restored linked orders", zap.Int("count", len(links)))
+	}
+
+	return nil
+}
+
+// placeOCO normalizes the OCO order and tags it with a client ID, before
+// placing it.
+func (a *App) placeOCO(ctx context.Context, o order.OCO) error {
+	o, err := a.normalizer.NormalizeOCO(a.market(o.Pair), o)
+	if err != nil {
+		return fmt.Errorf("normalize order: %w", err)
+	}
+
+	o.ClientID = a.idGenerator.GenerateID(a.prefix)
+
+	link, err := a.createOCO(ctx, o)
+	if err != nil || link == nil {
+		return err
+	}
+
+	return a.links.add(*link)
+}
+
+// createOCO places the OCO order on the exchange, and returns the link between
+// its legs for the app to manage. If the exchange does not support OCO orders,
+// the take profit is placed on its own while the stop loss is watched by the
+// app.
+func (a *App) createOCO(ctx context.Context, o order.OCO) (*Link, error) {
+	if native, ok := a.exchange.(OCOCreator); ok {
+		a.logger.Info("creating oco order", zap.Any("order", o))
+
+		list, err := native.CreateOCOOrder(ctx, o)
+		if err == nil {
+			a.logger.Info("order created", zap.Any("exchange_order_list", list))
+
+			link := &Link{ClientID: o.ClientID, ListID: list.ID, OrderIDs: make([]string, 0, len(list.Orders))}
+
+			for _, o := range list.Orders {
+				a.trackOrder(o)
+				link.OrderIDs = append(link.OrderIDs, o.ID)
+			}
+
+			return link, nil
+		}
+
+		if !errors.Is(err, exchange.ErrUnsupportedOrder) {
+			return nil, fmt.Errorf("create oco order: %w", err)
+		}
+	}
+
+	tp, err := a.createLimit(ctx, o.TakeProfitLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	// The stop loss is watched by the app even on exchanges that support
+	// stop orders, as exchanges that hold the funds of open orders would
+	// reject it while the take profit holds the same funds. The take profit
+	// is cancelled once the stop is triggered.
+	var stop WatchedStop
+
+	if o.StopLimitPrice.IsZero() {
+		stop = watchedStopMarket(o.StopLossMarket())
+	} else {
+		stop = watchedStopLimit(o.StopLossLimit())
+	}
+
+	if _, err := a.watchStop(stop); err != nil {
+		return nil, err
+	}
+
+	return &Link{ClientID: o.ClientID, OrderIDs: []string{tp.ID}, Stop: &stop}, nil
+}
+
+// cancelLegs cancels the other legs of the OCO order of the triggered stop,
+// and confirms that they are no longer open, as exchanges hold the funds of
+// open orders that the stop loss needs. It returns the stop with the size that
+// is left for it, which is reduced by what the legs filled before they were
+// cancelled. Stops that are not part of an OCO order are returned as they are.
+func (a *App) cancelLegs(ctx context.Context, s WatchedStop) (WatchedStop, error) {
+	ids := a.links.legs(s.ClientID)
+	if len(ids) == 0 {
+		return s, nil
+	}
+
+	if err := a.exchange.CancelOrders(ctx, ids...); err != nil {
+		return WatchedStop{}, fmt.Errorf("cancel oco legs: %w", err)
+	}
+
+	size := s.size()
+
+	for _, id := range ids {
+		leg, err := a.exchange.GetOrder(ctx, s.Pair, id)
+		if err != nil {
+			return WatchedStop{}, fmt.Errorf("get oco leg: %w", err)
+		}
+
+		if !leg.Status.Terminal() {
+			return WatchedStop{}, fmt.Errorf("%s: %w", id, ErrLegOpen)
+		}
+
+		size = size.Sub(leg.FilledSize)
+	}
+
+	if size.Sign() <= 0 {
+		a.logger.Info("oco leg filled before the stop loss", zap.String("id", s.ClientID))
+
+		size = trading.Amount{}
+	}
+
+	// The stop keeps being watched with what is left of its size until it is
+	// placed, as the link no longer has any legs that would close it.
+	settled := s.withSize(size)
+
+	if err := a.triggers.replace(settled); err != nil {
+		return WatchedStop{}, err
+	}
+
+	if err := a.links.closeLegs(settled); err != nil {
+		return WatchedStop{}, err
+	}
+
+	return settled, nil
+}
+
+// placeBracket normalizes the entry and the exit of the bracket, before
+// placing the entry. The exit is placed once the entry has been filled.
+func (a *App) placeBracket(ctx context.Context, b order.Bracket) error {
+	m := a.market(b.Entry.Pair)
+
+	entry, err := a.normalizer.Normalize(m, b.Entry)
+	if err != nil {
+		return fmt.Errorf("normalize entry: %w", err)
+	}
+
+	entry.ClientID = a.idGenerator.GenerateID(a.prefix)
+	b.Entry = entry
+
+	exit, err := a.normalizer.NormalizeOCO(m, b.Exit())
+	if err != nil {
+		return fmt.Errorf("normalize exit: %w", err)
+	}
+
+	eOrder, err := a.createLimit(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	return a.links.add(Link{ClientID: entry.ClientID, EntryOrderID: eOrder.ID, Exit: &exit})
+}
+
+// manageLinks checks the links against the open orders on the exchange. Once
+// the entry of a bracket is no longer open, its exit is placed for the size
+// that the entry filled. The legs of an OCO order are all cancelled once any
+// of them is no longer open, unless the exchange links them natively, in
+// which case the link is dropped once none of them is open.
+func (a *App) manageLinks(ctx context.Context) error {
+	links := a.links.all()
+	if len(links) == 0 {
+		return nil
+	}
+
+	orders, err := a.exchange.ListOpenOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("list open orders: %w", err)
+	}
+
+	open := make(map[string]bool, len(orders))
+
+	for _, o := range orders {
+		open[o.ID] = true
+	}
+
+	kept := make([]Link, 0, len(links))
+
+	for i, l := range links {
+		next, err := a.manageLink(ctx, l, open)
+		if err != nil {
+			// The links that have not been managed are kept for the next
+			// tick.
+			if setErr := a.links.set(append(kept, links[i:]...)); setErr != nil {
+				a.logger.Error("failed to save links", zap.Error(setErr))
+			}
+
+			return err
+		}
+
+		kept = append(kept, next...)
+	}
+
+	return a.links.set(kept)
+}
+
+// exitBracket places the exit of the bracket once its entry has been filled.
+// An entry that was cancelled, rejected or expired only has an exit for the
+// size that it filled before then, if any, and if that size can be placed on
+// the market.
+func (a *App) exitBracket(ctx context.Context, l Link) ([]Link, error) {
+	entry, err := a.exchange.GetOrder(ctx, l.Exit.Pair, l.EntryOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("get bracket entry: %w", err)
+	}
+
+	exit := *l.Exit
+
+	switch {
+	case !entry.Status.Terminal():
+		// The entry was not listed as open, but has not been closed either,
+		// such as while the exchange catches up.
+		return []Link{l}, nil
+	case entry.Status == order.StatusFilled:
+		a.logger.Info("bracket entry filled, placing exit", zap.String("id", l.ClientID))
+	case entry.FilledSize.Sign() > 0:
+		a.logger.Info("bracket entry partly filled, placing exit for the filled size",
+			zap.String("id", l.ClientID), zap.Any("status", entry.Status), zap.Stringer("filled", entry.FilledSize))
+
+		exit.BaseSize = entry.FilledSize
+
+		// The filled size may be below the filters of the market, which
+		// would be rejected on every tick, so the exit is dropped instead.
+		if exit, err = a.normalizer.NormalizeOCO(a.market(exit.Pair), exit); err != nil {
+			a.logger.Warn("bracket exit for the filled size cannot be placed, dropping exit",
+				zap.String("id", l.ClientID), zap.Error(err))
+
+			return nil, nil
+		}
+	default:
+		a.logger.Info("bracket entry closed without a fill, dropping exit",
+			zap.String("id", l.ClientID), zap.Any("status", entry.Status))
+
+		return nil, nil
+	}
+
+	link, err := a.createOCO(ctx, exit)
+	if err != nil || link == nil {
+		return nil, err
+	}
+
+	return []Link{*link}, nil
+}
+
+// manageLink returns the links that replace the link, which is the link
+// itself while nothing has changed.
+func (a *App) manageLink(ctx context.Context, l Link, open map[string]bool) ([]Link, error) {
+	if l.Exit != nil {
+		if open[l.EntryOrderID] {
+			return []Link{l}, nil
+		}
+
+		return a.exitBracket(ctx, l)
+	}
+
+	openIDs := make([]string, 0, len(l.OrderIDs))
+
+	for _, id := range l.OrderIDs {
+		if open[id] {
+			openIDs = append(openIDs, id)
+		}
+	}
+
+	// The exchange cancels the other legs of a native OCO order itself, so
+	// its link is only held while any of its legs is open.
+	if l.ListID != "" {
+		if len(openIDs) > 0 {
+			return []Link{l}, nil
+		}
+
+		return nil, nil
+	}
+
+	stopWatched := l.Stop != nil && a.triggers.watching(l.Stop.ClientID)
+
+	if len(openIDs) == len(l.OrderIDs) && (l.Stop == nil || stopWatched) {
+		return []Link{l}, nil
+	}
+
+	a.logger.Info("oco leg filled, cancelling the other legs", zap.String("id", l.ClientID))
+
+	if stopWatched {
+		if _, err := a.triggers.remove(l.Stop.ClientID); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(openIDs) > 0 {
+		if err := a.exchange.CancelOrders(ctx, openIDs...); err != nil {
+			return nil, fmt.Errorf("cancel oco legs: %w", err)
+		}
+	}
+
+	return nil, nil
+}
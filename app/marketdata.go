@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/orderbook"
+	"github.com/project-code-io/crypto-trading-bot-go/strategy"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// marketFeed holds the market data stream of the app, along with the last
+// price of the pair that it has streamed and the order books that it keeps.
+// It is safe for concurrent use.
+type marketFeed struct {
+	stream   MarketDataStream
+	channels []marketdata.Channel
+	books    *orderbook.Books
+
+	mu    sync.Mutex
+	price trading.Amount
+}
+
+// subscribe subscribes to the ticker and the channels of the feed for the
+// pair, along with the book channel if the feed keeps order books. It
+// returns a nil channel if the feed has no stream, which never receives.
+func (f *marketFeed) subscribe(ctx context.Context, pair trading.Pair) (<-chan marketdata.Event, error) {
+	if f.stream == nil {
+		return nil, nil
+	}
+
+	channels := []marketdata.Channel{marketdata.ChannelTicker}
+
+	if f.books != nil {
+		channels = append(channels, marketdata.ChannelBook)
+	}
+
+	for _, c := range f.channels {
+		if !hasChannel(channels, c) {
+			channels = append(channels, c)
+		}
+	}
+
+	events, err := f.stream.Subscribe(ctx, marketdata.Subscription{
+		Pairs:    []trading.Pair{pair},
+		Channels: channels,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+
+	return events, nil
+}
+
+func hasChannel(channels []marketdata.Channel, c marketdata.Channel) bool {
+	for _, ch := range channels {
+		if ch == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lastPrice returns the last price that has been streamed, which is zero if
+// there is none.
+func (f *marketFeed) lastPrice() trading.Amount {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.price
+}
+
+func (f *marketFeed) setPrice(price trading.Amount) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.price = price
+}
+
+// lastPrice returns the last price of the pair, which is taken from the
+// market data stream if it has streamed one, or else asked of the exchange.
+func (a *App) lastPrice(ctx context.Context) (string, error) {
+	if price := a.marketData.lastPrice(); price.Sign() > 0 {
+		return price.String(), nil
+	}
+
+	return a.exchange.GetLastPrice(ctx, a.pair)
+}
+
+// handleMarketData keeps the app up to date with the event, then passes it to
+// the strategy if it observes market data and carries out the intents that it
+// returns.
+func (a *App) handleMarketData(ctx context.Context, event marketdata.Event) error {
+	if err := a.trackMarketData(ctx, event); err != nil {
+		return err
+	}
+
+	if a.marketData.books != nil {
+		// The book is synced again on a later update, so the strategy is
+		// still told of the event.
+		if err := a.marketData.books.Apply(ctx, event); err != nil {
+			a.logger.Warn("failed to sync order book", zap.Error(err))
+		}
+	}
+
+	observer, ok := a.strategy.(strategy.MarketDataObserver)
+	if !ok {
+		return nil
+	}
+
+	intents, err := observer.OnMarketData(ctx, a.exchange, event)
+	if err != nil {
+		return fmt.Errorf("on market data: %w", err)
+	}
+
+	return a.execute(ctx, intents)
+}
+
+// trackMarketData keeps the last price and the tracked orders up to date with
+// the event.
+func (a *App) trackMarketData(ctx context.Context, event marketdata.Event) error {
+	switch e := event.(type) {
+	case marketdata.Ticker:
+		// Exchanges that only stream the best bid and ask, such as binance,
+		// leave the price of their tickers as zero.
+		if e.Pair == a.pair && e.Price.Sign() > 0 {
+			a.marketData.setPrice(e.Price)
+		}
+	case marketdata.Trade:
+		if e.Pair == a.pair {
+			a.marketData.setPrice(e.Price)
+		}
+	case marketdata.OrderUpdate:
+		return a.updateOrder(ctx, e.Order)
+	case marketdata.Reconnect:
+		// The streamed price is stale until the stream is back, so the
+		// exchange is asked for the price in the meantime.
+		a.logger.Warn("market data stream reconnecting", zap.Error(e.Err))
+		a.marketData.setPrice(trading.Amount{})
+	}
+
+	return nil
+}
+
+// consumeMarketData handles an event from the stream. Errors that the
+// application can recover from are handled within, any other error is
+// returned.
+func (a *App) consumeMarketData(ctx context.Context, event marketdata.Event) error {
+	// The stream closes its channel once the context is cancelled, which
+	// receives nil events until the application notices.
+	if event == nil {
+		return nil
+	}
+
+	if err := a.handleMarketData(ctx, event); err != nil && !a.handleError(ctx, err) {
+		return fmt.Errorf("handle market data: %w", err)
+	}
+
+	return nil
+}
@@ -7,9 +7,11 @@ package app
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	exchange "github.com/project-code-io/crypto-trading-bot-go/exchange"
+	marketdata "github.com/project-code-io/crypto-trading-bot-go/marketdata"
 	order "github.com/project-code-io/crypto-trading-bot-go/order"
 	trading "github.com/project-code-io/crypto-trading-bot-go/trading"
 )
@@ -71,11 +73,26 @@ func (mr *mockExchangeClientMockRecorder) CreateLimitOrder(ctx, order interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLimitOrder", reflect.TypeOf((*mockExchangeClient)(nil).CreateLimitOrder), ctx, order)
 }
 
+// CreateMarketOrder mocks base method.
+func (m *mockExchangeClient) CreateMarketOrder(ctx context.Context, order order.Market) (exchange.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMarketOrder", ctx, order)
+	ret0, _ := ret[0].(exchange.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMarketOrder indicates an expected call of CreateMarketOrder.
+func (mr *mockExchangeClientMockRecorder) CreateMarketOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMarketOrder", reflect.TypeOf((*mockExchangeClient)(nil).CreateMarketOrder), ctx, order)
+}
+
 // GetBalance mocks base method.
-func (m *mockExchangeClient) GetBalance(ctx context.Context, asset trading.Asset) (int64, error) {
+func (m *mockExchangeClient) GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBalance", ctx, asset)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].(trading.Amount)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -101,6 +118,36 @@ func (mr *mockExchangeClientMockRecorder) GetLastPrice(ctx, pair interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastPrice", reflect.TypeOf((*mockExchangeClient)(nil).GetLastPrice), ctx, pair)
 }
 
+// GetOrder mocks base method.
+func (m *mockExchangeClient) GetOrder(ctx context.Context, pair trading.Pair, orderID string) (exchange.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, pair, orderID)
+	ret0, _ := ret[0].(exchange.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *mockExchangeClientMockRecorder) GetOrder(ctx, pair, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*mockExchangeClient)(nil).GetOrder), ctx, pair, orderID)
+}
+
+// ListFills mocks base method.
+func (m *mockExchangeClient) ListFills(ctx context.Context, pair trading.Pair, since time.Time) ([]exchange.Fill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFills", ctx, pair, since)
+	ret0, _ := ret[0].([]exchange.Fill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFills indicates an expected call of ListFills.
+func (mr *mockExchangeClientMockRecorder) ListFills(ctx, pair, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFills", reflect.TypeOf((*mockExchangeClient)(nil).ListFills), ctx, pair, since)
+}
+
 // ListOpenOrders mocks base method.
 func (m *mockExchangeClient) ListOpenOrders(ctx context.Context) ([]exchange.Order, error) {
 	m.ctrl.T.Helper()
@@ -116,6 +163,291 @@ func (mr *mockExchangeClientMockRecorder) ListOpenOrders(ctx interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenOrders", reflect.TypeOf((*mockExchangeClient)(nil).ListOpenOrders), ctx)
 }
 
+// mockStopOrderCreator is a mock of StopOrderCreator interface.
+type mockStopOrderCreator struct {
+	ctrl     *gomock.Controller
+	recorder *mockStopOrderCreatorMockRecorder
+}
+
+// mockStopOrderCreatorMockRecorder is the mock recorder for mockStopOrderCreator.
+type mockStopOrderCreatorMockRecorder struct {
+	mock *mockStopOrderCreator
+}
+
+// NewmockStopOrderCreator creates a new mock instance.
+func NewmockStopOrderCreator(ctrl *gomock.Controller) *mockStopOrderCreator {
+	mock := &mockStopOrderCreator{ctrl: ctrl}
+	mock.recorder = &mockStopOrderCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockStopOrderCreator) EXPECT() *mockStopOrderCreatorMockRecorder {
+	return m.recorder
+}
+
+// CreateStopLimitOrder mocks base method.
+func (m *mockStopOrderCreator) CreateStopLimitOrder(ctx context.Context, order order.StopLimit) (exchange.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStopLimitOrder", ctx, order)
+	ret0, _ := ret[0].(exchange.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStopLimitOrder indicates an expected call of CreateStopLimitOrder.
+func (mr *mockStopOrderCreatorMockRecorder) CreateStopLimitOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStopLimitOrder", reflect.TypeOf((*mockStopOrderCreator)(nil).CreateStopLimitOrder), ctx, order)
+}
+
+// CreateStopMarketOrder mocks base method.
+func (m *mockStopOrderCreator) CreateStopMarketOrder(ctx context.Context, order order.StopMarket) (exchange.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStopMarketOrder", ctx, order)
+	ret0, _ := ret[0].(exchange.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStopMarketOrder indicates an expected call of CreateStopMarketOrder.
+func (mr *mockStopOrderCreatorMockRecorder) CreateStopMarketOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStopMarketOrder", reflect.TypeOf((*mockStopOrderCreator)(nil).CreateStopMarketOrder), ctx, order)
+}
+
+// mockOCOCreator is a mock of OCOCreator interface.
+type mockOCOCreator struct {
+	ctrl     *gomock.Controller
+	recorder *mockOCOCreatorMockRecorder
+}
+
+// mockOCOCreatorMockRecorder is the mock recorder for mockOCOCreator.
+type mockOCOCreatorMockRecorder struct {
+	mock *mockOCOCreator
+}
+
+// NewmockOCOCreator creates a new mock instance.
+func NewmockOCOCreator(ctrl *gomock.Controller) *mockOCOCreator {
+	mock := &mockOCOCreator{ctrl: ctrl}
+	mock.recorder = &mockOCOCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockOCOCreator) EXPECT() *mockOCOCreatorMockRecorder {
+	return m.recorder
+}
+
+// CreateOCOOrder mocks base method.
+func (m *mockOCOCreator) CreateOCOOrder(ctx context.Context, order order.OCO) (exchange.OrderList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOCOOrder", ctx, order)
+	ret0, _ := ret[0].(exchange.OrderList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOCOOrder indicates an expected call of CreateOCOOrder.
+func (mr *mockOCOCreatorMockRecorder) CreateOCOOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOCOOrder", reflect.TypeOf((*mockOCOCreator)(nil).CreateOCOOrder), ctx, order)
+}
+
+// mockLinkStore is a mock of LinkStore interface.
+type mockLinkStore struct {
+	ctrl     *gomock.Controller
+	recorder *mockLinkStoreMockRecorder
+}
+
+// mockLinkStoreMockRecorder is the mock recorder for mockLinkStore.
+type mockLinkStoreMockRecorder struct {
+	mock *mockLinkStore
+}
+
+// NewmockLinkStore creates a new mock instance.
+func NewmockLinkStore(ctrl *gomock.Controller) *mockLinkStore {
+	mock := &mockLinkStore{ctrl: ctrl}
+	mock.recorder = &mockLinkStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockLinkStore) EXPECT() *mockLinkStoreMockRecorder {
+	return m.recorder
+}
+
+// LoadLinks mocks base method.
+func (m *mockLinkStore) LoadLinks() ([]Link, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadLinks")
+	ret0, _ := ret[0].([]Link)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadLinks indicates an expected call of LoadLinks.
+func (mr *mockLinkStoreMockRecorder) LoadLinks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadLinks", reflect.TypeOf((*mockLinkStore)(nil).LoadLinks))
+}
+
+// SaveLinks mocks base method.
+func (m *mockLinkStore) SaveLinks(links []Link) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLinks", links)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLinks indicates an expected call of SaveLinks.
+func (mr *mockLinkStoreMockRecorder) SaveLinks(links interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLinks", reflect.TypeOf((*mockLinkStore)(nil).SaveLinks), links)
+}
+
+// mockTrailingStopStore is a mock of TrailingStopStore interface.
+type mockTrailingStopStore struct {
+	ctrl     *gomock.Controller
+	recorder *mockTrailingStopStoreMockRecorder
+}
+
+// mockTrailingStopStoreMockRecorder is the mock recorder for mockTrailingStopStore.
+type mockTrailingStopStoreMockRecorder struct {
+	mock *mockTrailingStopStore
+}
+
+// NewmockTrailingStopStore creates a new mock instance.
+func NewmockTrailingStopStore(ctrl *gomock.Controller) *mockTrailingStopStore {
+	mock := &mockTrailingStopStore{ctrl: ctrl}
+	mock.recorder = &mockTrailingStopStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockTrailingStopStore) EXPECT() *mockTrailingStopStoreMockRecorder {
+	return m.recorder
+}
+
+// LoadTrailingStops mocks base method.
+func (m *mockTrailingStopStore) LoadTrailingStops() ([]TrailingStop, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadTrailingStops")
+	ret0, _ := ret[0].([]TrailingStop)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadTrailingStops indicates an expected call of LoadTrailingStops.
+func (mr *mockTrailingStopStoreMockRecorder) LoadTrailingStops() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadTrailingStops", reflect.TypeOf((*mockTrailingStopStore)(nil).LoadTrailingStops))
+}
+
+// SaveTrailingStops mocks base method.
+func (m *mockTrailingStopStore) SaveTrailingStops(stops []TrailingStop) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTrailingStops", stops)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTrailingStops indicates an expected call of SaveTrailingStops.
+func (mr *mockTrailingStopStoreMockRecorder) SaveTrailingStops(stops interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTrailingStops", reflect.TypeOf((*mockTrailingStopStore)(nil).SaveTrailingStops), stops)
+}
+
+// mockWatchedStopStore is a mock of WatchedStopStore interface.
+type mockWatchedStopStore struct {
+	ctrl     *gomock.Controller
+	recorder *mockWatchedStopStoreMockRecorder
+}
+
+// mockWatchedStopStoreMockRecorder is the mock recorder for mockWatchedStopStore.
+type mockWatchedStopStoreMockRecorder struct {
+	mock *mockWatchedStopStore
+}
+
+// NewmockWatchedStopStore creates a new mock instance.
+func NewmockWatchedStopStore(ctrl *gomock.Controller) *mockWatchedStopStore {
+	mock := &mockWatchedStopStore{ctrl: ctrl}
+	mock.recorder = &mockWatchedStopStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockWatchedStopStore) EXPECT() *mockWatchedStopStoreMockRecorder {
+	return m.recorder
+}
+
+// LoadWatchedStops mocks base method.
+func (m *mockWatchedStopStore) LoadWatchedStops() ([]WatchedStop, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadWatchedStops")
+	ret0, _ := ret[0].([]WatchedStop)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadWatchedStops indicates an expected call of LoadWatchedStops.
+func (mr *mockWatchedStopStoreMockRecorder) LoadWatchedStops() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadWatchedStops", reflect.TypeOf((*mockWatchedStopStore)(nil).LoadWatchedStops))
+}
+
+// SaveWatchedStops mocks base method.
+func (m *mockWatchedStopStore) SaveWatchedStops(stops []WatchedStop) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWatchedStops", stops)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWatchedStops indicates an expected call of SaveWatchedStops.
+func (mr *mockWatchedStopStoreMockRecorder) SaveWatchedStops(stops interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWatchedStops", reflect.TypeOf((*mockWatchedStopStore)(nil).SaveWatchedStops), stops)
+}
+
+// mockMarketDataStream is a mock of MarketDataStream interface.
+type mockMarketDataStream struct {
+	ctrl     *gomock.Controller
+	recorder *mockMarketDataStreamMockRecorder
+}
+
+// mockMarketDataStreamMockRecorder is the mock recorder for mockMarketDataStream.
+type mockMarketDataStreamMockRecorder struct {
+	mock *mockMarketDataStream
+}
+
+// NewmockMarketDataStream creates a new mock instance.
+func NewmockMarketDataStream(ctrl *gomock.Controller) *mockMarketDataStream {
+	mock := &mockMarketDataStream{ctrl: ctrl}
+	mock.recorder = &mockMarketDataStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockMarketDataStream) EXPECT() *mockMarketDataStreamMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *mockMarketDataStream) Subscribe(ctx context.Context, s marketdata.Subscription) (<-chan marketdata.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, s)
+	ret0, _ := ret[0].(<-chan marketdata.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *mockMarketDataStreamMockRecorder) Subscribe(ctx, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*mockMarketDataStream)(nil).Subscribe), ctx, s)
+}
+
 // mockIDGenerator is a mock of IDGenerator interface.
 type mockIDGenerator struct {
 	ctrl     *gomock.Controller
@@ -152,3 +484,54 @@ func (mr *mockIDGeneratorMockRecorder) GenerateID(prefix interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateID", reflect.TypeOf((*mockIDGenerator)(nil).GenerateID), prefix)
 }
+
+// mockClock is a mock of Clock interface.
+type mockClock struct {
+	ctrl     *gomock.Controller
+	recorder *mockClockMockRecorder
+}
+
+// mockClockMockRecorder is the mock recorder for mockClock.
+type mockClockMockRecorder struct {
+	mock *mockClock
+}
+
+// NewmockClock creates a new mock instance.
+func NewmockClock(ctrl *gomock.Controller) *mockClock {
+	mock := &mockClock{ctrl: ctrl}
+	mock.recorder = &mockClockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *mockClock) EXPECT() *mockClockMockRecorder {
+	return m.recorder
+}
+
+// After mocks base method.
+func (m *mockClock) After(d time.Duration) <-chan time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "After", d)
+	ret0, _ := ret[0].(<-chan time.Time)
+	return ret0
+}
+
+// After indicates an expected call of After.
+func (mr *mockClockMockRecorder) After(d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "After", reflect.TypeOf((*mockClock)(nil).After), d)
+}
+
+// Now mocks base method.
+func (m *mockClock) Now() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Now")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// Now indicates an expected call of Now.
+func (mr *mockClockMockRecorder) Now() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Now", reflect.TypeOf((*mockClock)(nil).Now))
+}
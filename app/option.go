@@ -1,5 +1,14 @@
 package app
 
+import (
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/orderbook"
+	"github.com/project-code-io/crypto-trading-bot-go/portfolio"
+	"github.com/project-code-io/crypto-trading-bot-go/strategy"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
 // Option allows for overriding of the internals of the application. These
 // options are typically only meant for internal testing.
 type Option func(a *App)
@@ -12,3 +21,111 @@ func WithIDGenerator(gen IDGenerator) Option {
 		a.idGenerator = gen
 	}
 }
+
+// WithClock overrides the internal clock of the app. Use this method for
+// testing or for running the app against a simulated clock.
+func WithClock(clock Clock) Option {
+	return func(a *App) {
+		a.clock = clock
+	}
+}
+
+// WithPair overrides the pair that the app trades, which is BTC/USD by
+// default.
+func WithPair(pair trading.Pair) Option {
+	return func(a *App) {
+		a.pair = pair
+	}
+}
+
+// WithStrategy overrides the strategy that the app runs, which is the
+// reference HalfPrice strategy by default.
+func WithStrategy(s strategy.Strategy) Option {
+	return func(a *App) {
+		a.strategy = s
+	}
+}
+
+// WithMarkets sets the registry that the app looks up the filters of a market
+// in, such as the tick size and minimum notional, which orders are normalized
+// to before they are placed. Use the registry of the exchange client, i.e.
+// Binance.Markets, so that orders match what the exchange accepts.
+func WithMarkets(markets *trading.Registry) Option {
+	return func(a *App) {
+		a.markets = markets
+	}
+}
+
+// WithNormalizer overrides the normalizer of the app, which changes the
+// direction that the price and size of orders are rounded in for each side.
+func WithNormalizer(n *order.Normalizer) Option {
+	return func(a *App) {
+		a.normalizer = n
+	}
+}
+
+// WithLinkStore sets the store that the app persists the linked orders that
+// it manages to, such as the legs of OCO orders and the exits of brackets. The
+// links are restored from the store when the app starts, and their orders are
+// not cancelled as old orders. Without a store, the links are lost when the
+// app stops.
+func WithLinkStore(store LinkStore) Option {
+	return func(a *App) {
+		a.links.store = store
+	}
+}
+
+// WithTrailingStopStore sets the store that the app persists the trailing
+// stops that it tracks to. The trailing stops are restored from the store when
+// the app starts. Without a store, the trailing stops are lost when the app
+// stops.
+func WithTrailingStopStore(store TrailingStopStore) Option {
+	return func(a *App) {
+		a.trailing.store = store
+	}
+}
+
+// WithWatchedStopStore sets the store that the app persists its stop orders
+// to, which are the stops that it watches on exchanges without native support
+// and the IDs of those placed natively. The stops are restored from the store
+// when the app starts, and native stops are not cancelled as old orders.
+// Without a store, the stops are lost when the app stops.
+func WithWatchedStopStore(store WatchedStopStore) Option {
+	return func(a *App) {
+		a.triggers.store = store
+	}
+}
+
+// WithPortfolio sets the portfolio that the app applies the fills of the pair
+// to, which is marked at the last price and logged on every tick. Read the
+// snapshots of the portfolio to publish them elsewhere.
+func WithPortfolio(p *portfolio.Portfolio) Option {
+	return func(a *App) {
+		a.portfolio = p
+	}
+}
+
+// WithMarketData sets the stream that the app subscribes to for the pair when
+// it starts, along with the given channels. The ticker channel is always
+// subscribed to, and the last price of its tickers, or of the trades channel
+// if it is given, is used on each tick rather than asking the exchange for
+// it. Every event is passed to strategies that observe market data, and the
+// updates of the user channel are passed to strategies that observe their
+// orders as soon as they arrive.
+func WithMarketData(stream MarketDataStream, channels ...marketdata.Channel) Option {
+	return func(a *App) {
+		a.marketData.stream = stream
+		a.marketData.channels = channels
+	}
+}
+
+// WithOrderBooks sets the order books that the app keeps in sync with the
+// market data stream, which subscribes to the book channel for the pair. The
+// books are updated before each event is passed to strategies, so share them
+// with strategies that price off the book, such as post only maker orders.
+// The source of the snapshots of the books is given to the books themselves.
+func WithOrderBooks(books *orderbook.Books) Option {
+	return func(a *App) {
+		a.marketData.books = books
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// updatePortfolio applies the fills of the pair since the last fill that the
+// portfolio has seen, marks the position of the pair at the price, and logs a
+// snapshot of the portfolio.
+func (a *App) updatePortfolio(ctx context.Context, price trading.Amount) error {
+	if a.portfolio == nil {
+		return nil
+	}
+
+	fills, err := a.exchange.ListFills(ctx, a.pair, a.portfolio.LastFillTime())
+	if err != nil {
+		return fmt.Errorf("list fills: %w", err)
+	}
+
+	a.portfolio.Apply(fills...)
+	a.portfolio.SetMark(a.pair, price)
+
+	snapshot := a.portfolio.Snapshot(a.clock.Now())
+
+	for _, p := range snapshot.Positions {
+		a.logger.Info("position",
+			zap.Any("pair", p.Pair),
+			zap.Stringer("size", p.Size),
+			zap.Stringer("cost_basis", p.CostBasis),
+			zap.Stringer("average_cost", p.AverageCost),
+			zap.Stringer("realized_pnl", p.RealizedPnL),
+			zap.Stringer("mark_price", p.MarkPrice),
+			zap.Stringer("unrealized_pnl", p.UnrealizedPnL),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// placedStop is where a stop order has been placed, which is either on the
+// exchange or watched by the app.
+type placedStop struct {
+	orderID string
+	watched *WatchedStop
+}
+
+// placeStopLimit normalizes the stop limit order and tags it with a client ID,
+// unless the strategy has given it one, before placing it.
+func (a *App) placeStopLimit(ctx context.Context, o order.StopLimit) error {
+	o, err := a.normalizer.NormalizeStopLimit(a.market(o.Pair), o)
+	if err != nil {
+		return fmt.Errorf("normalize order: %w", err)
+	}
+
+	o.ClientID = a.clientID(o.ClientID)
+
+	_, err = a.createStopLimit(ctx, o)
+
+	return err
+}
+
+// createStopLimit places the stop limit order on the exchange. If the
+// exchange does not support stop limit orders, the stop is watched by the app
+// instead.
+func (a *App) createStopLimit(ctx context.Context, o order.StopLimit) (placedStop, error) {
+	if native, ok := a.exchange.(StopOrderCreator); ok {
+		a.logger.Info("creating stop limit order", zap.Any("order", o))
+
+		eOrder, err := native.CreateStopLimitOrder(ctx, o)
+		if err == nil {
+			return a.holdStop(eOrder, watchedStopLimit(o))
+		}
+
+		if !errors.Is(err, exchange.ErrUnsupportedOrder) {
+			return placedStop{}, fmt.Errorf("create stop limit order: %w", err)
+		}
+	}
+
+	return a.watchStop(watchedStopLimit(o))
+}
+
+// watchedStopLimit returns the stop limit order as a stop that is watched by
+// the app.
+func watchedStopLimit(o order.StopLimit) WatchedStop {
+	limit := o.Limit()
+
+	return WatchedStop{
+		ClientID:  o.ClientID,
+		Pair:      o.Pair,
+		StopPrice: o.StopPrice,
+		Direction: o.Direction,
+		Limit:     &limit,
+	}
+}
+
+// placeStopMarket normalizes the stop market order and tags it with a client
+// ID, unless the strategy has given it one, before placing it.
+func (a *App) placeStopMarket(ctx context.Context, o order.StopMarket) error {
+	o, err := a.normalizer.NormalizeStopMarket(a.market(o.Pair), o)
+	if err != nil {
+		return fmt.Errorf("normalize order: %w", err)
+	}
+
+	o.ClientID = a.clientID(o.ClientID)
+
+	_, err = a.createStopMarket(ctx, o)
+
+	return err
+}
+
+// createStopMarket places the stop market order on the exchange. If the
+// exchange does not support stop market orders, the stop is watched by the
+// app instead.
+func (a *App) createStopMarket(ctx context.Context, o order.StopMarket) (placedStop, error) {
+	if native, ok := a.exchange.(StopOrderCreator); ok {
+		a.logger.Info("creating stop market order", zap.Any("order", o))
+
+		eOrder, err := native.CreateStopMarketOrder(ctx, o)
+		if err == nil {
+			return a.holdStop(eOrder, watchedStopMarket(o))
+		}
+
+		if !errors.Is(err, exchange.ErrUnsupportedOrder) {
+			return placedStop{}, fmt.Errorf("create stop market order: %w", err)
+		}
+	}
+
+	return a.watchStop(watchedStopMarket(o))
+}
+
+// watchedStopMarket returns the stop market order as a stop that is watched
+// by the app.
+func watchedStopMarket(o order.StopMarket) WatchedStop {
+	market := o.Market()
+
+	return WatchedStop{
+		ClientID:  o.ClientID,
+		Pair:      o.Pair,
+		StopPrice: o.StopPrice,
+		Direction: o.Direction,
+		Market:    &market,
+	}
+}
+
+func (a *App) watchStop(s WatchedStop) (placedStop, error) {
+	a.logger.Info("watching stop order on the client side",
+		zap.String("id", s.ClientID), zap.Any("pair", s.Pair), zap.Stringer("stop_price", s.StopPrice))
+
+	if err := a.triggers.add(s); err != nil {
+		return placedStop{}, err
+	}
+
+	return placedStop{watched: &s}, nil
+}
+
+// holdStop holds on to the stop that has been placed natively as the order,
+// so that it is not cancelled as an old order when the app restarts.
+func (a *App) holdStop(eOrder exchange.Order, s WatchedStop) (placedStop, error) {
+	a.logger.Info("order created", zap.Any("exchange_order", eOrder))
+	a.trackOrder(eOrder)
+
+	s.OrderID = eOrder.ID
+
+	if err := a.triggers.add(s); err != nil {
+		return placedStop{}, err
+	}
+
+	return placedStop{orderID: eOrder.ID}, nil
+}
+
+// fireStops places the orders of the stops that are watched by the app once
+// the price of the pair has reached their stop price. The stop loss of an OCO
+// order is only placed once the other legs have been cancelled. A stop is only
+// removed once its order has been placed, so that the stops whose order could
+// not be placed, such as while rate limited, fire again on the next tick.
+func (a *App) fireStops(ctx context.Context, price trading.Amount) error {
+	for _, s := range a.triggers.triggered(a.pair, price) {
+		a.logger.Info("stop order triggered", zap.String("id", s.ClientID), zap.Stringer("price", price))
+
+		settled, err := a.cancelLegs(ctx, s)
+		if err != nil {
+			return fmt.Errorf("fire stop %s: %w", s.ClientID, err)
+		}
+
+		if settled.size().Sign() > 0 {
+			if err := a.placeTriggered(ctx, settled); err != nil {
+				return fmt.Errorf("fire stop %s: %w", s.ClientID, err)
+			}
+		}
+
+		if _, err := a.triggers.remove(s.ClientID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// placeTriggered places the order of the triggered stop.
+func (a *App) placeTriggered(ctx context.Context, s WatchedStop) error {
+	var err error
+
+	if s.Limit != nil {
+		_, err = a.createLimit(ctx, *s.Limit)
+	} else {
+		_, err = a.createMarket(ctx, *s.Market)
+	}
+
+	return err
+}
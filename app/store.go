@@ -0,0 +1,166 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileLinkStore persists the links of the app as a JSON file.
+type FileLinkStore struct {
+	path string
+}
+
+var _ LinkStore = (*FileLinkStore)(nil)
+
+// NewFileLinkStore acts as the default constructor for the FileLinkStore
+// type. The file is created on the first save if it does not exist.
+func NewFileLinkStore(path string) *FileLinkStore {
+	return &FileLinkStore{path: path}
+}
+
+// LoadLinks reads the links from the file. A file that does not exist holds
+// no links.
+func (s *FileLinkStore) LoadLinks() ([]Link, error) {
+	var links []Link
+
+	if err := readJSON(s.path, &links); err != nil {
+		return nil, fmt.Errorf("read links: %w", err)
+	}
+
+	return links, nil
+}
+
+// SaveLinks writes the links to the file.
+func (s *FileLinkStore) SaveLinks(links []Link) error {
+	if err := writeJSON(s.path, links); err != nil {
+		return fmt.Errorf("write links: %w", err)
+	}
+
+	return nil
+}
+
+// FileTrailingStopStore persists the trailing stops of the app as a JSON
+// file.
+type FileTrailingStopStore struct {
+	path string
+}
+
+var _ TrailingStopStore = (*FileTrailingStopStore)(nil)
+
+// NewFileTrailingStopStore acts as the default constructor for the
+// FileTrailingStopStore type. The file is created on the first save if it
+// does not exist.
+func NewFileTrailingStopStore(path string) *FileTrailingStopStore {
+	return &FileTrailingStopStore{path: path}
+}
+
+// LoadTrailingStops reads the trailing stops from the file. A file that does
+// not exist holds no trailing stops.
+func (s *FileTrailingStopStore) LoadTrailingStops() ([]TrailingStop, error) {
+	var stops []TrailingStop
+
+	if err := readJSON(s.path, &stops); err != nil {
+		return nil, fmt.Errorf("read trailing stops: %w", err)
+	}
+
+	return stops, nil
+}
+
+// SaveTrailingStops writes the trailing stops to the file.
+func (s *FileTrailingStopStore) SaveTrailingStops(stops []TrailingStop) error {
+	if err := writeJSON(s.path, stops); err != nil {
+		return fmt.Errorf("write trailing stops: %w", err)
+	}
+
+	return nil
+}
+
+// FileWatchedStopStore persists the stop orders of the app as a JSON file.
+type FileWatchedStopStore struct {
+	path string
+}
+
+var _ WatchedStopStore = (*FileWatchedStopStore)(nil)
+
+// NewFileWatchedStopStore acts as the default constructor for the
+// FileWatchedStopStore type. The file is created on the first save if it does
+// not exist.
+func NewFileWatchedStopStore(path string) *FileWatchedStopStore {
+	return &FileWatchedStopStore{path: path}
+}
+
+// LoadWatchedStops reads the stop orders from the file. A file that does not
+// exist holds no stop orders.
+func (s *FileWatchedStopStore) LoadWatchedStops() ([]WatchedStop, error) {
+	var stops []WatchedStop
+
+	if err := readJSON(s.path, &stops); err != nil {
+		return nil, fmt.Errorf("read watched stops: %w", err)
+	}
+
+	return stops, nil
+}
+
+// SaveWatchedStops writes the stop orders to the file.
+func (s *FileWatchedStopStore) SaveWatchedStops(stops []WatchedStop) error {
+	if err := writeJSON(s.path, stops); err != nil {
+		return fmt.Errorf("write watched stops: %w", err)
+	}
+
+	return nil
+}
+
+// readJSON decodes the JSON file into v. A file that does not exist leaves v
+// as is.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode file: %w", err)
+	}
+
+	return nil
+}
+
+// writeJSON encodes v to the JSON file. It is written to a temporary file
+// first, which then replaces the file, so that the file is never left half
+// written.
+func writeJSON(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/strategy"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// orderTracker holds the last seen state of the orders that the app has
+// placed, until they reach a terminal status. It is safe for concurrent use.
+type orderTracker struct {
+	mu     sync.Mutex
+	orders map[string]exchange.Order
+}
+
+// track starts tracking the order. The order is taken to be new and unfilled,
+// so that any progress it has already made is reported on the next update.
+func (t *orderTracker) track(o exchange.Order) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.orders == nil {
+		t.orders = make(map[string]exchange.Order)
+	}
+
+	o.Status, o.FilledSize = order.StatusNew, trading.Amount{}
+	t.orders[o.ID] = o
+}
+
+// untrack stops tracking the order with the given ID.
+func (t *orderTracker) untrack(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.orders, id)
+}
+
+// tracked returns the last seen state of the orders that are tracked.
+func (t *orderTracker) tracked() []exchange.Order {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	orders := make([]exchange.Order, 0, len(t.orders))
+
+	for _, o := range t.orders {
+		orders = append(orders, o)
+	}
+
+	return orders
+}
+
+// update records the current state of a tracked order, and returns the
+// update if its status or filled size has changed. Orders that reach a
+// terminal status are no longer tracked.
+func (t *orderTracker) update(current exchange.Order) (*strategy.OrderUpdate, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous, exists := t.orders[current.ID]
+	if !exists {
+		return nil, nil
+	}
+
+	if previous.Status == current.Status && previous.FilledSize.Cmp(current.FilledSize) == 0 {
+		return nil, nil
+	}
+
+	if previous.Status != current.Status && !previous.Status.CanTransitionTo(current.Status) {
+		return nil, fmt.Errorf("order %s from %s to %s: %w",
+			current.ID, previous.Status, current.Status, order.ErrInvalidTransition)
+	}
+
+	if current.Status.Terminal() {
+		delete(t.orders, current.ID)
+	} else {
+		t.orders[current.ID] = current
+	}
+
+	return &strategy.OrderUpdate{Order: current, Previous: previous.Status}, nil
+}
+
+// trackOrder starts tracking the order, if the strategy observes the orders
+// that it places.
+func (a *App) trackOrder(o exchange.Order) {
+	if _, ok := a.strategy.(strategy.OrderObserver); !ok || o.ID == "" {
+		return
+	}
+
+	a.orders.track(o)
+}
+
+// reconcileOrders brings the tracked orders up to date with the exchange,
+// and passes any that have changed to the strategy. Orders which are still
+// open are read from the open orders, and the rest are looked up one by one.
+func (a *App) reconcileOrders(ctx context.Context) error {
+	tracked := a.orders.tracked()
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	open, err := a.exchange.ListOpenOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("list open orders: %w", err)
+	}
+
+	openByID := make(map[string]exchange.Order, len(open))
+
+	for _, o := range open {
+		openByID[o.ID] = o
+	}
+
+	for _, o := range tracked {
+		current, err := a.currentOrder(ctx, o, openByID)
+		if err != nil {
+			return err
+		}
+
+		if err := a.updateOrder(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// currentOrder returns the current state of the tracked order. Orders that
+// the exchange no longer knows about are no longer tracked, and are returned
+// as they were last seen, as are orders whose status the exchange did not
+// report.
+func (a *App) currentOrder(
+	ctx context.Context, o exchange.Order, open map[string]exchange.Order,
+) (exchange.Order, error) {
+	if current, isOpen := open[o.ID]; isOpen {
+		if current.Status == "" {
+			current.Status = order.StatusNew
+
+			if current.FilledSize.Sign() > 0 {
+				current.Status = order.StatusPartiallyFilled
+			}
+		}
+
+		return current, nil
+	}
+
+	current, err := a.exchange.GetOrder(ctx, o.Pair, o.ID)
+	if errors.Is(err, exchange.ErrUnknownOrder) {
+		a.logger.Warn("tracked order is unknown to the exchange", zap.String("id", o.ID))
+		a.orders.untrack(o.ID)
+
+		return o, nil
+	}
+
+	if err != nil {
+		return exchange.Order{}, fmt.Errorf("get order %s: %w", o.ID, err)
+	}
+
+	if current.Status == "" {
+		return o, nil
+	}
+
+	return current, nil
+}
+
+// updateOrder records the current state of the order, and passes it to the
+// strategy if it has changed, carrying out the intents that it returns.
+func (a *App) updateOrder(ctx context.Context, current exchange.Order) error {
+	update, err := a.orders.update(current)
+	if errors.Is(err, order.ErrInvalidTransition) {
+		// The exchange may briefly report an older state of the order, which
+		// is ignored until it catches up.
+		a.logger.Warn("ignoring order update", zap.Error(err))
+		return nil
+	}
+
+	if err != nil || update == nil {
+		return err
+	}
+
+	a.logger.Info("order updated",
+		zap.String("id", current.ID),
+		zap.String("previous", string(update.Previous)),
+		zap.String("status", string(current.Status)),
+		zap.Stringer("filled_size", current.FilledSize))
+
+	observer, ok := a.strategy.(strategy.OrderObserver)
+	if !ok {
+		return nil
+	}
+
+	intents, err := observer.OnOrderUpdate(ctx, a.exchange, *update)
+	if err != nil {
+		return fmt.Errorf("on order update: %w", err)
+	}
+
+	return a.execute(ctx, intents)
+}
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// TrailingStop is a trailing stop order that is tracked by the app, along with
+// the best price that the pair has reached since it was placed. The best price
+// is zero until the app has seen a price of the pair.
+type TrailingStop struct {
+	Order order.TrailingStop `json:"order"`
+	Best  trading.Amount     `json:"best"`
+}
+
+// trailingTracker holds the trailing stops of the app, saving them to the
+// store whenever they change. It is safe for concurrent use.
+type trailingTracker struct {
+	mu    sync.Mutex
+	stops []TrailingStop
+	store TrailingStopStore
+}
+
+// load replaces the trailing stops with those in the store.
+func (t *trailingTracker) load() ([]TrailingStop, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.store == nil {
+		return nil, nil
+	}
+
+	stops, err := t.store.LoadTrailingStops()
+	if err != nil {
+		return nil, fmt.Errorf("load trailing stops: %w", err)
+	}
+
+	t.stops = stops
+
+	return stops, nil
+}
+
+// add starts tracking the trailing stop and saves it.
+func (t *trailingTracker) add(s TrailingStop) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.save(append(t.stops, s))
+}
+
+// remove stops tracking the trailing stops with the given IDs. The IDs which
+// are not tracked are returned.
+func (t *trailingTracker) remove(ids ...string) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	untracked := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		untracked[id] = true
+	}
+
+	kept := make([]TrailingStop, 0, len(t.stops))
+
+	for _, s := range t.stops {
+		if untracked[s.Order.ClientID] {
+			delete(untracked, s.Order.ClientID)
+			continue
+		}
+
+		kept = append(kept, s)
+	}
+
+	remaining := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if untracked[id] {
+			remaining = append(remaining, id)
+		}
+	}
+
+	if len(kept) == len(t.stops) {
+		return remaining, nil
+	}
+
+	return remaining, t.save(kept)
+}
+
+// trail moves the best price of the trailing stops on the pair to the price if
+// it is better, then returns those that the price has triggered. The
+// triggered stops are still tracked, with their best price, until they are
+// removed once their exit has been placed.
+func (t *trailingTracker) trail(p trading.Pair, price trading.Amount) ([]TrailingStop, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.stops) == 0 {
+		return nil, nil
+	}
+
+	triggered := make([]TrailingStop, 0)
+	stops := make([]TrailingStop, 0, len(t.stops))
+	moved := false
+
+	for _, s := range t.stops {
+		switch {
+		case s.Order.Pair != p:
+			// Stops on other pairs are kept as they are.
+		case s.Best.IsZero() || s.Order.Improves(s.Best, price):
+			s.Best = price
+			moved = true
+		case s.Order.Direction().Triggered(price, s.Order.StopPrice(s.Best)):
+			triggered = append(triggered, s)
+		}
+
+		stops = append(stops, s)
+	}
+
+	if !moved {
+		return triggered, nil
+	}
+
+	return triggered, t.save(stops)
+}
+
+func (t *trailingTracker) save(stops []TrailingStop) error {
+	t.stops = stops
+
+	if t.store == nil {
+		return nil
+	}
+
+	if err := t.store.SaveTrailingStops(stops); err != nil {
+		return fmt.Errorf("save trailing stops: %w", err)
+	}
+
+	return nil
+}
+
+// restoreTrailingStops loads the trailing stops from the store, which carry
+// on trailing from the best price that they had reached.
+func (a *App) restoreTrailingStops() error {
+	stops, err := a.trailing.load()
+	if err != nil {
+		return err
+	}
+
+	if len(stops) > 0 {
+		a.logger.Info("restored trailing stops", zap.Int("count", len(stops)))
+	}
+
+	return nil
+}
+
+// placeTrailingStop normalizes the trailing stop and tags it with a client
+// ID, unless the strategy has given it one, before tracking it. The stop
+// starts trailing from the next price of the pair.
+func (a *App) placeTrailingStop(o order.TrailingStop) error {
+	o, err := a.normalizer.NormalizeTrailingStop(a.market(o.Pair), o)
+	if err != nil {
+		return fmt.Errorf("normalize order: %w", err)
+	}
+
+	o.ClientID = a.clientID(o.ClientID)
+
+	a.logger.Info("tracking trailing stop order", zap.Any("order", o))
+
+	return a.trailing.add(TrailingStop{Order: o})
+}
+
+// trailStops moves the trailing stops along with the price, and places the
+// exit of those that the price has triggered. A stop is only removed once its
+// exit has been placed, so that the stops whose exit could not be placed, such
+// as while rate limited, are triggered again on the next tick.
+func (a *App) trailStops(ctx context.Context, price trading.Amount) error {
+	triggered, err := a.trailing.trail(a.pair, price)
+
+	for _, s := range triggered {
+		if exitErr := a.exitTrailingStop(ctx, s); exitErr != nil {
+			return exitErr
+		}
+
+		if _, removeErr := a.trailing.remove(s.Order.ClientID); removeErr != nil {
+			return removeErr
+		}
+	}
+
+	return err
+}
+
+// exitTrailingStop places the order that exits the triggered trailing stop,
+// which is a limit order priced off the stop price if the stop has a limit
+// offset, or a market order otherwise.
+func (a *App) exitTrailingStop(ctx context.Context, s TrailingStop) error {
+	stopPrice := s.Order.StopPrice(s.Best)
+
+	a.logger.Info("trailing stop order triggered",
+		zap.String("id", s.Order.ClientID), zap.Stringer("best", s.Best), zap.Stringer("stop_price", stopPrice))
+
+	if s.Order.LimitOffset.IsZero() {
+		if _, err := a.createMarket(ctx, s.Order.Market()); err != nil {
+			return fmt.Errorf("exit trailing stop %s: %w", s.Order.ClientID, err)
+		}
+
+		return nil
+	}
+
+	// The stop price of a percentage trail is not on the tick size, so the
+	// limit order is normalized before it is placed.
+	limit, err := a.normalizer.Normalize(a.market(s.Order.Pair), s.Order.Limit(stopPrice))
+	if err != nil {
+		return fmt.Errorf("normalize exit of trailing stop %s: %w", s.Order.ClientID, err)
+	}
+
+	if _, err := a.createLimit(ctx, limit); err != nil {
+		return fmt.Errorf("exit trailing stop %s: %w", s.Order.ClientID, err)
+	}
+
+	return nil
+}
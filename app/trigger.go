@@ -0,0 +1,252 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// WatchedStop is a stop order that is watched by the app rather than placed
+// on the exchange. Only one of Limit and Market is set, which is the order
+// that is placed once the stop is triggered.
+type WatchedStop struct {
+	ClientID  string          `json:"client_id"`
+	Pair      trading.Pair    `json:"pair"`
+	StopPrice trading.Amount  `json:"stop_price"`
+	Direction order.Direction `json:"direction"`
+	Limit     *order.Limit    `json:"limit,omitempty"`
+	Market    *order.Market   `json:"market,omitempty"`
+
+	// OrderID is the ID of the stop order on the exchange if it has been
+	// placed natively. Such stops are never triggered by the app, which only
+	// holds on to them so that they are not cancelled as old orders when the
+	// app restarts.
+	OrderID string `json:"order_id,omitempty"`
+}
+
+// size returns the base size of the order of the stop.
+func (s WatchedStop) size() trading.Amount {
+	if s.Limit != nil {
+		return s.Limit.BaseSize
+	}
+
+	return s.Market.BaseSize
+}
+
+// withSize returns a copy of the stop whose order has the given base size.
+func (s WatchedStop) withSize(size trading.Amount) WatchedStop {
+	if s.Limit != nil {
+		limit := *s.Limit
+		limit.BaseSize = size
+		s.Limit = &limit
+
+		return s
+	}
+
+	market := *s.Market
+	market.BaseSize = size
+	s.Market = &market
+
+	return s
+}
+
+// triggerEngine watches the stop orders that an exchange cannot place
+// natively, and hands back those whose stop price has been reached. It also
+// holds the stop orders that have been placed natively. The stops are saved to
+// the store whenever they change. It is safe for concurrent use.
+type triggerEngine struct {
+	mu    sync.Mutex
+	stops []WatchedStop
+	store WatchedStopStore
+}
+
+// load replaces the stops with those in the store.
+func (t *triggerEngine) load() ([]WatchedStop, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.store == nil {
+		return nil, nil
+	}
+
+	stops, err := t.store.LoadWatchedStops()
+	if err != nil {
+		return nil, fmt.Errorf("load watched stops: %w", err)
+	}
+
+	t.stops = stops
+
+	return stops, nil
+}
+
+// add starts watching the stop and saves it.
+func (t *triggerEngine) add(s WatchedStop) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.save(append(t.stops, s))
+}
+
+// replace replaces the stop with the same ID as the given stop and saves it.
+func (t *triggerEngine) replace(s WatchedStop) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stops := append([]WatchedStop(nil), t.stops...)
+
+	for i := range stops {
+		if stops[i].ClientID == s.ClientID {
+			stops[i] = s
+		}
+	}
+
+	return t.save(stops)
+}
+
+// remove stops watching the stops with the given IDs, which are their client
+// IDs or, for stops that have been placed natively, either of their IDs. The
+// IDs which are not watched are returned, as they are of orders on the
+// exchange, along with the order IDs of the native stops that were removed.
+func (t *triggerEngine) remove(ids ...string) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	removed := make(map[string]bool, len(ids))
+
+	for _, id := range ids {
+		removed[id] = true
+	}
+
+	kept := make([]WatchedStop, 0, len(t.stops))
+	remaining := make([]string, 0, len(ids))
+	found := make(map[string]bool, len(ids))
+
+	for _, s := range t.stops {
+		if !removed[s.ClientID] && (s.OrderID == "" || !removed[s.OrderID]) {
+			kept = append(kept, s)
+			continue
+		}
+
+		found[s.ClientID] = true
+
+		// A native stop is still to be cancelled on the exchange.
+		if s.OrderID != "" {
+			found[s.OrderID] = true
+			remaining = append(remaining, s.OrderID)
+		}
+	}
+
+	for _, id := range ids {
+		if !found[id] {
+			remaining = append(remaining, id)
+		}
+	}
+
+	if len(kept) == len(t.stops) {
+		return remaining, nil
+	}
+
+	return remaining, t.save(kept)
+}
+
+// prune stops holding on to the native stops whose order is not open, as
+// they have been filled or cancelled on the exchange.
+func (t *triggerEngine) prune(open map[string]bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	kept := make([]WatchedStop, 0, len(t.stops))
+
+	for _, s := range t.stops {
+		if s.OrderID == "" || open[s.OrderID] {
+			kept = append(kept, s)
+		}
+	}
+
+	if len(kept) == len(t.stops) {
+		return nil
+	}
+
+	return t.save(kept)
+}
+
+// orderIDs returns the IDs of the stop orders that have been placed natively.
+func (t *triggerEngine) orderIDs() map[string]bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := make(map[string]bool)
+
+	for _, s := range t.stops {
+		if s.OrderID != "" {
+			ids[s.OrderID] = true
+		}
+	}
+
+	return ids
+}
+
+// watching reports whether the stop with the given ID is being watched.
+func (t *triggerEngine) watching(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.stops {
+		if s.ClientID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// triggered returns the stops on the pair whose stop price has been reached
+// by the price, in the order they were added. The stops are still watched
+// until they are removed, which is once their order has been placed. Stops
+// that have been placed natively are triggered by the exchange instead.
+func (t *triggerEngine) triggered(p trading.Pair, price trading.Amount) []WatchedStop {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	triggered := make([]WatchedStop, 0)
+
+	for _, s := range t.stops {
+		if s.OrderID == "" && s.Pair == p && s.Direction.Triggered(price, s.StopPrice) {
+			triggered = append(triggered, s)
+		}
+	}
+
+	return triggered
+}
+
+func (t *triggerEngine) save(stops []WatchedStop) error {
+	t.stops = stops
+
+	if t.store == nil {
+		return nil
+	}
+
+	if err := t.store.SaveWatchedStops(stops); err != nil {
+		return fmt.Errorf("save watched stops: %w", err)
+	}
+
+	return nil
+}
+
+// restoreStops loads the stops from the store, which are watched again.
+func (a *App) restoreStops() error {
+	stops, err := a.triggers.load()
+	if err != nil {
+		return err
+	}
+
+	if len(stops) > 0 {
+		a.logger.Info("restored stop orders", zap.Int("count", len(stops)))
+	}
+
+	return nil
+}
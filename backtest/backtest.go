@@ -0,0 +1,153 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/project-code-io/crypto-trading-bot-go/app"
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Config describes the starting conditions of a backtest.
+type Config struct {
+	// Pair is the pair that the candles are for, and that the application
+	// trades.
+	Pair trading.Pair
+
+	// Balances holds the starting balance of each asset.
+	Balances map[trading.Asset]trading.Amount
+
+	// MakerFeeBps and TakerFeeBps are the fees charged by the simulated
+	// exchange in basis points.
+	MakerFeeBps int64
+	TakerFeeBps int64
+
+	// Options are passed through to the application. The clock and pair of
+	// the backtest take precedence over any given here.
+	Options []app.Option
+}
+
+// ErrNoCandles describes an error in which a backtest is run without any
+// candles to replay.
+var ErrNoCandles = errors.New("no candles to replay")
+
+// Run replays the candles through the application, using a paper exchange
+// and a simulated clock. For each candle the exchange is moved through the
+// open, high and low prices, matching any resting orders, before the
+// application runs a single iteration of its trading loop at the close.
+func Run(ctx context.Context, logger *zap.Logger, candles []marketdata.Candle, cfg Config) (*Report, error) {
+	if len(candles) == 0 {
+		return nil, ErrNoCandles
+	}
+
+	clock := NewClock(candles[0].Start)
+	feed := &candleFeed{pair: cfg.Pair}
+
+	paperOpts := []exchange.PaperOption{
+		exchange.WithPaperClock(clock.Now),
+		exchange.WithPaperFees(cfg.MakerFeeBps, cfg.TakerFeeBps),
+	}
+
+	for asset, amount := range cfg.Balances {
+		paperOpts = append(paperOpts, exchange.WithPaperBalance(asset, amount))
+	}
+
+	paper := exchange.NewPaper(feed, paperOpts...)
+
+	appOpts := make([]app.Option, 0, len(cfg.Options))
+	appOpts = append(appOpts, cfg.Options...)
+	appOpts = append(appOpts, app.WithPair(cfg.Pair), app.WithClock(clock))
+
+	a := app.New(logger, paper, appOpts...)
+
+	rec := newRecorder(cfg.Pair, paper.Balances(), candles[0].Open)
+
+	for _, c := range candles {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		clock.Set(c.Start)
+
+		if err := replay(paper, cfg.Pair, c); err != nil {
+			return nil, fmt.Errorf("replay candle at %s: %w", c.Start, err)
+		}
+
+		feed.set(c.Close.String())
+
+		if err := a.Tick(ctx); err != nil {
+			return nil, fmt.Errorf("tick at %s: %w", c.Start, err)
+		}
+
+		rec.record(paper.Balances(), c.Close)
+	}
+
+	return rec.report(candles, paper.Fills()), nil
+}
+
+// replay moves the paper exchange through the prices of the candle before it
+// closes. The order of the high and the low within a candle is not known, so
+// it is assumed that a rising candle reaches its low first and a falling
+// candle reaches its high first.
+func replay(paper *exchange.Paper, pair trading.Pair, c marketdata.Candle) error {
+	prices := []trading.Amount{c.Open, c.High, c.Low}
+
+	if c.Close.Cmp(c.Open) >= 0 {
+		prices = []trading.Amount{c.Open, c.Low, c.High}
+	}
+
+	for _, price := range prices {
+		if err := paper.SetPrice(pair, price.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// candleFeed is the price feed of the paper exchange, which returns the
+// close of the candle that is being replayed.
+type candleFeed struct {
+	mu    sync.Mutex
+	pair  trading.Pair
+	price string
+}
+
+func (f *candleFeed) set(price string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.price = price
+}
+
+func (f *candleFeed) GetLastPrice(ctx context.Context, p trading.Pair) (string, error) {
+	if p != f.pair {
+		return "", exchange.ErrMissingPair
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.price, nil
+}
+
+// interval returns the time between the candles, which is the interval of
+// the candles if they have one, or else the time between the first two.
+func interval(candles []marketdata.Candle) time.Duration {
+	if candles[0].Interval > 0 {
+		return candles[0].Interval
+	}
+
+	if len(candles) < 2 {
+		return 0
+	}
+
+	return candles[1].Start.Sub(candles[0].Start)
+}
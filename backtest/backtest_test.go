@@ -0,0 +1,232 @@
+package backtest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/project-code-io/crypto-trading-bot-go/app"
+	"github.com/project-code-io/crypto-trading-bot-go/backtest"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/portfolio"
+	"github.com/project-code-io/crypto-trading-bot-go/strategy"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := `time,open,high,low,close,volume
+2023-01-01T00:00:00Z,100,110,95,105,12.5
+1672534800,105,106,90,91,3
+`
+
+	candles, err := backtest.ReadCSV(strings.NewReader(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []marketdata.Candle{
+		{
+			Start:    time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			Interval: time.Hour,
+			Open:     trading.MustParseAmount("100"),
+			High:     trading.MustParseAmount("110"),
+			Low:      trading.MustParseAmount("95"),
+			Close:    trading.MustParseAmount("105"),
+			Volume:   trading.MustParseAmount("12.5"),
+			Closed:   true,
+		},
+		{
+			Start:    time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC),
+			Interval: time.Hour,
+			Open:     trading.MustParseAmount("105"),
+			High:     trading.MustParseAmount("106"),
+			Low:      trading.MustParseAmount("90"),
+			Close:    trading.MustParseAmount("91"),
+			Volume:   trading.MustParseAmount("3"),
+			Closed:   true,
+		},
+	}, candles)
+
+	_, err = backtest.ReadCSV(strings.NewReader(data + "yesterday,1,1,1,1,1\n"))
+	assert.ErrorIs(t, err, backtest.ErrBadCandle)
+
+	_, err = backtest.ReadCSV(strings.NewReader(data + "1672538400,1,1,one,1,1\n"))
+	assert.ErrorIs(t, err, backtest.ErrBadCandle)
+}
+
+func TestClock(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := backtest.NewClock(start)
+
+	assert.Equal(t, start.Add(time.Second), <-clock.After(time.Second))
+	assert.Equal(t, start.Add(time.Second), clock.Now())
+
+	clock.Set(start)
+	assert.Equal(t, start.Add(time.Second), clock.Now(), "clock should not move backwards")
+
+	clock.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+}
+
+func TestRun(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	candle := func(offset time.Duration, open string, high string, low string, closePrice string) marketdata.Candle {
+		return marketdata.Candle{
+			Pair:     trading.BTCUSD,
+			Start:    start.Add(offset),
+			Interval: time.Hour,
+			Open:     trading.MustParseAmount(open),
+			High:     trading.MustParseAmount(high),
+			Low:      trading.MustParseAmount(low),
+			Close:    trading.MustParseAmount(closePrice),
+			Closed:   true,
+		}
+	}
+
+	candles := []marketdata.Candle{
+		candle(0, "100", "101", "99", "100"),
+		candle(time.Hour, "100", "125", "98", "120"),
+		candle(time.Hour*2, "120", "121", "85", "90"),
+		candle(time.Hour*3, "90", "112", "89", "110"),
+	}
+
+	report, err := backtest.Run(context.Background(), zaptest.NewLogger(t), candles, backtest.Config{
+		Pair: trading.BTCUSD,
+		Balances: map[trading.Asset]trading.Amount{
+			trading.USD: trading.MustParseAmount("100"),
+			trading.BTC: trading.MustParseAmount("1"),
+		},
+		MakerFeeBps: 10,
+		TakerFeeBps: 20,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, start, report.Start)
+	assert.Equal(t, start.Add(time.Hour*4), report.End)
+	assert.Empty(t, report.Trades, "orders at half the price should never fill")
+	assert.Equal(t, trading.MustParseAmount("200"), report.StartEquity)
+	assert.Equal(t, trading.MustParseAmount("210"), report.FinalEquity)
+	assert.Equal(t, trading.MustParseAmount("10"), report.PnL)
+	assert.Equal(t, trading.MustParseAmount("100"), report.FinalBalances[trading.USD])
+	assert.InDelta(t, 3000.0/22000.0, report.MaxDrawdown, 1e-9)
+	assert.Zero(t, report.WinRate)
+	assert.InDelta(t, 14.2332116, report.SharpeRatio, 1e-6)
+	assert.Contains(t, report.String(), "pnl:           10 USD")
+}
+
+// scriptedStrategy is a strategy that places the next of its orders on each
+// tick, until it runs out of them.
+type scriptedStrategy struct {
+	orders []order.Limit
+}
+
+func (s *scriptedStrategy) OnTick(
+	ctx context.Context, account strategy.Account, tick strategy.Tick,
+) ([]strategy.Intent, error) {
+	if len(s.orders) == 0 {
+		return nil, nil
+	}
+
+	o := s.orders[0]
+	s.orders = s.orders[1:]
+
+	return []strategy.Intent{strategy.PlaceLimit{Order: o}}, nil
+}
+
+func TestRunWithFills(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	amount := trading.MustParseAmount
+
+	candle := func(offset time.Duration, open string, high string, low string, closePrice string) marketdata.Candle {
+		return marketdata.Candle{
+			Pair:     trading.BTCUSD,
+			Start:    start.Add(offset),
+			Interval: time.Hour,
+			Open:     amount(open),
+			High:     amount(high),
+			Low:      amount(low),
+			Close:    amount(closePrice),
+			Closed:   true,
+		}
+	}
+
+	limit := func(side order.Side, price string) order.Limit {
+		return order.Limit{Pair: trading.BTCUSD, Side: side, BaseSize: amount("1"), Price: amount(price)}
+	}
+
+	// Each order rests on the book until the next candle reaches its price.
+	// The first round trip is sold at a profit, and the second at a loss.
+	strat := &scriptedStrategy{orders: []order.Limit{
+		limit(order.SideBuy, "90"),
+		limit(order.SideSell, "110"),
+		limit(order.SideBuy, "100"),
+		limit(order.SideSell, "90"),
+	}}
+
+	candles := []marketdata.Candle{
+		candle(0, "100", "101", "99", "100"),
+		candle(time.Hour, "100", "100", "88", "92"),
+		candle(time.Hour*2, "92", "112", "91", "110"),
+		candle(time.Hour*3, "110", "110", "85", "88"),
+		candle(time.Hour*4, "88", "91", "87", "90"),
+	}
+
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	report, err := backtest.Run(context.Background(), zaptest.NewLogger(t), candles, backtest.Config{
+		Pair: trading.BTCUSD,
+		Balances: map[trading.Asset]trading.Amount{
+			trading.USD: amount("200"),
+		},
+		MakerFeeBps: 10,
+		TakerFeeBps: 20,
+		Options:     []app.Option{app.WithStrategy(strat), app.WithPortfolio(p)},
+	})
+
+	assert.NoError(t, err)
+
+	if assert.Len(t, report.Trades, 4) {
+		expected := []struct {
+			side  order.Side
+			price string
+			fee   string
+		}{
+			{side: order.SideBuy, price: "90", fee: "0.09"},
+			{side: order.SideSell, price: "110", fee: "0.11"},
+			{side: order.SideBuy, price: "100", fee: "0.1"},
+			{side: order.SideSell, price: "90", fee: "0.09"},
+		}
+
+		for i, e := range expected {
+			assert.Equal(t, e.side, report.Trades[i].Side, i)
+			assert.Equal(t, amount(e.price), report.Trades[i].Price, i)
+			assert.Equal(t, amount("1"), report.Trades[i].Size, i)
+			assert.Equal(t, amount(e.fee), report.Trades[i].Fee, i)
+		}
+	}
+
+	assert.Equal(t, amount("200"), report.StartEquity)
+	assert.Equal(t, amount("209.61"), report.FinalEquity)
+	assert.Equal(t, amount("9.61"), report.PnL)
+	assert.Equal(t, amount("209.61"), report.FinalBalances[trading.USD])
+	assert.True(t, report.FinalBalances[trading.BTC].IsZero())
+	assert.Equal(t, 0.5, report.WinRate)
+	assert.Contains(t, report.String(), "trades:        4")
+
+	// The gain of 19.8 on the first round trip and the loss of 10.19 on the
+	// second are realized, net of the fees.
+	assert.Equal(t, amount("9.61"), p.Position(trading.BTCUSD).RealizedPnL)
+}
+
+func TestRunWithoutCandles(t *testing.T) {
+	_, err := backtest.Run(context.Background(), zaptest.NewLogger(t), nil, backtest.Config{
+		Pair: trading.BTCUSD,
+	})
+
+	assert.ErrorIs(t, err, backtest.ErrNoCandles)
+}
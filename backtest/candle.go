@@ -0,0 +1,95 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// ErrBadCandle describes an error in which a candle could not be read.
+var ErrBadCandle = errors.New("bad candle")
+
+// ReadCSV reads candles from CSV data with the columns time, open, high, low,
+// close and volume. The time can be given either as an RFC 3339 timestamp or
+// as the number of seconds since the unix epoch. A header row is skipped if
+// one is present. The candles are closed, and their interval is taken to be
+// the time between the first two. The pair is not known from the data, so it
+// is left empty.
+func ReadCSV(r io.Reader) ([]marketdata.Candle, error) {
+	const columns = 6
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = columns
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read csv: %w", err)
+	}
+
+	candles := make([]marketdata.Candle, 0, len(records))
+
+	for i, record := range records {
+		t, err := parseTime(record[0])
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		c, err := parseCandle(t, record[1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		candles = append(candles, c)
+	}
+
+	if len(candles) > 1 {
+		interval := candles[1].Start.Sub(candles[0].Start)
+
+		for i := range candles {
+			candles[i].Interval = interval
+		}
+	}
+
+	return candles, nil
+}
+
+// parseCandle parses the open, high, low and close prices and the volume of
+// a candle.
+func parseCandle(start time.Time, fields []string) (marketdata.Candle, error) {
+	c := marketdata.Candle{Start: start, Closed: true}
+
+	for i, amount := range []*trading.Amount{&c.Open, &c.High, &c.Low, &c.Close, &c.Volume} {
+		v, err := trading.ParseAmount(fields[i])
+		if err != nil {
+			return marketdata.Candle{}, fmt.Errorf("parse amount %q: %v: %w", fields[i], err, ErrBadCandle)
+		}
+
+		*amount = v
+	}
+
+	return c, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time %q: %w", s, ErrBadCandle)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,54 @@
+package backtest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a simulated clock. Rather than waiting, the clock moves forward
+// by the duration given to After, so that the application runs through the
+// historical data as fast as possible.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a simulated clock that starts at the given time.
+func NewClock(start time.Time) *Clock {
+	return &Clock{
+		now: start,
+	}
+}
+
+// Now returns the current simulated time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After moves the clock forward by the duration and returns a channel that
+// has the new time ready on it.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+
+	return ch
+}
+
+// Set moves the clock forward to the given time. The clock never moves
+// backwards, so times before the current time are ignored.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t.After(c.now) {
+		c.now = t
+	}
+}
@@ -0,0 +1,4 @@
+// Package backtest provides a way of evaluating the trading logic of the
+// application against historical data. Candles are replayed through a paper
+// exchange on a simulated clock, and a report of the performance is produced.
+package backtest
@@ -0,0 +1,227 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Report holds the results of a backtest. Equity is the value of all of the
+// balances in the quote asset of the pair.
+type Report struct {
+	Pair          trading.Pair
+	Start         time.Time
+	End           time.Time
+	Trades        []exchange.Fill
+	StartBalances map[trading.Asset]trading.Amount
+	FinalBalances map[trading.Asset]trading.Amount
+	StartEquity   trading.Amount
+	FinalEquity   trading.Amount
+
+	// PnL is the difference between the final and the starting equity.
+	PnL trading.Amount
+
+	// MaxDrawdown is the largest fall in equity from a peak, as a fraction
+	// of the peak.
+	MaxDrawdown float64
+
+	// WinRate is the fraction of sells that were made at a profit, against
+	// the average cost of the base asset that was bought.
+	WinRate float64
+
+	// SharpeRatio is the annualized mean of the returns of each candle,
+	// over their standard deviation. A risk free rate of zero is assumed.
+	SharpeRatio float64
+}
+
+// String returns a human readable summary of the report.
+func (r *Report) String() string {
+	var b strings.Builder
+
+	quote := r.Pair.Quote
+
+	fmt.Fprintf(&b, "pair:          %s/%s\n", r.Pair.Base, r.Pair.Quote)
+	fmt.Fprintf(&b, "period:        %s - %s\n", r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+	fmt.Fprintf(&b, "trades:        %d\n", len(r.Trades))
+	fmt.Fprintf(&b, "start equity:  %s %s\n", r.StartEquity, quote)
+	fmt.Fprintf(&b, "final equity:  %s %s\n", r.FinalEquity, quote)
+	fmt.Fprintf(&b, "pnl:           %s %s\n", r.PnL, quote)
+	fmt.Fprintf(&b, "max drawdown:  %.2f%%\n", r.MaxDrawdown*percent)
+	fmt.Fprintf(&b, "win rate:      %.2f%%\n", r.WinRate*percent)
+	fmt.Fprintf(&b, "sharpe ratio:  %.4f\n", r.SharpeRatio)
+
+	assets := make([]string, 0, len(r.FinalBalances))
+
+	for asset := range r.FinalBalances {
+		assets = append(assets, string(asset))
+	}
+
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		a := trading.Asset(asset)
+
+		fmt.Fprintf(&b, "balance %-5s  %s -> %s\n", a, r.StartBalances[a], r.FinalBalances[a])
+	}
+
+	return b.String()
+}
+
+const percent = 100
+
+// recorder keeps track of the equity of the account over the backtest.
+type recorder struct {
+	pair          trading.Pair
+	startBalances map[trading.Asset]trading.Amount
+	balances      map[trading.Asset]trading.Amount
+	equity        []trading.Amount
+}
+
+func newRecorder(pair trading.Pair, balances map[trading.Asset]trading.Amount, price trading.Amount) *recorder {
+	r := &recorder{
+		pair:          pair,
+		startBalances: balances,
+		balances:      balances,
+	}
+
+	r.record(balances, price)
+
+	return r
+}
+
+func (r *recorder) record(balances map[trading.Asset]trading.Amount, price trading.Amount) {
+	r.balances = balances
+	r.equity = append(r.equity, balances[r.pair.Quote].Add(r.pair.Value(price, balances[r.pair.Base])))
+}
+
+func (r *recorder) report(candles []marketdata.Candle, fills []exchange.Fill) *Report {
+	start := r.equity[0]
+	final := r.equity[len(r.equity)-1]
+
+	return &Report{
+		Pair:          r.pair,
+		Start:         candles[0].Start,
+		End:           candles[len(candles)-1].Start.Add(interval(candles)),
+		Trades:        fills,
+		StartBalances: r.startBalances,
+		FinalBalances: r.balances,
+		StartEquity:   start,
+		FinalEquity:   final,
+		PnL:           final.Sub(start),
+		MaxDrawdown:   maxDrawdown(r.equity),
+		WinRate:       winRate(r.pair, fills),
+		SharpeRatio:   sharpeRatio(r.equity, interval(candles)),
+	}
+}
+
+func maxDrawdown(equity []trading.Amount) float64 {
+	var (
+		peak     trading.Amount
+		drawdown float64
+	)
+
+	for _, e := range equity {
+		if e.Cmp(peak) > 0 {
+			peak = e
+		}
+
+		if peak.Sign() > 0 {
+			drawdown = math.Max(drawdown, peak.Sub(e).Float64()/peak.Float64())
+		}
+	}
+
+	return drawdown
+}
+
+func sharpeRatio(equity []trading.Amount, interval time.Duration) float64 {
+	if len(equity) < 3 || interval <= 0 {
+		return 0
+	}
+
+	returns := make([]float64, 0, len(equity)-1)
+
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1].IsZero() {
+			return 0
+		}
+
+		returns = append(returns, equity[i].Float64()/equity[i-1].Float64()-1)
+	}
+
+	var mean float64
+
+	for _, r := range returns {
+		mean += r
+	}
+
+	mean /= float64(len(returns))
+
+	var variance float64
+
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+
+	stdDev := math.Sqrt(variance / float64(len(returns)-1))
+	if stdDev == 0 {
+		return 0
+	}
+
+	const year = time.Hour * 24 * 365
+
+	return mean / stdDev * math.Sqrt(float64(year)/float64(interval))
+}
+
+// winRate returns the fraction of the sell fills that made a profit, against
+// the average cost of the base asset bought by the preceding buy fills.
+func winRate(pair trading.Pair, fills []exchange.Fill) float64 {
+	var (
+		cost, size  trading.Amount
+		sells, wins int
+	)
+
+	for _, f := range fills {
+		value := pair.Value(f.Price, f.Size)
+
+		if f.Side == order.SideBuy {
+			cost = cost.Add(value).Add(f.Fee)
+			size = size.Add(f.Size)
+
+			continue
+		}
+
+		sells++
+
+		sold := f.Size
+		if sold.Cmp(size) > 0 {
+			sold = size
+		}
+
+		// The division can only fail when nothing is held, in which case
+		// there is no cost to measure the sell against.
+		basis, err := cost.Mul(sold).Div(size, pair.Quote.Decimals(), trading.RoundDown)
+		if err != nil {
+			continue
+		}
+
+		cost = cost.Sub(basis)
+		size = size.Sub(sold)
+
+		if value.Sub(f.Fee).Cmp(basis) > 0 {
+			wins++
+		}
+	}
+
+	if sells == 0 {
+		return 0
+	}
+
+	return float64(wins) / float64(sells)
+}
@@ -2,10 +2,20 @@ package exchange
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/project-code-io/crypto-trading-bot-go/order"
 	"github.com/project-code-io/crypto-trading-bot-go/trading"
@@ -15,18 +25,51 @@ import (
 // configuration with either the .us or the .com domain can be
 // done in the constructor.
 type Binance struct {
-	BaseURL string
+	BaseURL    string
+	APIKey     string
+	APISecret  string
+	RecvWindow time.Duration
+
+	requester requester
+	markets   *trading.Registry
+
+	// timeOffset holds the difference in milliseconds between the binance
+	// server clock and the local clock. It is set by SyncTime.
+	timeOffset int64
 }
 
+// defaultBinanceRecvWindow is how long after the request timestamp binance
+// will still accept a signed request.
+const defaultBinanceRecvWindow = 5 * time.Second
+
 // NewBinance acts as the default constructor for the Binance exchange type.
 // This method takes a BinanceDomain, which is used for specifying either the
-// .us domain or the .com domain.
-func NewBinance(domain BinanceDomain) *Binance {
+// .us domain or the .com domain. Authentication credentials are loaded from
+// the environment, returning an error if any are missing. Options can be
+// passed to override the base URL given by the domain and the HTTP client.
+func NewBinance(domain BinanceDomain, opts ...Option) (*Binance, error) {
+	key, exists := os.LookupEnv("BINANCE_API_KEY")
+	if !exists {
+		return nil, ErrAPIKeyNotSet
+	}
+
+	secret, exists := os.LookupEnv("BINANCE_API_SECRET")
+	if !exists {
+		return nil, ErrAPISecretNotSet
+	}
+
+	o := newOptions(domain.baseURL(), opts)
+
 	e := &Binance{
-		BaseURL: domain.baseURL(),
+		BaseURL:    o.baseURL,
+		APIKey:     key,
+		APISecret:  secret,
+		RecvWindow: defaultBinanceRecvWindow,
+		requester:  o.requester(),
+		markets:    o.registry(defaultBinanceMarkets),
 	}
 
-	return e
+	return e, nil
 }
 
 // ErrBadBinanceDomain describes an error in which the binance domain is not
@@ -35,15 +78,103 @@ var ErrBadBinanceDomain = errors.New(
 	"the binance domain does not match the location of the bot",
 )
 
+// defaultBinanceMarkets returns the markets that are supported before any
+// have been loaded with LoadMarkets.
+func defaultBinanceMarkets() *trading.Registry {
+	return trading.NewRegistry(
+		trading.Market{Pair: trading.BTCUSD, Symbol: "BTCUSD"},
+		trading.Market{Pair: trading.ETHUSD, Symbol: "ETHUSD"},
+	)
+}
+
+// Markets returns the registry of the markets that the client supports.
+func (e *Binance) Markets() *trading.Registry {
+	return e.markets
+}
+
 func (e *Binance) convertPairValue(p trading.Pair) (string, error) {
-	switch p {
-	case trading.BTCUSD:
-		return "BTCUSD", nil
-	case trading.ETHUSD:
-		return "ETHUSD", nil
-	default:
-		return "", ErrMissingPair
+	m, err := e.markets.Market(p)
+	if err != nil {
+		return "", fmt.Errorf("%v: %w", err, ErrMissingPair)
+	}
+
+	return m.Symbol, nil
+}
+
+func (e *Binance) convertSymbol(symbol string) (trading.Pair, error) {
+	m, err := e.markets.MarketBySymbol(symbol)
+	if err != nil {
+		return trading.Pair{}, fmt.Errorf("%v: %w", err, ErrMissingPair)
+	}
+
+	return m.Pair, nil
+}
+
+// LoadMarkets loads the markets that are trading on binance from the
+// exchange info, along with their filters, adding them to the registry of
+// the client. The decimal places of any asset that is not yet known are
+// registered from the precision that binance gives for it.
+func (e *Binance) LoadMarkets(ctx context.Context) error {
+	type filter struct {
+		FilterType  string         `json:"filterType"`
+		TickSize    trading.Amount `json:"tickSize"`
+		StepSize    trading.Amount `json:"stepSize"`
+		MinQty      trading.Amount `json:"minQty"`
+		MinNotional trading.Amount `json:"minNotional"`
+	}
+
+	type exchangeInfoResponse struct {
+		Symbols []struct {
+			Symbol              string   `json:"symbol"`
+			Status              string   `json:"status"`
+			BaseAsset           string   `json:"baseAsset"`
+			BaseAssetPrecision  int      `json:"baseAssetPrecision"`
+			QuoteAsset          string   `json:"quoteAsset"`
+			QuoteAssetPrecision int      `json:"quoteAssetPrecision"`
+			Filters             []filter `json:"filters"`
+		} `json:"symbols"`
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.BaseURL+"/api/v3/exchangeInfo", nil)
+	if err != nil {
+		return fmt.Errorf("create new request: %w", err)
+	}
+
+	var data exchangeInfoResponse
+
+	if err := e.do(req, &data); err != nil {
+		return fmt.Errorf("get exchange info: %w", err)
+	}
+
+	for _, s := range data.Symbols {
+		if s.Status != "TRADING" {
+			continue
+		}
+
+		m := trading.Market{
+			Pair:   trading.Pair{Base: trading.Asset(s.BaseAsset), Quote: trading.Asset(s.QuoteAsset)},
+			Symbol: s.Symbol,
+		}
+
+		for _, f := range s.Filters {
+			switch f.FilterType {
+			case "PRICE_FILTER":
+				m.TickSize = f.TickSize
+			case "LOT_SIZE":
+				m.LotSize = f.StepSize
+				m.MinSize = f.MinQty
+			case "NOTIONAL", "MIN_NOTIONAL":
+				m.MinNotional = f.MinNotional
+			}
+		}
+
+		registerUnknownAsset(m.Pair.Base, s.BaseAssetPrecision)
+		registerUnknownAsset(m.Pair.Quote, s.QuoteAssetPrecision)
+
+		e.markets.Add(m)
 	}
+
+	return nil
 }
 
 // GetLastPrice obtains the last price for the pair on binance.
@@ -66,24 +197,909 @@ func (e *Binance) GetLastPrice(ctx context.Context, p trading.Pair) (string, err
 		return "", fmt.Errorf("create new request: %w", err)
 	}
 
-	res, err := http.DefaultClient.Do(req)
+	var data priceResponse
+
+	if err := e.do(req, &data); err != nil {
+		return "", err
+	}
+
+	return data.Price, nil
+}
+
+// GetOrderBook obtains a snapshot of the order book of the pair on binance,
+// with up to depth price levels on each side. Binance accepts depths of up to
+// 5000 levels.
+func (e *Binance) GetOrderBook(ctx context.Context, p trading.Pair, depth int) (OrderBook, error) {
+	type depthResponse struct {
+		LastUpdateID int64               `json:"lastUpdateId"`
+		Bids         [][2]trading.Amount `json:"bids"`
+		Asks         [][2]trading.Amount `json:"asks"`
+	}
+
+	symbol, err := e.convertPairValue(p)
+	if err != nil {
+		return OrderBook{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	query := url.Values{
+		"symbol": []string{symbol},
+		"limit":  []string{strconv.Itoa(depth)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.BaseURL+"/api/v3/depth?"+query.Encode(), nil)
+	if err != nil {
+		return OrderBook{}, fmt.Errorf("create new request: %w", err)
+	}
+
+	var data depthResponse
+
+	if err := e.do(req, &data); err != nil {
+		return OrderBook{}, err
+	}
+
+	book := OrderBook{
+		Pair:         p,
+		LastUpdateID: data.LastUpdateID,
+		Bids:         make([]Level, 0, len(data.Bids)),
+		Asks:         make([]Level, 0, len(data.Asks)),
+	}
+
+	for _, l := range data.Bids {
+		book.Bids = append(book.Bids, Level{Price: l[0], Size: l[1]})
+	}
+
+	for _, l := range data.Asks {
+		book.Asks = append(book.Asks, Level{Price: l[0], Size: l[1]})
+	}
+
+	return book, nil
+}
+
+// BinanceIntervals maps the intervals of candles to those of binance klines.
+var BinanceIntervals = map[time.Duration]string{
+	time.Second:      "1s",
+	time.Minute:      "1m",
+	3 * time.Minute:  "3m",
+	5 * time.Minute:  "5m",
+	15 * time.Minute: "15m",
+	30 * time.Minute: "30m",
+	time.Hour:        "1h",
+	2 * time.Hour:    "2h",
+	4 * time.Hour:    "4h",
+	6 * time.Hour:    "6h",
+	8 * time.Hour:    "8h",
+	12 * time.Hour:   "12h",
+	24 * time.Hour:   "1d",
+	72 * time.Hour:   "3d",
+	168 * time.Hour:  "1w",
+}
+
+// binanceKlineLimit is the most klines that binance returns for a request.
+const binanceKlineLimit = 1000
+
+// CandleIntervals returns the intervals of the candles that binance offers,
+// from the shortest.
+func (e *Binance) CandleIntervals() []time.Duration {
+	return supportedIntervals(BinanceIntervals)
+}
+
+// GetCandles obtains the candles of the pair with the interval that start
+// from the start time up to but not including the end time, oldest first.
+// The interval must be one of CandleIntervals.
+func (e *Binance) GetCandles(
+	ctx context.Context, p trading.Pair, interval time.Duration, start time.Time, end time.Time,
+) ([]Candle, error) {
+	name, ok := BinanceIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", interval, ErrUnsupportedInterval)
+	}
+
+	symbol, err := e.convertPairValue(p)
+	if err != nil {
+		return nil, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	query := url.Values{
+		"symbol":   []string{symbol},
+		"interval": []string{name},
+		"endTime":  []string{strconv.FormatInt(end.UnixMilli()-1, 10)},
+		"limit":    []string{strconv.Itoa(binanceKlineLimit)},
+	}
+
+	candles := make([]Candle, 0)
+
+	for from := start; from.Before(end); {
+		query.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+
+		page, err := e.getKlines(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		candles = append(candles, page...)
+
+		if len(page) < binanceKlineLimit {
+			break
+		}
+
+		from = page[len(page)-1].Start.Add(interval)
+	}
+
+	return candles, nil
+}
+
+func (e *Binance) getKlines(ctx context.Context, query url.Values) ([]Candle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.BaseURL+"/api/v3/klines?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create new request: %w", err)
+	}
+
+	// Each kline is an array of the open time, the open, high, low and close
+	// prices and the volume, followed by fields that are not needed.
+	var data [][]json.RawMessage
+
+	if err := e.do(req, &data); err != nil {
+		return nil, fmt.Errorf("get klines: %w", err)
+	}
+
+	candles := make([]Candle, 0, len(data))
+
+	for _, kline := range data {
+		const fields = 6
+
+		if len(kline) < fields {
+			return nil, fmt.Errorf("decode kline: %d fields", len(kline))
+		}
+
+		var (
+			openTime int64
+			c        Candle
+		)
+
+		for i, v := range []any{&openTime, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume} {
+			if err := json.Unmarshal(kline[i], v); err != nil {
+				return nil, fmt.Errorf("decode kline: %w", err)
+			}
+		}
+
+		c.Start = BinanceTime(openTime)
+		candles = append(candles, c)
+	}
+
+	return candles, nil
+}
+
+// SyncTime fetches the binance server time and stores the offset from the
+// local clock, so that the timestamp of signed requests falls within the
+// receive window even when the local clock has drifted.
+func (e *Binance) SyncTime(ctx context.Context) error {
+	type timeResponse struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+
+	url := fmt.Sprintf("%s/api/v3/time", e.BaseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create new request: %w", err)
+	}
+
+	var data timeResponse
+
+	if err := e.do(req, &data); err != nil {
+		return err
+	}
+
+	atomic.StoreInt64(&e.timeOffset, data.ServerTime-time.Now().UnixMilli())
+
+	return nil
+}
+
+func (e *Binance) sign(query string) string {
+	hash := hmac.New(sha256.New, []byte(e.APISecret))
+	hash.Write([]byte(query))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// doSigned performs a request against one of the signed (TRADE or USER_DATA)
+// binance endpoints. The params are sent in the query string along with the
+// timestamp, receive window and the HMAC-SHA256 signature of the query.
+func (e *Binance) doSigned(
+	ctx context.Context, method string, path string, params url.Values, v any,
+) error {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	timestamp := time.Now().UnixMilli() + atomic.LoadInt64(&e.timeOffset)
+
+	params.Set("timestamp", strconv.FormatInt(timestamp, 10))
+
+	if e.RecvWindow > 0 {
+		params.Set("recvWindow", strconv.FormatInt(e.RecvWindow.Milliseconds(), 10))
+	}
+
+	query := params.Encode()
+	query += "&signature=" + e.sign(query)
+
+	endpoint := fmt.Sprintf("%s%s?%s", e.BaseURL, path, query)
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("create new request: %w", err)
+	}
+
+	req.Header.Add("X-MBX-APIKEY", e.APIKey)
+
+	return e.do(req, v)
+}
+
+func (e *Binance) do(req *http.Request, v any) error {
+	res, err := e.requester.do(req)
 	if err != nil {
-		return "", fmt.Errorf("perform request: %w", err)
+		return fmt.Errorf("perform request: %w", err)
 	}
 
+	defer res.Body.Close()
+
 	const badLocationCode = 451
 
 	if res.StatusCode == badLocationCode {
-		return "", ErrBadBinanceDomain
+		return ErrBadBinanceDomain
 	}
 
-	var data priceResponse
+	if res.StatusCode != http.StatusOK {
+		return decodeBinanceError(res)
+	}
 
-	if err = json.NewDecoder(res.Body).Decode(&data); err != nil {
-		return "", fmt.Errorf("decode response body: %w", err)
+	if v == nil {
+		return nil
 	}
 
-	return data.Price, nil
+	if err = json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response body: %w", err)
+	}
+
+	return nil
+}
+
+// binanceErrorKinds maps the error codes returned by binance to the class of
+// error that they belong to.
+var binanceErrorKinds = map[int]error{
+	-1001: ErrMaintenance,  // DISCONNECTED
+	-1002: ErrAuthFailed,   // UNAUTHORIZED
+	-1003: ErrRateLimited,  // TOO_MANY_REQUESTS
+	-1013: ErrInvalidOrder, // filter failure, e.g. LOT_SIZE or PRICE_FILTER
+	-1015: ErrRateLimited,  // TOO_MANY_ORDERS
+	-1016: ErrMaintenance,  // SERVICE_SHUTTING_DOWN
+	-1022: ErrAuthFailed,   // INVALID_SIGNATURE
+	-2010: ErrInvalidOrder, // NEW_ORDER_REJECTED
+	-2011: ErrUnknownOrder, // CANCEL_REJECTED
+	-2013: ErrUnknownOrder, // NO_SUCH_ORDER
+	-2014: ErrAuthFailed,   // BAD_API_KEY_FMT
+	-2015: ErrAuthFailed,   // REJECTED_MBX_KEY
+}
+
+// The 11xx error codes describe a problem with the parameters of a request.
+const (
+	binanceMinParamErrorCode = -1199
+	binanceMaxParamErrorCode = -1100
+)
+
+func classifyBinanceError(code int, msg string) error {
+	if code == -2010 && strings.Contains(strings.ToLower(msg), "insufficient balance") {
+		return ErrInsufficientFunds
+	}
+
+	if kind, exists := binanceErrorKinds[code]; exists {
+		return kind
+	}
+
+	if code >= binanceMinParamErrorCode && code <= binanceMaxParamErrorCode {
+		return ErrInvalidOrder
+	}
+
+	return nil
+}
+
+// decodeBinanceError decodes the error payload of a failed response into an
+// APIError, falling back to the HTTP status code to classify it.
+func decodeBinanceError(res *http.Response) error {
+	type errorResponse struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		RetryAfter: retryAfter(res),
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read error body: %w", err)
+	}
+
+	var data errorResponse
+
+	if err := json.Unmarshal(body, &data); err != nil || data.Code == 0 {
+		apiErr.Message = string(body)
+	} else {
+		apiErr.Code = strconv.Itoa(data.Code)
+		apiErr.Message = data.Msg
+		apiErr.Kind = classifyBinanceError(data.Code, data.Msg)
+	}
+
+	if apiErr.Kind == nil {
+		apiErr.Kind = classifyStatus(res.StatusCode)
+	}
+
+	return apiErr
+}
+
+type binanceOrder struct {
+	Symbol              string         `json:"symbol"`
+	OrderID             int64          `json:"orderId"`
+	ClientOrderID       string         `json:"clientOrderId"`
+	Side                string         `json:"side"`
+	Price               trading.Amount `json:"price"`
+	OrigQty             trading.Amount `json:"origQty"`
+	Status              string         `json:"status"`
+	ExecutedQty         trading.Amount `json:"executedQty"`
+	CummulativeQuoteQty trading.Amount `json:"cummulativeQuoteQty"`
+	Time                int64          `json:"time"`
+	TransactTime        int64          `json:"transactTime"`
+	UpdateTime          int64          `json:"updateTime"`
+}
+
+// binanceStatuses maps the statuses of binance orders which are not open to
+// the status of an order. Orders which are pending cancellation are still
+// open until binance has cancelled them.
+var binanceStatuses = map[string]order.Status{
+	"FILLED":           order.StatusFilled,
+	"CANCELED":         order.StatusCancelled,
+	"REJECTED":         order.StatusRejected,
+	"EXPIRED":          order.StatusExpired,
+	"EXPIRED_IN_MATCH": order.StatusExpired,
+}
+
+func (e *Binance) toOrder(o binanceOrder) Order {
+	// Orders on pairs that are not supported still need to be returned, so
+	// that they can be cancelled, which is why the error is ignored here.
+	pair, _ := e.convertSymbol(o.Symbol)
+
+	eOrder := Order{
+		ID:         strconv.FormatInt(o.OrderID, 10),
+		Pair:       pair,
+		Side:       order.Side(o.Side),
+		ClientID:   o.ClientOrderID,
+		BaseSize:   o.OrigQty,
+		Price:      o.Price,
+		FilledSize: o.ExecutedQty,
+		Status:     binanceStatuses[o.Status],
+		CreatedAt:  BinanceTime(o.Time),
+		UpdatedAt:  BinanceTime(o.UpdateTime),
+	}
+
+	switch o.Status {
+	case "NEW", "PARTIALLY_FILLED", "PENDING_CANCEL":
+		eOrder.Status = order.OpenStatus(o.ExecutedQty)
+	}
+
+	if eOrder.CreatedAt.IsZero() {
+		eOrder.CreatedAt = BinanceTime(o.TransactTime)
+	}
+
+	if eOrder.UpdatedAt.IsZero() {
+		eOrder.UpdatedAt = eOrder.CreatedAt
+	}
+
+	if o.ExecutedQty.Sign() > 0 {
+		// The error is ignored as the executed quantity is above zero.
+		eOrder.AveragePrice, _ = o.CummulativeQuoteQty.Div(o.ExecutedQty, pair.Quote.Decimals(), trading.RoundHalfEven)
+	}
+
+	return eOrder
+}
+
+// BinanceTime converts a timestamp in milliseconds to a time, where a zero
+// timestamp is one that binance did not send.
+func BinanceTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(ms).UTC()
+}
+
+// CreateLimitOrder places a new limit order on binance. Post only orders are
+// placed as LIMIT_MAKER orders, which binance rejects if they would
+// immediately match. Binance has no good till date orders, so those return
+// ErrUnsupportedOrder.
+func (e *Binance) CreateLimitOrder(ctx context.Context, o order.Limit) (Order, error) {
+	if err := o.Validate(); err != nil {
+		return Order{}, fmt.Errorf("%v: %w", err, ErrInvalidOrder)
+	}
+
+	tif := o.EffectiveTimeInForce()
+	if tif == order.TimeInForceGTD {
+		return Order{}, fmt.Errorf("good till date: %w", ErrUnsupportedOrder)
+	}
+
+	symbol, err := e.convertPairValue(o.Pair)
+	if err != nil {
+		return Order{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(o.Side))
+	params.Set("quantity", o.BaseSize.String())
+	params.Set("price", o.Price.String())
+	params.Set("newOrderRespType", "RESULT")
+
+	if o.ClientID != "" {
+		params.Set("newClientOrderId", o.ClientID)
+	}
+
+	if o.PostOnly {
+		params.Set("type", "LIMIT_MAKER")
+	} else {
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", string(tif))
+	}
+
+	var data binanceOrder
+
+	if err := e.doSigned(ctx, http.MethodPost, "/api/v3/order", params, &data); err != nil {
+		return Order{}, fmt.Errorf("create order: %w", err)
+	}
+
+	return e.toOrder(data), nil
+}
+
+// CreateMarketOrder places a new market order on binance. Binance has no
+// slippage protection on market orders, so orders with a price limit are
+// placed as limit orders at the price limit that are immediate or cancel,
+// which cancels any part of the order that cannot be filled straight away.
+func (e *Binance) CreateMarketOrder(ctx context.Context, o order.Market) (Order, error) {
+	if err := o.Validate(); err != nil {
+		return Order{}, fmt.Errorf("%v: %w", err, ErrInvalidOrder)
+	}
+
+	symbol, err := e.convertPairValue(o.Pair)
+	if err != nil {
+		return Order{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(o.Side))
+	params.Set("newOrderRespType", "RESULT")
+
+	if o.ClientID != "" {
+		params.Set("newClientOrderId", o.ClientID)
+	}
+
+	switch {
+	case o.PriceLimit.Sign() > 0:
+		params.Set("type", "LIMIT")
+		params.Set("timeInForce", "IOC")
+		params.Set("quantity", protectedBaseSize(e.markets, o).String())
+		params.Set("price", o.PriceLimit.String())
+	case o.QuoteSize.IsZero():
+		params.Set("type", "MARKET")
+		params.Set("quantity", o.BaseSize.String())
+	default:
+		params.Set("type", "MARKET")
+		params.Set("quoteOrderQty", o.QuoteSize.String())
+	}
+
+	var data binanceOrder
+
+	if err := e.doSigned(ctx, http.MethodPost, "/api/v3/order", params, &data); err != nil {
+		return Order{}, fmt.Errorf("create order: %w", err)
+	}
+
+	return e.toOrder(data), nil
+}
+
+// binanceStopType returns the type of a stop order on binance. Binance names
+// a stop by whether it is a loss or a profit for the side, which is a stop
+// loss for a sell below the stop price or a buy above it, and a take profit
+// otherwise.
+func binanceStopType(side order.Side, d order.Direction, limit bool) (string, error) {
+	if !d.Valid() {
+		return "", fmt.Errorf("direction %q: %w", d, ErrInvalidOrder)
+	}
+
+	orderType := "TAKE_PROFIT"
+	if side == order.SideSell && d == order.DirectionBelow || side == order.SideBuy && d == order.DirectionAbove {
+		orderType = "STOP_LOSS"
+	}
+
+	if limit {
+		orderType += "_LIMIT"
+	}
+
+	return orderType, nil
+}
+
+// CreateStopLimitOrder places a new stop limit order on binance, as either a
+// STOP_LOSS_LIMIT or a TAKE_PROFIT_LIMIT order depending on its side and
+// direction.
+func (e *Binance) CreateStopLimitOrder(ctx context.Context, o order.StopLimit) (Order, error) {
+	orderType, err := binanceStopType(o.Side, o.Direction, true)
+	if err != nil {
+		return Order{}, err
+	}
+
+	params := url.Values{}
+	params.Set("type", orderType)
+	params.Set("timeInForce", "GTC")
+	params.Set("quantity", o.BaseSize.String())
+	params.Set("price", o.Price.String())
+	params.Set("stopPrice", o.StopPrice.String())
+
+	return e.createStopOrder(ctx, o.Pair, o.Side, o.ClientID, params)
+}
+
+// CreateStopMarketOrder places a new stop market order on binance, as either
+// a STOP_LOSS or a TAKE_PROFIT order depending on its side and direction.
+// Binance only supports these types on some symbols and domains, and rejects
+// the order otherwise.
+func (e *Binance) CreateStopMarketOrder(ctx context.Context, o order.StopMarket) (Order, error) {
+	orderType, err := binanceStopType(o.Side, o.Direction, false)
+	if err != nil {
+		return Order{}, err
+	}
+
+	params := url.Values{}
+	params.Set("type", orderType)
+	params.Set("quantity", o.BaseSize.String())
+	params.Set("stopPrice", o.StopPrice.String())
+
+	return e.createStopOrder(ctx, o.Pair, o.Side, o.ClientID, params)
+}
+
+// createStopOrder adds the fields that are common to every order to the
+// params of a stop order, before placing it.
+func (e *Binance) createStopOrder(
+	ctx context.Context, p trading.Pair, side order.Side, clientID string, params url.Values,
+) (Order, error) {
+	symbol, err := e.convertPairValue(p)
+	if err != nil {
+		return Order{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	params.Set("symbol", symbol)
+	params.Set("side", string(side))
+	params.Set("newOrderRespType", "RESULT")
+
+	if clientID != "" {
+		params.Set("newClientOrderId", clientID)
+	}
+
+	var data binanceOrder
+
+	if err := e.doSigned(ctx, http.MethodPost, "/api/v3/order", params, &data); err != nil {
+		return Order{}, fmt.Errorf("create order: %w", err)
+	}
+
+	return e.toOrder(data), nil
+}
+
+// CreateOCOOrder places a new OCO order on binance, whose take profit leg is
+// a LIMIT_MAKER order and whose stop loss leg is a STOP_LOSS_LIMIT order, or
+// a STOP_LOSS order without a stop limit price. Binance cancels the other leg
+// once either is filled.
+func (e *Binance) CreateOCOOrder(ctx context.Context, o order.OCO) (OrderList, error) {
+	type ocoResponse struct {
+		OrderListID       int64          `json:"orderListId"`
+		ListClientOrderID string         `json:"listClientOrderId"`
+		OrderReports      []binanceOrder `json:"orderReports"`
+	}
+
+	if err := o.Validate(); err != nil {
+		return OrderList{}, fmt.Errorf("%v: %w", err, ErrInvalidOrder)
+	}
+
+	symbol, err := e.convertPairValue(o.Pair)
+	if err != nil {
+		return OrderList{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("side", string(o.Side))
+	params.Set("quantity", o.BaseSize.String())
+	params.Set("price", o.TakeProfit.String())
+	params.Set("stopPrice", o.StopPrice.String())
+	params.Set("newOrderRespType", "RESULT")
+
+	if !o.StopLimitPrice.IsZero() {
+		params.Set("stopLimitPrice", o.StopLimitPrice.String())
+		params.Set("stopLimitTimeInForce", "GTC")
+	}
+
+	if o.ClientID != "" {
+		params.Set("listClientOrderId", o.ClientID)
+		params.Set("limitClientOrderId", o.TakeProfitLimit().ClientID)
+		params.Set("stopClientOrderId", o.StopLossLimit().ClientID)
+	}
+
+	var data ocoResponse
+
+	if err := e.doSigned(ctx, http.MethodPost, "/api/v3/order/oco", params, &data); err != nil {
+		return OrderList{}, fmt.Errorf("create oco order: %w", err)
+	}
+
+	list := OrderList{
+		ID:       strconv.FormatInt(data.OrderListID, 10),
+		ClientID: data.ListClientOrderID,
+		Orders:   make([]Order, 0, len(data.OrderReports)),
+	}
+
+	for _, report := range data.OrderReports {
+		list.Orders = append(list.Orders, e.toOrder(report))
+	}
+
+	return list, nil
+}
+
+// GetOrder returns the order with the given ID, whether it is open or not.
+// Binance requires the symbol of an order in order to look it up, which is
+// why the pair is needed. ErrUnknownOrder is returned if there is no such
+// order.
+func (e *Binance) GetOrder(ctx context.Context, p trading.Pair, orderID string) (Order, error) {
+	symbol, err := e.convertPairValue(p)
+	if err != nil {
+		return Order{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", orderID)
+
+	var data binanceOrder
+
+	if err := e.doSigned(ctx, http.MethodGet, "/api/v3/order", params, &data); err != nil {
+		return Order{}, fmt.Errorf("get order: %w", err)
+	}
+
+	return e.toOrder(data), nil
+}
+
+// CancelOrders cancels the orders with the given IDs. Binance requires the
+// symbol of an order in order to cancel it, so the open orders are listed
+// first. Orders which are no longer open are ignored as there is nothing to
+// cancel.
+func (e *Binance) CancelOrders(ctx context.Context, orderIDs ...string) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	open, err := e.listOpenOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("list open orders: %w", err)
+	}
+
+	symbols := make(map[string]string, len(open))
+
+	for _, o := range open {
+		symbols[strconv.FormatInt(o.OrderID, 10)] = o.Symbol
+	}
+
+	for _, id := range orderIDs {
+		symbol, exists := symbols[id]
+		if !exists {
+			continue
+		}
+
+		params := url.Values{}
+		params.Set("symbol", symbol)
+		params.Set("orderId", id)
+
+		err := e.doSigned(ctx, http.MethodDelete, "/api/v3/order", params, nil)
+
+		// The order may have been filled since the open orders were listed.
+		if err != nil && !errors.Is(err, ErrUnknownOrder) {
+			return fmt.Errorf("cancel order %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *Binance) listOpenOrders(ctx context.Context) ([]binanceOrder, error) {
+	var data []binanceOrder
+
+	if err := e.doSigned(ctx, http.MethodGet, "/api/v3/openOrders", nil, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// ListOpenOrders returns all of the open orders on the account, across every
+// symbol.
+func (e *Binance) ListOpenOrders(ctx context.Context) ([]Order, error) {
+	data, err := e.listOpenOrders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list open orders: %w", err)
+	}
+
+	orders := make([]Order, 0, len(data))
+
+	for _, o := range data {
+		orders = append(orders, e.toOrder(o))
+	}
+
+	return orders, nil
+}
+
+// binanceTradesLimit is the maximum number of trades that binance returns in
+// a single page.
+const binanceTradesLimit = 1000
+
+// ListFills returns the fills of the orders on the pair since the given
+// time, oldest first, following the trade IDs until every page has been read.
+// A zero time returns every fill. Binance does not return the client ID of
+// the order of a fill.
+func (e *Binance) ListFills(ctx context.Context, p trading.Pair, since time.Time) ([]Fill, error) {
+	type trade struct {
+		ID              int64          `json:"id"`
+		OrderID         int64          `json:"orderId"`
+		Price           trading.Amount `json:"price"`
+		Qty             trading.Amount `json:"qty"`
+		Commission      trading.Amount `json:"commission"`
+		CommissionAsset string         `json:"commissionAsset"`
+		Time            int64          `json:"time"`
+		IsBuyer         bool           `json:"isBuyer"`
+	}
+
+	symbol, err := e.convertPairValue(p)
+	if err != nil {
+		return nil, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("limit", strconv.Itoa(binanceTradesLimit))
+
+	// Binance returns the latest trades without either a start time or a
+	// trade ID to start from.
+	if since.IsZero() {
+		params.Set("fromId", "0")
+	} else {
+		params.Set("startTime", strconv.FormatInt(since.UnixMilli(), 10))
+	}
+
+	fills := make([]Fill, 0)
+
+	for {
+		var data []trade
+
+		if err := e.doSigned(ctx, http.MethodGet, "/api/v3/myTrades", params, &data); err != nil {
+			return nil, fmt.Errorf("list trades: %w", err)
+		}
+
+		for _, t := range data {
+			side := order.SideSell
+			if t.IsBuyer {
+				side = order.SideBuy
+			}
+
+			fills = append(fills, Fill{
+				ID:       strconv.FormatInt(t.ID, 10),
+				OrderID:  strconv.FormatInt(t.OrderID, 10),
+				Pair:     p,
+				Side:     side,
+				Price:    t.Price,
+				Size:     t.Qty,
+				Fee:      t.Commission,
+				FeeAsset: trading.Asset(t.CommissionAsset),
+				Time:     BinanceTime(t.Time),
+			})
+		}
+
+		if len(data) < binanceTradesLimit {
+			return fills, nil
+		}
+
+		// The start time cannot be sent along with a trade ID.
+		params.Del("startTime")
+		params.Set("fromId", strconv.FormatInt(data[len(data)-1].ID+1, 10))
+	}
+}
+
+// GetBalance returns the free balance of the asset. Funds that are locked in
+// open orders are not included.
+func (e *Binance) GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error) {
+	type accountResponse struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+
+	var data accountResponse
+
+	if err := e.doSigned(ctx, http.MethodGet, "/api/v3/account", nil, &data); err != nil {
+		return trading.Amount{}, fmt.Errorf("get account: %w", err)
+	}
+
+	for _, b := range data.Balances {
+		if b.Asset != string(asset) {
+			continue
+		}
+
+		balance, err := trading.ParseAmount(b.Free)
+		if err != nil {
+			return trading.Amount{}, fmt.Errorf("parse balance: %w", err)
+		}
+
+		return balance, nil
+	}
+
+	return trading.Amount{}, nil
+}
+
+// CreateListenKey starts a user data stream on binance, returning the listen
+// key that the websocket streams of the user data are subscribed to with. The
+// key expires after an hour unless it is kept alive.
+func (e *Binance) CreateListenKey(ctx context.Context) (string, error) {
+	type listenKeyResponse struct {
+		ListenKey string `json:"listenKey"`
+	}
+
+	var data listenKeyResponse
+
+	if err := e.doUserStream(ctx, http.MethodPost, "", &data); err != nil {
+		return "", fmt.Errorf("create listen key: %w", err)
+	}
+
+	return data.ListenKey, nil
+}
+
+// KeepAliveListenKey extends the life of the listen key by an hour. Binance
+// recommends that this is done every 30 minutes.
+func (e *Binance) KeepAliveListenKey(ctx context.Context, listenKey string) error {
+	if err := e.doUserStream(ctx, http.MethodPut, listenKey, nil); err != nil {
+		return fmt.Errorf("keep alive listen key: %w", err)
+	}
+
+	return nil
+}
+
+// CloseListenKey ends the user data stream of the listen key.
+func (e *Binance) CloseListenKey(ctx context.Context, listenKey string) error {
+	if err := e.doUserStream(ctx, http.MethodDelete, listenKey, nil); err != nil {
+		return fmt.Errorf("close listen key: %w", err)
+	}
+
+	return nil
+}
+
+// doUserStream performs a request against the user data stream endpoint,
+// which only needs the api key rather than a signature.
+func (e *Binance) doUserStream(ctx context.Context, method string, listenKey string, v any) error {
+	endpoint := e.BaseURL + "/api/v3/userDataStream"
+
+	if listenKey != "" {
+		endpoint += "?" + url.Values{"listenKey": []string{listenKey}}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("create new request: %w", err)
+	}
+
+	req.Header.Add("X-MBX-APIKEY", e.APIKey)
+
+	return e.do(req, v)
 }
 
 // BinanceDomain is an enum type that is used to specify which domain the
@@ -108,19 +1124,3 @@ func (d BinanceDomain) baseURL() string {
 		return ""
 	}
 }
-
-func (e *Binance) LimitOrder(ctx context.Context, order order.Limit) (Order, error) {
-	return Order{}, nil
-}
-
-func (e *Binance) CancelOrders(ctx context.Context, orderID ...string) error {
-	return nil
-}
-
-func (e *Binance) ListOpenOrders(ctx context.Context) ([]Order, error) {
-	return nil, nil
-}
-
-func (e *Binance) GetBalance(ctx context.Context) (int64, error) {
-	return 0, nil
-}
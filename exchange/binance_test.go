@@ -1,31 +1,514 @@
 package exchange_test
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
 
 func TestBinanceConstructor(t *testing.T) {
+	type want struct {
+		baseURL string
+		err     error
+	}
+
 	testCases := []struct {
 		name  string
 		input exchange.BinanceDomain
-		wants exchange.Binance
+		setup func()
+		wants want
 	}{
 		{
 			name:  "testing that the BinanceDomainUS has the correct baseURL",
 			input: exchange.BinanceDomainUS,
-			wants: exchange.Binance{
-				BaseURL: "https://api.binance.us",
+			setup: func() {
+				os.Setenv("BINANCE_API_KEY", "FOO")
+				os.Setenv("BINANCE_API_SECRET", "BAR")
+			},
+			wants: want{
+				baseURL: "https://api.binance.us",
 			},
 		},
 		{
 			name:  "testing that the BinanceDomainDotCom has the correct baseURL",
 			input: exchange.BinanceDomainDotCom,
-			wants: exchange.Binance{
-				BaseURL: "https://api.binance.com",
+			setup: func() {
+				os.Setenv("BINANCE_API_KEY", "FOO")
+				os.Setenv("BINANCE_API_SECRET", "BAR")
+			},
+			wants: want{
+				baseURL: "https://api.binance.com",
+			},
+		},
+		{
+			name:  "testing with missing api key env var",
+			input: exchange.BinanceDomainUS,
+			setup: func() {
+				os.Unsetenv("BINANCE_API_KEY")
+				os.Setenv("BINANCE_API_SECRET", "BAR")
+			},
+			wants: want{
+				err: exchange.ErrAPIKeyNotSet,
+			},
+		},
+		{
+			name:  "testing with missing api secret env var",
+			input: exchange.BinanceDomainUS,
+			setup: func() {
+				os.Setenv("BINANCE_API_KEY", "FOO")
+				os.Unsetenv("BINANCE_API_SECRET")
+			},
+			wants: want{
+				err: exchange.ErrAPISecretNotSet,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			res, err := exchange.NewBinance(tt.input)
+
+			assert.ErrorIs(t, err, tt.wants.err)
+
+			if tt.wants.err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wants.baseURL, res.BaseURL)
+			assert.Equal(t, "FOO", res.APIKey)
+			assert.Equal(t, "BAR", res.APISecret)
+		})
+	}
+}
+
+// newBinanceServer creates a test server that checks the signature of every
+// signed request before passing it to the handler.
+func newBinanceServer(t *testing.T, handler http.HandlerFunc) *exchange.Binance {
+	t.Helper()
+
+	const (
+		key    = "key"
+		secret = "secret"
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/time", "/api/v3/ticker/price", "/api/v3/exchangeInfo", "/api/v3/depth", "/api/v3/klines":
+			// public endpoints are not signed
+		case "/api/v3/userDataStream":
+			// user data stream endpoints only need the api key
+			assert.Equal(t, key, r.Header.Get("X-MBX-APIKEY"))
+		default:
+			query, sig, found := strings.Cut(r.URL.RawQuery, "&signature=")
+			if !found {
+				t.Errorf("request to %s is not signed", r.URL.Path)
+			}
+
+			hash := hmac.New(sha256.New, []byte(secret))
+			hash.Write([]byte(query))
+
+			assert.Equal(t, hex.EncodeToString(hash.Sum(nil)), sig)
+			assert.Equal(t, key, r.Header.Get("X-MBX-APIKEY"))
+			assert.NotEmpty(t, r.URL.Query().Get("timestamp"))
+			assert.Equal(t, "5000", r.URL.Query().Get("recvWindow"))
+		}
+
+		handler(w, r)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	os.Setenv("BINANCE_API_KEY", key)
+	os.Setenv("BINANCE_API_SECRET", secret)
+
+	e, err := exchange.NewBinance(
+		exchange.BinanceDomainUS, exchange.WithBaseURL(srv.URL), exchange.WithHTTPClient(srv.Client()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestBinanceCreateLimitOrder(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input order.Limit
+		query map[string]string
+	}{
+		{
+			name: "limit order is placed as good till cancelled",
+			input: order.Limit{
+				ClientID: "foobar",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("0.01"),
+				Price:    trading.MustParseAmount("500"),
+			},
+			query: map[string]string{
+				"symbol":           "BTCUSD",
+				"side":             "BUY",
+				"type":             "LIMIT",
+				"timeInForce":      "GTC",
+				"quantity":         "0.01",
+				"price":            "500",
+				"newClientOrderId": "foobar",
+			},
+		},
+		{
+			name: "post only order is placed as limit maker",
+			input: order.Limit{
+				ClientID: "foobar",
+				Pair:     trading.ETHUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("1.5"),
+				Price:    trading.MustParseAmount("2000.10"),
+				PostOnly: true,
+			},
+			query: map[string]string{
+				"symbol":           "ETHUSD",
+				"side":             "SELL",
+				"type":             "LIMIT_MAKER",
+				"timeInForce":      "",
+				"quantity":         "1.5",
+				"price":            "2000.1",
+				"newClientOrderId": "foobar",
+			},
+		},
+		{
+			name: "immediate or cancel order",
+			input: order.Limit{
+				ClientID:    "foobar",
+				Pair:        trading.BTCUSD,
+				Side:        order.SideBuy,
+				BaseSize:    trading.MustParseAmount("0.01"),
+				Price:       trading.MustParseAmount("500"),
+				TimeInForce: order.TimeInForceIOC,
+			},
+			query: map[string]string{
+				"type":        "LIMIT",
+				"timeInForce": "IOC",
+			},
+		},
+		{
+			name: "fill or kill order",
+			input: order.Limit{
+				ClientID:    "foobar",
+				Pair:        trading.BTCUSD,
+				Side:        order.SideBuy,
+				BaseSize:    trading.MustParseAmount("0.01"),
+				Price:       trading.MustParseAmount("500"),
+				TimeInForce: order.TimeInForceFOK,
+			},
+			query: map[string]string{
+				"type":        "LIMIT",
+				"timeInForce": "FOK",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/v3/order", r.URL.Path)
+
+				for k, v := range tt.query {
+					assert.Equal(t, v, r.URL.Query().Get(k), k)
+				}
+
+				q := r.URL.Query()
+
+				fmt.Fprintf(w, `{"symbol":%q,"orderId":28,"clientOrderId":%q,"side":%q,"price":%q,"origQty":%q}`,
+					q.Get("symbol"), q.Get("newClientOrderId"), q.Get("side"), q.Get("price"), q.Get("quantity"))
+			})
+
+			res, err := e.CreateLimitOrder(context.Background(), tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, exchange.Order{
+				ID:       "28",
+				Pair:     tt.input.Pair,
+				Side:     tt.input.Side,
+				ClientID: "foobar",
+				BaseSize: tt.input.BaseSize,
+				Price:    tt.input.Price,
+			}, res)
+		})
+	}
+}
+
+func TestBinanceCreateLimitOrderUnsupported(t *testing.T) {
+	expires := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	_, err := e.CreateLimitOrder(context.Background(), order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("500"),
+		Expires:  &expires,
+	})
+
+	assert.ErrorIs(t, err, exchange.ErrUnsupportedOrder)
+
+	_, err = e.CreateLimitOrder(context.Background(), order.Limit{
+		Pair:        trading.BTCUSD,
+		Side:        order.SideBuy,
+		BaseSize:    trading.MustParseAmount("0.01"),
+		Price:       trading.MustParseAmount("500"),
+		PostOnly:    true,
+		TimeInForce: order.TimeInForceIOC,
+	})
+
+	assert.ErrorIs(t, err, exchange.ErrInvalidOrder)
+}
+
+func TestBinanceCreateMarketOrder(t *testing.T) {
+	testCases := []struct {
+		name    string
+		markets *trading.Registry
+		input   order.Market
+		query   map[string]string
+	}{
+		{
+			name: "market order by base size",
+			input: order.Market{
+				ClientID: "foobar",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.01"),
+			},
+			query: map[string]string{
+				"symbol":           "BTCUSD",
+				"side":             "SELL",
+				"type":             "MARKET",
+				"quantity":         "0.01",
+				"quoteOrderQty":    "",
+				"newClientOrderId": "foobar",
+			},
+		},
+		{
+			name: "market order by quote size",
+			input: order.Market{
+				ClientID:  "foobar",
+				Pair:      trading.ETHUSD,
+				Side:      order.SideBuy,
+				QuoteSize: trading.MustParseAmount("25.50"),
+			},
+			query: map[string]string{
+				"symbol":           "ETHUSD",
+				"side":             "BUY",
+				"type":             "MARKET",
+				"quantity":         "",
+				"quoteOrderQty":    "25.5",
+				"newClientOrderId": "foobar",
+			},
+		},
+		{
+			name: "order with a price limit is placed as immediate or cancel",
+			input: order.Market{
+				ClientID:   "foobar",
+				Pair:       trading.BTCUSD,
+				Side:       order.SideSell,
+				BaseSize:   trading.MustParseAmount("0.01"),
+				PriceLimit: trading.MustParseAmount("16500"),
+			},
+			query: map[string]string{
+				"symbol":      "BTCUSD",
+				"side":        "SELL",
+				"type":        "LIMIT",
+				"timeInForce": "IOC",
+				"quantity":    "0.01",
+				"price":       "16500",
+			},
+		},
+		{
+			name: "quote size with a price limit is converted to the lot size",
+			markets: trading.NewRegistry(trading.Market{
+				Pair:    trading.BTCUSD,
+				Symbol:  "BTCUSD",
+				LotSize: trading.MustParseAmount("0.0001"),
+			}),
+			input: order.Market{
+				ClientID:   "foobar",
+				Pair:       trading.BTCUSD,
+				Side:       order.SideBuy,
+				QuoteSize:  trading.MustParseAmount("100"),
+				PriceLimit: trading.MustParseAmount("17500"),
+			},
+			query: map[string]string{
+				"type":          "LIMIT",
+				"timeInForce":   "IOC",
+				"quantity":      "0.0057",
+				"quoteOrderQty": "",
+				"price":         "17500",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/v3/order", r.URL.Path)
+
+				for k, v := range tt.query {
+					assert.Equal(t, v, r.URL.Query().Get(k), k)
+				}
+
+				q := r.URL.Query()
+
+				fmt.Fprintf(w, `{"symbol":%q,"orderId":28,"clientOrderId":%q,"side":%q}`,
+					q.Get("symbol"), q.Get("newClientOrderId"), q.Get("side"))
+			})
+
+			if tt.markets != nil {
+				for _, m := range tt.markets.Markets() {
+					e.Markets().Add(m)
+				}
+			}
+
+			res, err := e.CreateMarketOrder(context.Background(), tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, exchange.Order{
+				ID:       "28",
+				Pair:     tt.input.Pair,
+				Side:     tt.input.Side,
+				ClientID: "foobar",
+			}, res)
+		})
+	}
+}
+
+func TestBinanceCreateMarketOrderInvalid(t *testing.T) {
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	_, err := e.CreateMarketOrder(context.Background(), order.Market{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideBuy,
+		BaseSize:  trading.MustParseAmount("1"),
+		QuoteSize: trading.MustParseAmount("100"),
+	})
+
+	assert.ErrorIs(t, err, exchange.ErrInvalidOrder)
+}
+
+func TestBinanceCreateStopOrder(t *testing.T) {
+	testCases := []struct {
+		name   string
+		create func(e *exchange.Binance) (exchange.Order, error)
+		query  map[string]string
+	}{
+		{
+			name: "stop loss limit for a sell below the stop price",
+			create: func(e *exchange.Binance) (exchange.Order, error) {
+				return e.CreateStopLimitOrder(context.Background(), order.StopLimit{
+					ClientID:  "foobar",
+					Pair:      trading.BTCUSD,
+					Side:      order.SideSell,
+					BaseSize:  trading.MustParseAmount("0.01"),
+					Price:     trading.MustParseAmount("15900"),
+					StopPrice: trading.MustParseAmount("16000"),
+					Direction: order.DirectionBelow,
+				})
+			},
+			query: map[string]string{
+				"symbol":           "BTCUSD",
+				"side":             "SELL",
+				"type":             "STOP_LOSS_LIMIT",
+				"timeInForce":      "GTC",
+				"quantity":         "0.01",
+				"price":            "15900",
+				"stopPrice":        "16000",
+				"newClientOrderId": "foobar",
+			},
+		},
+		{
+			name: "take profit limit for a sell above the stop price",
+			create: func(e *exchange.Binance) (exchange.Order, error) {
+				return e.CreateStopLimitOrder(context.Background(), order.StopLimit{
+					ClientID:  "foobar",
+					Pair:      trading.BTCUSD,
+					Side:      order.SideSell,
+					BaseSize:  trading.MustParseAmount("0.01"),
+					Price:     trading.MustParseAmount("20000"),
+					StopPrice: trading.MustParseAmount("20000"),
+					Direction: order.DirectionAbove,
+				})
+			},
+			query: map[string]string{
+				"type":      "TAKE_PROFIT_LIMIT",
+				"stopPrice": "20000",
+			},
+		},
+		{
+			name: "stop loss for a buy above the stop price",
+			create: func(e *exchange.Binance) (exchange.Order, error) {
+				return e.CreateStopMarketOrder(context.Background(), order.StopMarket{
+					ClientID:  "foobar",
+					Pair:      trading.ETHUSD,
+					Side:      order.SideBuy,
+					BaseSize:  trading.MustParseAmount("1.5"),
+					StopPrice: trading.MustParseAmount("2000"),
+					Direction: order.DirectionAbove,
+				})
+			},
+			query: map[string]string{
+				"symbol":      "ETHUSD",
+				"side":        "BUY",
+				"type":        "STOP_LOSS",
+				"timeInForce": "",
+				"quantity":    "1.5",
+				"price":       "",
+				"stopPrice":   "2000",
+			},
+		},
+		{
+			name: "take profit for a buy below the stop price",
+			create: func(e *exchange.Binance) (exchange.Order, error) {
+				return e.CreateStopMarketOrder(context.Background(), order.StopMarket{
+					ClientID:  "foobar",
+					Pair:      trading.ETHUSD,
+					Side:      order.SideBuy,
+					BaseSize:  trading.MustParseAmount("1.5"),
+					StopPrice: trading.MustParseAmount("1000"),
+					Direction: order.DirectionBelow,
+				})
+			},
+			query: map[string]string{
+				"type": "TAKE_PROFIT",
 			},
 		},
 	}
@@ -34,9 +517,630 @@ func TestBinanceConstructor(t *testing.T) {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-			res := exchange.NewBinance(tt.input)
+			e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/v3/order", r.URL.Path)
+
+				for k, v := range tt.query {
+					assert.Equal(t, v, r.URL.Query().Get(k), k)
+				}
+
+				q := r.URL.Query()
+
+				fmt.Fprintf(w, `{"symbol":%q,"orderId":28,"clientOrderId":%q,"side":%q}`,
+					q.Get("symbol"), q.Get("newClientOrderId"), q.Get("side"))
+			})
+
+			res, err := tt.create(e)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "28", res.ID)
+			assert.Equal(t, "foobar", res.ClientID)
+		})
+	}
+}
+
+func TestBinanceCreateOCOOrder(t *testing.T) {
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v3/order/oco", r.URL.Path)
+
+		for k, v := range map[string]string{
+			"symbol":               "BTCUSD",
+			"side":                 "SELL",
+			"quantity":             "0.5",
+			"price":                "20000",
+			"stopPrice":            "16000",
+			"stopLimitPrice":       "15900",
+			"stopLimitTimeInForce": "GTC",
+			"listClientOrderId":    "foobar",
+			"limitClientOrderId":   "foobar-tp",
+			"stopClientOrderId":    "foobar-sl",
+		} {
+			assert.Equal(t, v, r.URL.Query().Get(k), k)
+		}
+
+		fmt.Fprint(w, `{
+			"orderListId": 7,
+			"listClientOrderId": "foobar",
+			"orderReports": [
+				{"symbol":"BTCUSD","orderId":28,"clientOrderId":"foobar-sl","side":"SELL","price":"15900","origQty":"0.5"},
+				{"symbol":"BTCUSD","orderId":29,"clientOrderId":"foobar-tp","side":"SELL","price":"20000","origQty":"0.5"}
+			]
+		}`)
+	})
+
+	res, err := e.CreateOCOOrder(context.Background(), order.OCO{
+		ClientID:       "foobar",
+		Pair:           trading.BTCUSD,
+		Side:           order.SideSell,
+		BaseSize:       trading.MustParseAmount("0.5"),
+		TakeProfit:     trading.MustParseAmount("20000"),
+		StopPrice:      trading.MustParseAmount("16000"),
+		StopLimitPrice: trading.MustParseAmount("15900"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderList{
+		ID:       "7",
+		ClientID: "foobar",
+		Orders: []exchange.Order{
+			{
+				ID:       "28",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				ClientID: "foobar-sl",
+				BaseSize: trading.MustParseAmount("0.5"),
+				Price:    trading.MustParseAmount("15900"),
+			},
+			{
+				ID:       "29",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				ClientID: "foobar-tp",
+				BaseSize: trading.MustParseAmount("0.5"),
+				Price:    trading.MustParseAmount("20000"),
+			},
+		},
+	}, res)
+}
+
+func TestBinanceGetOrder(t *testing.T) {
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v3/order", r.URL.Path)
+		assert.Equal(t, "BTCUSD", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "28", r.URL.Query().Get("orderId"))
+
+		fmt.Fprint(w, `{
+			"symbol": "BTCUSD",
+			"orderId": 28,
+			"clientOrderId": "foobar",
+			"price": "17000.00",
+			"origQty": "0.5",
+			"executedQty": "0.3",
+			"cummulativeQuoteQty": "5099.70",
+			"status": "PARTIALLY_FILLED",
+			"side": "BUY",
+			"time": 1672628645000,
+			"updateTime": 1672628705000
+		}`)
+	})
+
+	res, err := e.GetOrder(context.Background(), trading.BTCUSD, "28")
+
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.Order{
+		ID:           "28",
+		Pair:         trading.BTCUSD,
+		Side:         order.SideBuy,
+		ClientID:     "foobar",
+		BaseSize:     trading.MustParseAmount("0.5"),
+		Price:        trading.MustParseAmount("17000"),
+		Status:       order.StatusPartiallyFilled,
+		FilledSize:   trading.MustParseAmount("0.3"),
+		AveragePrice: trading.MustParseAmount("16999"),
+		CreatedAt:    time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt:    time.Date(2023, 1, 2, 3, 5, 5, 0, time.UTC),
+	}, res)
+}
+
+func TestBinanceOrderStatus(t *testing.T) {
+	testCases := []struct {
+		status      string
+		executedQty string
+		expected    order.Status
+	}{
+		{status: "NEW", executedQty: "0", expected: order.StatusNew},
+		{status: "PENDING_CANCEL", executedQty: "0.1", expected: order.StatusPartiallyFilled},
+		{status: "FILLED", executedQty: "0.5", expected: order.StatusFilled},
+		{status: "CANCELED", executedQty: "0.1", expected: order.StatusCancelled},
+		{status: "REJECTED", executedQty: "0", expected: order.StatusRejected},
+		{status: "EXPIRED_IN_MATCH", executedQty: "0", expected: order.StatusExpired},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.status, func(t *testing.T) {
+			e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"symbol":"BTCUSD","orderId":28,"status":%q,"executedQty":%q}`, tt.status, tt.executedQty)
+			})
+
+			res, err := e.GetOrder(context.Background(), trading.BTCUSD, "28")
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, res.Status)
+		})
+	}
+}
+
+func TestBinanceListFills(t *testing.T) {
+	since := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/myTrades", r.URL.Path)
+		assert.Equal(t, "BTCUSD", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+
+		trades := make([]string, 0)
+
+		switch r.URL.Query().Get("fromId") {
+		case "":
+			assert.Equal(t, "1672628645000", r.URL.Query().Get("startTime"))
+
+			// A full page means that there may be more trades.
+			for id := 1; id <= 1000; id++ {
+				trades = append(trades, fmt.Sprintf(`{
+					"id": %d, "orderId": 28, "price": "17000.00", "qty": "0.001",
+					"commission": "0.000001", "commissionAsset": "BTC", "time": 1672628645000, "isBuyer": true
+				}`, id))
+			}
+		case "1001":
+			assert.Empty(t, r.URL.Query().Get("startTime"))
+
+			trades = append(trades, `{
+				"id": 1001, "orderId": 29, "price": "18000.00", "qty": "0.5",
+				"commission": "9.00", "commissionAsset": "USD", "time": 1672628705000, "isBuyer": false
+			}`)
+		}
+
+		fmt.Fprintf(w, "[%s]", strings.Join(trades, ","))
+	})
+
+	res, err := e.ListFills(context.Background(), trading.BTCUSD, since)
+
+	assert.NoError(t, err)
+	assert.Len(t, res, 1001)
+	assert.Equal(t, exchange.Fill{
+		ID:       "1",
+		OrderID:  "28",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		Price:    trading.MustParseAmount("17000"),
+		Size:     trading.MustParseAmount("0.001"),
+		Fee:      trading.MustParseAmount("0.000001"),
+		FeeAsset: trading.BTC,
+		Time:     since,
+	}, res[0])
+	assert.Equal(t, exchange.Fill{
+		ID:       "1001",
+		OrderID:  "29",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideSell,
+		Price:    trading.MustParseAmount("18000"),
+		Size:     trading.MustParseAmount("0.5"),
+		Fee:      trading.MustParseAmount("9"),
+		FeeAsset: trading.USD,
+		Time:     since.Add(time.Minute),
+	}, res[1000])
+}
+
+func TestBinanceListAllFills(t *testing.T) {
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "0", r.URL.Query().Get("fromId"))
+		assert.Empty(t, r.URL.Query().Get("startTime"))
+
+		fmt.Fprint(w, `[]`)
+	})
+
+	res, err := e.ListFills(context.Background(), trading.BTCUSD, time.Time{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func TestBinanceCancelOrders(t *testing.T) {
+	cancelled := make([]string, 0)
+
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/openOrders":
+			fmt.Fprint(w, `[
+				{"symbol":"BTCUSD","orderId":1,"clientOrderId":"a","side":"BUY"},
+				{"symbol":"ETHUSD","orderId":2,"clientOrderId":"b","side":"SELL"}
+			]`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v3/order":
+			cancelled = append(cancelled, r.URL.Query().Get("symbol")+":"+r.URL.Query().Get("orderId"))
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	err := e.CancelOrders(context.Background(), "2", "3", "1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ETHUSD:2", "BTCUSD:1"}, cancelled)
+}
+
+func TestBinanceListOpenOrders(t *testing.T) {
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/openOrders", r.URL.Path)
+
+		fmt.Fprint(w, `[
+			{"symbol":"BTCUSD","orderId":1,"clientOrderId":"a","side":"BUY","price":"0.10000000","origQty":"1.00000000"},
+			{"symbol":"SOLUSD","orderId":2,"clientOrderId":"b","side":"SELL"}
+		]`)
+	})
+
+	res, err := e.ListOpenOrders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []exchange.Order{
+		{
+			ID:       "1",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideBuy,
+			ClientID: "a",
+			BaseSize: trading.MustParseAmount("1"),
+			Price:    trading.MustParseAmount("0.1"),
+		},
+		{ID: "2", Side: order.SideSell, ClientID: "b"},
+	}, res)
+}
+
+func TestBinanceGetBalance(t *testing.T) {
+	testCases := []struct {
+		name     string
+		asset    trading.Asset
+		expected trading.Amount
+	}{
+		{
+			name:     "free balance of held asset",
+			asset:    trading.USD,
+			expected: trading.MustParseAmount("50.01"),
+		},
+		{
+			name:     "balance beyond the range of an int64 in units",
+			asset:    trading.ETH,
+			expected: trading.MustParseAmount("12345.678901234567890123"),
+		},
+		{
+			name:  "asset that is not held",
+			asset: trading.Asset("SOL"),
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v3/account", r.URL.Path)
+
+				fmt.Fprint(w, `{"balances":[
+					{"asset":"BTC","free":"0.01","locked":"0.00"},
+					{"asset":"USD","free":"50.01","locked":"10.00"},
+					{"asset":"ETH","free":"12345.678901234567890123","locked":"0.00"}
+				]}`)
+			})
+
+			res, err := e.GetBalance(context.Background(), tt.asset)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestBinanceGetOrderBook(t *testing.T) {
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v3/depth", r.URL.Path)
+		assert.Equal(t, "BTCUSD", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "100", r.URL.Query().Get("limit"))
+
+		fmt.Fprint(w, `{
+			"lastUpdateId": 1027024,
+			"bids": [["21921.73", "0.06317902"], ["21920.00", "1.5"]],
+			"asks": [["21921.74", "0.5"]]
+		}`)
+	})
+
+	book, err := e.GetOrderBook(context.Background(), trading.BTCUSD, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderBook{
+		Pair:         trading.BTCUSD,
+		LastUpdateID: 1027024,
+		Bids: []exchange.Level{
+			{Price: trading.MustParseAmount("21921.73"), Size: trading.MustParseAmount("0.06317902")},
+			{Price: trading.MustParseAmount("21920.00"), Size: trading.MustParseAmount("1.5")},
+		},
+		Asks: []exchange.Level{
+			{Price: trading.MustParseAmount("21921.74"), Size: trading.MustParseAmount("0.5")},
+		},
+	}, book)
+}
+
+func TestBinanceGetCandles(t *testing.T) {
+	start := time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/klines", r.URL.Path)
+		assert.Equal(t, "BTCUSD", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "1h", r.URL.Query().Get("interval"))
+		assert.Equal(t, strconv.FormatInt(start.UnixMilli(), 10), r.URL.Query().Get("startTime"))
+		assert.Equal(t, strconv.FormatInt(start.Add(2*time.Hour).UnixMilli()-1, 10), r.URL.Query().Get("endTime"))
+
+		fmt.Fprintf(w, `[
+			[%d, "100.0", "110.0", "95.0", "105.0", "12.5", 0, "0", 10, "0", "0", "0"],
+			[%d, "105.0", "106.0", "90.0", "91.0", "3", 0, "0", 10, "0", "0", "0"]
+		]`, start.UnixMilli(), start.Add(time.Hour).UnixMilli())
+	})
+
+	candles, err := e.GetCandles(context.Background(), trading.BTCUSD, time.Hour, start, start.Add(2*time.Hour))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []exchange.Candle{
+		{
+			Start:  start,
+			Open:   trading.MustParseAmount("100.0"),
+			High:   trading.MustParseAmount("110.0"),
+			Low:    trading.MustParseAmount("95.0"),
+			Close:  trading.MustParseAmount("105.0"),
+			Volume: trading.MustParseAmount("12.5"),
+		},
+		{
+			Start:  start.Add(time.Hour),
+			Open:   trading.MustParseAmount("105.0"),
+			High:   trading.MustParseAmount("106.0"),
+			Low:    trading.MustParseAmount("90.0"),
+			Close:  trading.MustParseAmount("91.0"),
+			Volume: trading.MustParseAmount("3"),
+		},
+	}, candles)
+
+	_, err = e.GetCandles(context.Background(), trading.BTCUSD, 7*time.Minute, start, start.Add(time.Hour))
+	assert.ErrorIs(t, err, exchange.ErrUnsupportedInterval)
+}
+
+func TestBinanceListenKey(t *testing.T) {
+	var methods []string
+
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/userDataStream", r.URL.Path)
+		assert.Empty(t, r.URL.Query().Get("signature"))
+
+		methods = append(methods, r.Method)
+
+		if r.Method == http.MethodPost {
+			assert.Empty(t, r.URL.Query().Get("listenKey"))
+			fmt.Fprint(w, `{"listenKey":"pqia91ma19a5s61cv6a81va65sdf19v8a65a1a5s61cv6a81va65sdf19v8a65a1"}`)
+
+			return
+		}
+
+		assert.Equal(t, "pqia91ma19a5s61cv6a81va65sdf19v8a65a1a5s61cv6a81va65sdf19v8a65a1", r.URL.Query().Get("listenKey"))
+		fmt.Fprint(w, `{}`)
+	})
+
+	key, err := e.CreateListenKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "pqia91ma19a5s61cv6a81va65sdf19v8a65a1a5s61cv6a81va65sdf19v8a65a1", key)
+
+	assert.NoError(t, e.KeepAliveListenKey(context.Background(), key))
+	assert.NoError(t, e.CloseListenKey(context.Background(), key))
+
+	assert.Equal(t, []string{http.MethodPost, http.MethodPut, http.MethodDelete}, methods)
+}
+
+func TestBinanceLoadMarkets(t *testing.T) {
+	var symbols []string
+
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/exchangeInfo":
+			fmt.Fprint(w, `{"symbols":[
+				{
+					"symbol":"SOLUSDT","status":"TRADING",
+					"baseAsset":"SOL","baseAssetPrecision":8,"quoteAsset":"USDT","quoteAssetPrecision":8,
+					"filters":[
+						{"filterType":"PRICE_FILTER","minPrice":"0.01","maxPrice":"10000.00","tickSize":"0.01"},
+						{"filterType":"LOT_SIZE","minQty":"0.001","maxQty":"9000.00","stepSize":"0.001"},
+						{"filterType":"NOTIONAL","minNotional":"5.00","maxNotional":"9000000.00"}
+					]
+				},
+				{
+					"symbol":"ETHUSD","status":"TRADING",
+					"baseAsset":"ETH","baseAssetPrecision":8,"quoteAsset":"USD","quoteAssetPrecision":4,
+					"filters":[{"filterType":"MIN_NOTIONAL","minNotional":"10.0000"}]
+				},
+				{
+					"symbol":"LUNAUSD","status":"BREAK",
+					"baseAsset":"LUNA","baseAssetPrecision":8,"quoteAsset":"USD","quoteAssetPrecision":4
+				}
+			]}`)
+		case "/api/v3/order":
+			symbols = append(symbols, r.URL.Query().Get("symbol"))
+			fmt.Fprint(w, `{"orderId":1}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	assert.NoError(t, e.LoadMarkets(context.Background()))
+
+	solusdt := trading.Pair{Base: "SOL", Quote: "USDT"}
+
+	assert.Equal(t, []trading.Market{
+		{Pair: trading.BTCUSD, Symbol: "BTCUSD"},
+		{Pair: trading.ETHUSD, Symbol: "ETHUSD", MinNotional: trading.MustParseAmount("10")},
+		{
+			Pair:        solusdt,
+			Symbol:      "SOLUSDT",
+			TickSize:    trading.MustParseAmount("0.01"),
+			LotSize:     trading.MustParseAmount("0.001"),
+			MinSize:     trading.MustParseAmount("0.001"),
+			MinNotional: trading.MustParseAmount("5"),
+		},
+	}, e.Markets().Markets())
+
+	assert.Equal(t, 8, solusdt.Quote.Decimals())
+	assert.Equal(t, 18, trading.ETH.Decimals(), "known assets should keep their decimal places")
+	assert.False(t, trading.Asset("LUNA").Known())
+
+	_, err := e.CreateLimitOrder(context.Background(), order.Limit{
+		Pair:     solusdt,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("1"),
+		Price:    trading.MustParseAmount("20"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SOLUSDT"}, symbols)
+}
+
+func TestBinanceWithMarkets(t *testing.T) {
+	os.Setenv("BINANCE_API_KEY", "FOO")
+	os.Setenv("BINANCE_API_SECRET", "BAR")
+
+	markets := trading.NewRegistry(trading.Market{Pair: trading.Pair{Base: "SOL", Quote: "USDT"}, Symbol: "SOLUSDT"})
+
+	e, err := exchange.NewBinance(exchange.BinanceDomainDotCom, exchange.WithMarkets(markets))
+
+	assert.NoError(t, err)
+	assert.Same(t, markets, e.Markets())
+
+	_, err = e.CreateLimitOrder(context.Background(), order.Limit{Pair: trading.BTCUSD})
+
+	assert.ErrorIs(t, err, exchange.ErrMissingPair)
+}
+
+func TestBinanceSyncTime(t *testing.T) {
+	var timestamps []string
+
+	e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/time":
+			fmt.Fprint(w, `{"serverTime":1000}`)
+		default:
+			timestamps = append(timestamps, r.URL.Query().Get("timestamp"))
+			fmt.Fprint(w, `[]`)
+		}
+	})
+
+	assert.NoError(t, e.SyncTime(context.Background()))
+
+	_, err := e.ListOpenOrders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, timestamps, 1)
+	assert.Len(t, timestamps[0], 4, "timestamp should be close to the server time")
+}
+
+func TestBinanceErrors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		status     int
+		header     map[string]string
+		body       string
+		wants      error
+		retryAfter time.Duration
+	}{
+		{
+			name:       "rate limited",
+			status:     http.StatusTooManyRequests,
+			header:     map[string]string{"Retry-After": "7"},
+			body:       `{"code":-1003,"msg":"Too many requests."}`,
+			wants:      exchange.ErrRateLimited,
+			retryAfter: time.Second * 7,
+		},
+		{
+			name:   "banned after being rate limited",
+			status: http.StatusTeapot,
+			body:   `{"code":-1003,"msg":"Way too many requests; IP banned."}`,
+			wants:  exchange.ErrRateLimited,
+		},
+		{
+			name:   "insufficient funds",
+			status: http.StatusBadRequest,
+			body:   `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`,
+			wants:  exchange.ErrInsufficientFunds,
+		},
+		{
+			name:   "order rejected by filter",
+			status: http.StatusBadRequest,
+			body:   `{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`,
+			wants:  exchange.ErrInvalidOrder,
+		},
+		{
+			name:   "bad parameter",
+			status: http.StatusBadRequest,
+			body:   `{"code":-1111,"msg":"Precision is over the maximum defined for this asset."}`,
+			wants:  exchange.ErrInvalidOrder,
+		},
+		{
+			name:   "bad signature",
+			status: http.StatusBadRequest,
+			body:   `{"code":-1022,"msg":"Signature for this request is not valid."}`,
+			wants:  exchange.ErrAuthFailed,
+		},
+		{
+			name:   "unknown order",
+			status: http.StatusBadRequest,
+			body:   `{"code":-2013,"msg":"Order does not exist."}`,
+			wants:  exchange.ErrUnknownOrder,
+		},
+		{
+			name:   "maintenance",
+			status: http.StatusServiceUnavailable,
+			body:   `<html>down for maintenance</html>`,
+			wants:  exchange.ErrMaintenance,
+		},
+		{
+			name:   "bad domain",
+			status: http.StatusUnavailableForLegalReasons,
+			wants:  exchange.ErrBadBinanceDomain,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newBinanceServer(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			_, err := e.GetLastPrice(context.Background(), trading.BTCUSD)
+			assert.ErrorIs(t, err, tt.wants)
+
+			_, err = e.ListOpenOrders(context.Background())
+			assert.ErrorIs(t, err, tt.wants)
+
+			var apiErr *exchange.APIError
 
-			assert.Equal(t, tt.wants, *res)
+			if errors.As(err, &apiErr) {
+				assert.Equal(t, tt.status, apiErr.StatusCode)
+				assert.Equal(t, tt.retryAfter, apiErr.RetryAfter)
+			}
 		})
 	}
 }
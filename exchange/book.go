@@ -0,0 +1,26 @@
+package exchange
+
+import (
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Level represents the total size of the orders at a price in the order book.
+type Level struct {
+	Price trading.Amount
+	Size  trading.Amount
+}
+
+// OrderBook represents a snapshot of the price levels of the order book of a
+// pair, with the bids from the highest price and the asks from the lowest.
+// LastUpdateID is the ID of the last change to the book that the snapshot
+// holds, on exchanges that number them such as binance, and zero otherwise.
+// Time is zero on exchanges that do not send it.
+type OrderBook struct {
+	Pair         trading.Pair
+	Time         time.Time
+	LastUpdateID int64
+	Bids         []Level
+	Asks         []Level
+}
@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"sort"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Candle represents the open, high, low and close prices and the volume of a
+// pair over an interval of time, starting at Start, as the exchange reports
+// it.
+type Candle struct {
+	Start  time.Time
+	Open   trading.Amount
+	High   trading.Amount
+	Low    trading.Amount
+	Close  trading.Amount
+	Volume trading.Amount
+}
+
+// supportedIntervals returns the intervals of the map from the shortest.
+func supportedIntervals(intervals map[time.Duration]string) []time.Duration {
+	out := make([]time.Duration, 0, len(intervals))
+
+	for d := range intervals {
+		out = append(out, d)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i] < out[j]
+	})
+
+	return out
+}
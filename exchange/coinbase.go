@@ -1,15 +1,19 @@
 package exchange
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"time"
 
@@ -20,16 +24,21 @@ import (
 // Coinbase represents a client that is able to talk to the coinbase advanced
 // trading api.
 type Coinbase struct {
+	BaseURL   string
 	APIKey    string
 	APISecret string
+
+	requester requester
+	markets   *trading.Registry
 }
 
 const coinbaseBaseURL = "https://api.coinbase.com"
 
 // NewCoinbase acts as the default constructor for the Coinbase exchange type.
 // This method will attempt to load authentication credentials from the
-// environment, returning an error if any are missing.
-func NewCoinbase() (*Coinbase, error) {
+// environment, returning an error if any are missing. Options can be passed
+// to override the base URL and the HTTP client.
+func NewCoinbase(opts ...Option) (*Coinbase, error) {
 	key, exists := os.LookupEnv("COINBASE_API_KEY")
 	if !exists {
 		return nil, ErrAPIKeyNotSet
@@ -40,9 +49,14 @@ func NewCoinbase() (*Coinbase, error) {
 		return nil, ErrAPISecretNotSet
 	}
 
+	o := newOptions(coinbaseBaseURL, opts)
+
 	e := &Coinbase{
+		BaseURL:   o.baseURL,
 		APIKey:    key,
 		APISecret: secret,
+		requester: o.requester(),
+		markets:   o.registry(defaultCoinbaseMarkets),
 	}
 
 	return e, nil
@@ -84,18 +98,97 @@ func (e *Coinbase) doRequest(r *http.Request) (*http.Response, error) {
 	r.Header.Add("CB-ACCESS-SIGN", sig)
 	r.Header.Add("CB-ACCESS-TIMESTAMP", strconv.Itoa(int(timestamp)))
 
-	return http.DefaultClient.Do(r)
+	return e.requester.do(r)
+}
+
+// defaultCoinbaseMarkets returns the markets that are supported before any
+// have been loaded with LoadMarkets.
+func defaultCoinbaseMarkets() *trading.Registry {
+	return trading.NewRegistry(
+		trading.Market{Pair: trading.BTCUSD, Symbol: "BTC-USD"},
+		trading.Market{Pair: trading.ETHUSD, Symbol: "ETH-USD"},
+	)
+}
+
+// Markets returns the registry of the markets that the client supports.
+func (e *Coinbase) Markets() *trading.Registry {
+	return e.markets
+}
+
+func (e *Coinbase) convertProductID(productID string) (trading.Pair, error) {
+	m, err := e.markets.MarketBySymbol(productID)
+	if err != nil {
+		return trading.Pair{}, fmt.Errorf("%v: %w", err, ErrMissingPair)
+	}
+
+	return m.Pair, nil
 }
 
 func (e *Coinbase) convertPairValue(p trading.Pair) (string, error) {
-	switch p {
-	case trading.BTCUSD:
-		return "BTC-USD", nil
-	case trading.ETHUSD:
-		return "ETH-USD", nil
-	default:
-		return "", ErrMissingPair
+	m, err := e.markets.Market(p)
+	if err != nil {
+		return "", fmt.Errorf("%v: %w", err, ErrMissingPair)
 	}
+
+	return m.Symbol, nil
+}
+
+// LoadMarkets loads the spot products that are online on coinbase, along
+// with their increments and minimum sizes, adding them to the registry of
+// the client. The decimal places of any asset that is not yet known are taken
+// from the increment of the product.
+func (e *Coinbase) LoadMarkets(ctx context.Context) error {
+	type productsResponse struct {
+		Products []struct {
+			ProductID       string         `json:"product_id"`
+			ProductType     string         `json:"product_type"`
+			Status          string         `json:"status"`
+			TradingDisabled bool           `json:"trading_disabled"`
+			BaseCurrencyID  string         `json:"base_currency_id"`
+			QuoteCurrencyID string         `json:"quote_currency_id"`
+			BaseIncrement   trading.Amount `json:"base_increment"`
+			QuoteIncrement  trading.Amount `json:"quote_increment"`
+			PriceIncrement  trading.Amount `json:"price_increment"`
+			BaseMinSize     trading.Amount `json:"base_min_size"`
+			QuoteMinSize    trading.Amount `json:"quote_min_size"`
+		} `json:"products"`
+	}
+
+	query := url.Values{}
+	query.Set("product_type", "SPOT")
+
+	var data productsResponse
+
+	if err := e.doJSON(ctx, http.MethodGet, "/api/v3/brokerage/products", query, nil, &data); err != nil {
+		return fmt.Errorf("list products: %w", err)
+	}
+
+	for _, p := range data.Products {
+		if p.Status != "online" || p.TradingDisabled {
+			continue
+		}
+
+		tickSize := p.PriceIncrement
+		if tickSize.IsZero() {
+			tickSize = p.QuoteIncrement
+		}
+
+		m := trading.Market{
+			Pair:        trading.Pair{Base: trading.Asset(p.BaseCurrencyID), Quote: trading.Asset(p.QuoteCurrencyID)},
+			Symbol:      p.ProductID,
+			TickSize:    tickSize,
+			LotSize:     p.BaseIncrement,
+			MinSize:     p.BaseMinSize,
+			MinNotional: p.QuoteMinSize,
+		}
+
+		registerUnknownAsset(m.Pair.Base, p.BaseIncrement.Scale())
+		registerUnknownAsset(m.Pair.Quote, p.QuoteIncrement.Scale())
+
+		e.markets.Add(m)
+	}
+
+	return nil
 }
 
 // GetLastPrice obtains the last price for the pair on binance.
@@ -109,39 +202,790 @@ func (e *Coinbase) GetLastPrice(ctx context.Context, p trading.Pair) (string, er
 		return "", err
 	}
 
-	url := fmt.Sprintf("%s/api/v3/brokerage/products/%s", coinbaseBaseURL, pairVal)
+	var response priceResponse
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
+	path := fmt.Sprintf("/api/v3/brokerage/products/%s", pairVal)
+
+	if err := e.doJSON(ctx, http.MethodGet, path, nil, nil, &response); err != nil {
 		return "", err
 	}
 
+	return response.Price, nil
+}
+
+// GetOrderBook obtains a snapshot of the order book of the pair on coinbase,
+// with up to depth price levels on each side.
+func (e *Coinbase) GetOrderBook(ctx context.Context, p trading.Pair, depth int) (OrderBook, error) {
+	type coinbaseLevel struct {
+		Price trading.Amount `json:"price"`
+		Size  trading.Amount `json:"size"`
+	}
+
+	type bookResponse struct {
+		PriceBook struct {
+			Bids []coinbaseLevel `json:"bids"`
+			Asks []coinbaseLevel `json:"asks"`
+			Time time.Time       `json:"time"`
+		} `json:"pricebook"`
+	}
+
+	pairVal, err := e.convertPairValue(p)
+	if err != nil {
+		return OrderBook{}, err
+	}
+
+	query := url.Values{
+		"product_id": []string{pairVal},
+		"limit":      []string{strconv.Itoa(depth)},
+	}
+
+	var response bookResponse
+
+	if err := e.doJSON(ctx, http.MethodGet, "/api/v3/brokerage/product_book", query, nil, &response); err != nil {
+		return OrderBook{}, err
+	}
+
+	book := OrderBook{
+		Pair: p,
+		Time: response.PriceBook.Time,
+		Bids: make([]Level, 0, len(response.PriceBook.Bids)),
+		Asks: make([]Level, 0, len(response.PriceBook.Asks)),
+	}
+
+	for _, l := range response.PriceBook.Bids {
+		book.Bids = append(book.Bids, Level(l))
+	}
+
+	for _, l := range response.PriceBook.Asks {
+		book.Asks = append(book.Asks, Level(l))
+	}
+
+	return book, nil
+}
+
+// coinbaseGranularities maps the intervals of candles to the granularities of
+// coinbase.
+var coinbaseGranularities = map[time.Duration]string{
+	time.Minute:      "ONE_MINUTE",
+	5 * time.Minute:  "FIVE_MINUTE",
+	15 * time.Minute: "FIFTEEN_MINUTE",
+	30 * time.Minute: "THIRTY_MINUTE",
+	time.Hour:        "ONE_HOUR",
+	2 * time.Hour:    "TWO_HOUR",
+	6 * time.Hour:    "SIX_HOUR",
+	24 * time.Hour:   "ONE_DAY",
+}
+
+// coinbaseCandleLimit is the most candles that coinbase returns for a request.
+const coinbaseCandleLimit = 350
+
+// CandleIntervals returns the intervals of the candles that coinbase offers,
+// from the shortest.
+func (e *Coinbase) CandleIntervals() []time.Duration {
+	return supportedIntervals(coinbaseGranularities)
+}
+
+// GetCandles obtains the candles of the pair with the interval that start
+// from the start time up to but not including the end time, oldest first.
+// The interval must be one of CandleIntervals.
+func (e *Coinbase) GetCandles(
+	ctx context.Context, p trading.Pair, interval time.Duration, start time.Time, end time.Time,
+) ([]Candle, error) {
+	type candlesResponse struct {
+		Candles []struct {
+			Start  string         `json:"start"`
+			Open   trading.Amount `json:"open"`
+			High   trading.Amount `json:"high"`
+			Low    trading.Amount `json:"low"`
+			Close  trading.Amount `json:"close"`
+			Volume trading.Amount `json:"volume"`
+		} `json:"candles"`
+	}
+
+	granularity, ok := coinbaseGranularities[interval]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", interval, ErrUnsupportedInterval)
+	}
+
+	productID, err := e.convertPairValue(p)
+	if err != nil {
+		return nil, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	path := fmt.Sprintf("/api/v3/brokerage/products/%s/candles", productID)
+	query := url.Values{"granularity": []string{granularity}}
+	candles := make([]Candle, 0)
+
+	// The range of each request is limited to the most candles that it can
+	// return, and both of its ends are included.
+	for from := start; from.Before(end); from = from.Add(coinbaseCandleLimit * interval) {
+		to := from.Add(coinbaseCandleLimit * interval)
+		if to.After(end) {
+			to = end
+		}
+
+		query.Set("start", strconv.FormatInt(from.Unix(), 10))
+		query.Set("end", strconv.FormatInt(to.Add(-time.Second).Unix(), 10))
+
+		var data candlesResponse
+
+		if err := e.doJSON(ctx, http.MethodGet, path, query, nil, &data); err != nil {
+			return nil, fmt.Errorf("get candles: %w", err)
+		}
+
+		for _, c := range data.Candles {
+			seconds, err := strconv.ParseInt(c.Start, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse candle start: %w", err)
+			}
+
+			candles = append(candles, Candle{
+				Start:  time.Unix(seconds, 0).UTC(),
+				Open:   c.Open,
+				High:   c.High,
+				Low:    c.Low,
+				Close:  c.Close,
+				Volume: c.Volume,
+			})
+		}
+	}
+
+	// Coinbase returns the newest candles first.
+	sort.SliceStable(candles, func(i, j int) bool {
+		return candles[i].Start.Before(candles[j].Start)
+	})
+
+	return candles, nil
+}
+
+// doJSON performs a signed request against the advanced trade api. The body,
+// if not nil, is encoded as JSON and the response is decoded into v.
+func (e *Coinbase) doJSON(
+	ctx context.Context, method string, path string, query url.Values, body any, v any,
+) error {
+	endpoint := e.BaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode body: %w", err)
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return fmt.Errorf("create new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
 	res, err := e.doRequest(req)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("perform request: %w", err)
 	}
 
-	var response priceResponse
+	defer res.Body.Close()
 
-	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("decode json: %w", err)
+	if res.StatusCode != http.StatusOK {
+		return decodeCoinbaseError(res)
 	}
 
-	return response.Price, nil
+	if err = json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode json: %w", err)
+	}
+
+	return nil
+}
+
+// coinbaseErrorKinds maps the error values returned by coinbase to the class
+// of error that they belong to. NOT_FOUND is left out, as coinbase returns it
+// for unknown products as well as unknown orders, so only the order endpoints
+// class it as ErrUnknownOrder.
+var coinbaseErrorKinds = map[string]error{
+	"UNAUTHENTICATED":    ErrAuthFailed,
+	"PERMISSION_DENIED":  ErrAuthFailed,
+	"RESOURCE_EXHAUSTED": ErrRateLimited,
+	"INVALID_ARGUMENT":   ErrInvalidOrder,
+	"UNAVAILABLE":        ErrMaintenance,
+	"INTERNAL":           ErrMaintenance,
+}
+
+// decodeCoinbaseError decodes the error payload of a failed response into an
+// APIError, falling back to the HTTP status code to classify it.
+func decodeCoinbaseError(res *http.Response) error {
+	type errorResponse struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		RetryAfter: retryAfter(res),
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read error body: %w", err)
+	}
+
+	var data errorResponse
+
+	if err := json.Unmarshal(body, &data); err != nil || data.Error == "" {
+		apiErr.Message = string(body)
+	} else {
+		apiErr.Code = data.Error
+		apiErr.Message = data.Message
+		apiErr.Kind = coinbaseErrorKinds[data.Error]
+	}
+
+	if apiErr.Kind == nil {
+		apiErr.Kind = classifyStatus(res.StatusCode)
+	}
+
+	return apiErr
 }
 
-func (e *Coinbase) CreateLimitOrder(ctx context.Context, order order.Limit) (Order, error) {
-	return Order{}, nil
+// unknownOrder classes a NOT_FOUND error from an order endpoint as
+// ErrUnknownOrder, as the order is the only thing that the endpoint can fail
+// to find.
+func unknownOrder(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == "NOT_FOUND" {
+		apiErr.Kind = ErrUnknownOrder
+	}
+
+	return err
 }
 
-func (e *Coinbase) CancelOrders(ctx context.Context, orderID ...string) error {
+// orderFailure creates an error for an order that was rejected by coinbase.
+// Coinbase responds to these with a 200 status and the reason in the body.
+func (e *Coinbase) orderFailure(reason string, previewReason string, message string) error {
+	kind := ErrInvalidOrder
+
+	if reason == "INSUFFICIENT_FUND" || previewReason == "PREVIEW_INSUFFICIENT_FUND" {
+		kind = ErrInsufficientFunds
+	}
+
+	return &APIError{
+		StatusCode: http.StatusOK,
+		Code:       reason,
+		Message:    message,
+		Kind:       kind,
+	}
+}
+
+type coinbaseOrder struct {
+	OrderID            string `json:"order_id"`
+	ProductID          string `json:"product_id"`
+	Side               string `json:"side"`
+	ClientOrderID      string `json:"client_order_id"`
+	OrderConfiguration map[string]struct {
+		BaseSize   trading.Amount `json:"base_size"`
+		LimitPrice trading.Amount `json:"limit_price"`
+	} `json:"order_configuration"`
+	Status             string         `json:"status"`
+	FilledSize         trading.Amount `json:"filled_size"`
+	AverageFilledPrice trading.Amount `json:"average_filled_price"`
+	TotalFees          trading.Amount `json:"total_fees"`
+	CreatedTime        *time.Time     `json:"created_time"`
+	LastFillTime       *time.Time     `json:"last_fill_time"`
+}
+
+// coinbaseStatuses maps the statuses of coinbase orders which are not open to
+// the status of an order.
+var coinbaseStatuses = map[string]order.Status{
+	"FILLED":    order.StatusFilled,
+	"CANCELLED": order.StatusCancelled,
+	"EXPIRED":   order.StatusExpired,
+	"FAILED":    order.StatusRejected,
+}
+
+func (e *Coinbase) toOrder(o coinbaseOrder) Order {
+	// Orders on products that are not supported are still returned so that
+	// they can be cancelled, which is why the error is ignored here.
+	pair, _ := e.convertProductID(o.ProductID)
+
+	eOrder := Order{
+		ID:           o.OrderID,
+		Pair:         pair,
+		Side:         order.Side(o.Side),
+		ClientID:     o.ClientOrderID,
+		Status:       coinbaseStatuses[o.Status],
+		FilledSize:   o.FilledSize,
+		AveragePrice: o.AverageFilledPrice,
+		Fee:          o.TotalFees,
+	}
+
+	switch o.Status {
+	case "PENDING", "OPEN", "QUEUED", "CANCEL_QUEUED":
+		eOrder.Status = order.OpenStatus(o.FilledSize)
+	}
+
+	if !o.TotalFees.IsZero() {
+		eOrder.FeeAsset = pair.Quote
+	}
+
+	if o.CreatedTime != nil {
+		eOrder.CreatedAt, eOrder.UpdatedAt = *o.CreatedTime, *o.CreatedTime
+	}
+
+	if o.LastFillTime != nil {
+		eOrder.UpdatedAt = *o.LastFillTime
+	}
+
+	// The configuration is keyed by the type of the order, of which there is
+	// only ever one.
+	for _, config := range o.OrderConfiguration {
+		eOrder.BaseSize = config.BaseSize
+		eOrder.Price = config.LimitPrice
+	}
+
+	return eOrder
+}
+
+// coinbaseOrderRequest is the body of a request to create an order. The
+// configuration is keyed by the type of the order.
+type coinbaseOrderRequest struct {
+	ClientOrderID      string         `json:"client_order_id"`
+	ProductID          string         `json:"product_id"`
+	Side               string         `json:"side"`
+	OrderConfiguration map[string]any `json:"order_configuration"`
+}
+
+// createOrder places the order on coinbase. Coinbase responds with a success
+// flag rather than an error status when it rejects an order, which is turned
+// into an error here.
+func (e *Coinbase) createOrder(ctx context.Context, body coinbaseOrderRequest) (Order, error) {
+	type orderResponse struct {
+		Success         bool          `json:"success"`
+		FailureReason   string        `json:"failure_reason"`
+		OrderID         string        `json:"order_id"`
+		SuccessResponse coinbaseOrder `json:"success_response"`
+		ErrorResponse   struct {
+			Error                string `json:"error"`
+			Message              string `json:"message"`
+			PreviewFailureReason string `json:"preview_failure_reason"`
+		} `json:"error_response"`
+	}
+
+	var data orderResponse
+
+	if err := e.doJSON(ctx, http.MethodPost, "/api/v3/brokerage/orders", nil, body, &data); err != nil {
+		return Order{}, fmt.Errorf("create order: %w", err)
+	}
+
+	if !data.Success {
+		resErr := data.ErrorResponse
+
+		return Order{}, fmt.Errorf(
+			"order rejected: %w", e.orderFailure(resErr.Error, resErr.PreviewFailureReason, resErr.Message),
+		)
+	}
+
+	return e.toOrder(data.SuccessResponse), nil
+}
+
+// CreateLimitOrder places a new limit order on coinbase, using the order
+// configuration of its time in force. Orders with an expiry are placed as good
+// till date, otherwise they are good till cancelled by default.
+func (e *Coinbase) CreateLimitOrder(ctx context.Context, o order.Limit) (Order, error) {
+	type limitConfig struct {
+		BaseSize   trading.Amount `json:"base_size"`
+		LimitPrice trading.Amount `json:"limit_price"`
+		EndTime    *time.Time     `json:"end_time,omitempty"`
+		PostOnly   bool           `json:"post_only"`
+	}
+
+	if err := o.Validate(); err != nil {
+		return Order{}, fmt.Errorf("%v: %w", err, ErrInvalidOrder)
+	}
+
+	productID, err := e.convertPairValue(o.Pair)
+	if err != nil {
+		return Order{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	var (
+		configType string
+		config     any
+	)
+
+	switch o.EffectiveTimeInForce() {
+	case order.TimeInForceGTD:
+		configType = "limit_limit_gtd"
+		config = limitConfig{BaseSize: o.BaseSize, LimitPrice: o.Price, EndTime: o.Expires, PostOnly: o.PostOnly}
+	case order.TimeInForceIOC:
+		configType = "sor_limit_ioc"
+		config = map[string]trading.Amount{"base_size": o.BaseSize, "limit_price": o.Price}
+	case order.TimeInForceFOK:
+		configType = "limit_limit_fok"
+		config = map[string]trading.Amount{"base_size": o.BaseSize, "limit_price": o.Price}
+	default:
+		configType = "limit_limit_gtc"
+		config = limitConfig{BaseSize: o.BaseSize, LimitPrice: o.Price, PostOnly: o.PostOnly}
+	}
+
+	eOrder, err := e.createOrder(ctx, coinbaseOrderRequest{
+		ClientOrderID:      o.ClientID,
+		ProductID:          productID,
+		Side:               string(o.Side),
+		OrderConfiguration: map[string]any{configType: config},
+	})
+	if err != nil {
+		return Order{}, err
+	}
+
+	eOrder.BaseSize = o.BaseSize
+	eOrder.Price = o.Price
+
+	return eOrder, nil
+}
+
+// CreateMarketOrder places a new market order on coinbase. Orders with a
+// price limit are placed as limit orders at the price limit that are
+// immediate or cancel, which cancels any part of the order that cannot be
+// filled straight away.
+func (e *Coinbase) CreateMarketOrder(ctx context.Context, o order.Market) (Order, error) {
+	if err := o.Validate(); err != nil {
+		return Order{}, fmt.Errorf("%v: %w", err, ErrInvalidOrder)
+	}
+
+	productID, err := e.convertPairValue(o.Pair)
+	if err != nil {
+		return Order{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	var (
+		configType string
+		config     map[string]trading.Amount
+	)
+
+	switch {
+	case o.PriceLimit.Sign() > 0:
+		configType = "sor_limit_ioc"
+		config = map[string]trading.Amount{
+			"base_size":   protectedBaseSize(e.markets, o),
+			"limit_price": o.PriceLimit,
+		}
+	case o.QuoteSize.IsZero():
+		configType = "market_market_ioc"
+		config = map[string]trading.Amount{"base_size": o.BaseSize}
+	default:
+		configType = "market_market_ioc"
+		config = map[string]trading.Amount{"quote_size": o.QuoteSize}
+	}
+
+	eOrder, err := e.createOrder(ctx, coinbaseOrderRequest{
+		ClientOrderID:      o.ClientID,
+		ProductID:          productID,
+		Side:               string(o.Side),
+		OrderConfiguration: map[string]any{configType: config},
+	})
+	if err != nil {
+		return Order{}, err
+	}
+
+	eOrder.BaseSize = config["base_size"]
+	eOrder.Price = o.PriceLimit
+
+	return eOrder, nil
+}
+
+// CreateStopLimitOrder places a new stop limit order on coinbase, which is
+// good till cancelled.
+func (e *Coinbase) CreateStopLimitOrder(ctx context.Context, o order.StopLimit) (Order, error) {
+	type stopLimitConfig struct {
+		BaseSize      trading.Amount `json:"base_size"`
+		LimitPrice    trading.Amount `json:"limit_price"`
+		StopPrice     trading.Amount `json:"stop_price"`
+		StopDirection string         `json:"stop_direction"`
+	}
+
+	var direction string
+
+	switch o.Direction {
+	case order.DirectionAbove:
+		direction = "STOP_DIRECTION_STOP_UP"
+	case order.DirectionBelow:
+		direction = "STOP_DIRECTION_STOP_DOWN"
+	default:
+		return Order{}, fmt.Errorf("direction %q: %w", o.Direction, ErrInvalidOrder)
+	}
+
+	productID, err := e.convertPairValue(o.Pair)
+	if err != nil {
+		return Order{}, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	config := stopLimitConfig{
+		BaseSize:      o.BaseSize,
+		LimitPrice:    o.Price,
+		StopPrice:     o.StopPrice,
+		StopDirection: direction,
+	}
+
+	eOrder, err := e.createOrder(ctx, coinbaseOrderRequest{
+		ClientOrderID:      o.ClientID,
+		ProductID:          productID,
+		Side:               string(o.Side),
+		OrderConfiguration: map[string]any{"stop_limit_stop_limit_gtc": config},
+	})
+	if err != nil {
+		return Order{}, err
+	}
+
+	eOrder.BaseSize = o.BaseSize
+	eOrder.Price = o.Price
+
+	return eOrder, nil
+}
+
+// CreateStopMarketOrder always returns ErrUnsupportedOrder, as coinbase has no
+// stop market orders. Such orders have to be triggered on the client side.
+func (e *Coinbase) CreateStopMarketOrder(ctx context.Context, o order.StopMarket) (Order, error) {
+	return Order{}, fmt.Errorf("stop market: %w", ErrUnsupportedOrder)
+}
+
+// coinbaseIgnoredCancelFailures are the failure reasons of a cancel which mean
+// that the order is not open, either because it has already been cancelled or
+// because it has been filled.
+var coinbaseIgnoredCancelFailures = map[string]bool{
+	"UNKNOWN_CANCEL_ORDER":     true,
+	"DUPLICATE_CANCEL_REQUEST": true,
+}
+
+// CancelOrders cancels the orders with the given IDs. Orders which are no
+// longer open are ignored as there is nothing to cancel.
+func (e *Coinbase) CancelOrders(ctx context.Context, orderIDs ...string) error {
+	type cancelRequest struct {
+		OrderIDs []string `json:"order_ids"`
+	}
+
+	type cancelResponse struct {
+		Results []struct {
+			Success       bool   `json:"success"`
+			FailureReason string `json:"failure_reason"`
+			OrderID       string `json:"order_id"`
+		} `json:"results"`
+	}
+
+	// The batch cancel endpoint accepts at most this many orders per request.
+	const batchSize = 100
+
+	for len(orderIDs) > 0 {
+		batch := orderIDs
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+
+		orderIDs = orderIDs[len(batch):]
+
+		var data cancelResponse
+
+		body := cancelRequest{OrderIDs: batch}
+
+		err := e.doJSON(ctx, http.MethodPost, "/api/v3/brokerage/orders/batch_cancel", nil, body, &data)
+		if err != nil {
+			return fmt.Errorf("batch cancel: %w", err)
+		}
+
+		for _, r := range data.Results {
+			if r.Success || coinbaseIgnoredCancelFailures[r.FailureReason] {
+				continue
+			}
+
+			return fmt.Errorf("cancel order %s: %w", r.OrderID, &APIError{
+				StatusCode: http.StatusOK,
+				Code:       r.FailureReason,
+			})
+		}
+	}
+
 	return nil
 }
 
+// GetOrder returns the order with the given ID, whether it is open or not.
+// The pair is not needed by coinbase, and is only taken to match the other
+// exchanges. ErrUnknownOrder is returned if there is no such order.
+func (e *Coinbase) GetOrder(ctx context.Context, p trading.Pair, orderID string) (Order, error) {
+	type orderResponse struct {
+		Order coinbaseOrder `json:"order"`
+	}
+
+	var data orderResponse
+
+	path := "/api/v3/brokerage/orders/historical/" + url.PathEscape(orderID)
+
+	if err := e.doJSON(ctx, http.MethodGet, path, nil, nil, &data); err != nil {
+		return Order{}, fmt.Errorf("get order: %w", unknownOrder(err))
+	}
+
+	return e.toOrder(data.Order), nil
+}
+
+// ListOpenOrders returns all of the open orders on the account, following the
+// pagination cursor until every page has been read.
 func (e *Coinbase) ListOpenOrders(ctx context.Context) ([]Order, error) {
-	return nil, nil
+	type ordersResponse struct {
+		Orders  []coinbaseOrder `json:"orders"`
+		HasNext bool            `json:"has_next"`
+		Cursor  string          `json:"cursor"`
+	}
+
+	orders := make([]Order, 0)
+	query := url.Values{}
+	query.Set("order_status", "OPEN")
+
+	for {
+		var data ordersResponse
+
+		err := e.doJSON(ctx, http.MethodGet, "/api/v3/brokerage/orders/historical/batch", query, nil, &data)
+		if err != nil {
+			return nil, fmt.Errorf("list orders: %w", err)
+		}
+
+		for _, o := range data.Orders {
+			orders = append(orders, e.toOrder(o))
+		}
+
+		if !data.HasNext || data.Cursor == "" {
+			return orders, nil
+		}
+
+		query.Set("cursor", data.Cursor)
+	}
+}
+
+// ListFills returns the fills of the orders on the pair since the given
+// time, oldest first, following the pagination cursor until every page has
+// been read. A zero time returns every fill. Coinbase does not return the
+// client ID of the order of a fill.
+func (e *Coinbase) ListFills(ctx context.Context, p trading.Pair, since time.Time) ([]Fill, error) {
+	type fillsResponse struct {
+		Fills []struct {
+			TradeID     string         `json:"trade_id"`
+			OrderID     string         `json:"order_id"`
+			TradeTime   time.Time      `json:"trade_time"`
+			Price       trading.Amount `json:"price"`
+			Size        trading.Amount `json:"size"`
+			Commission  trading.Amount `json:"commission"`
+			Side        string         `json:"side"`
+			SizeInQuote bool           `json:"size_in_quote"`
+		} `json:"fills"`
+		Cursor string `json:"cursor"`
+	}
+
+	productID, err := e.convertPairValue(p)
+	if err != nil {
+		return nil, fmt.Errorf("convert pair value: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("product_id", productID)
+
+	if !since.IsZero() {
+		query.Set("start_sequence_timestamp", since.UTC().Format(time.RFC3339))
+	}
+
+	fills := make([]Fill, 0)
+
+	for {
+		var data fillsResponse
+
+		err := e.doJSON(ctx, http.MethodGet, "/api/v3/brokerage/orders/historical/fills", query, nil, &data)
+		if err != nil {
+			return nil, fmt.Errorf("list fills: %w", err)
+		}
+
+		for _, f := range data.Fills {
+			size := f.Size
+
+			// Market orders that are sized in the quote asset have their fills
+			// sized in it too. The price of a fill is never zero.
+			if f.SizeInQuote {
+				size, _ = f.Size.Div(f.Price, p.Base.Decimals(), trading.RoundDown)
+			}
+
+			fills = append(fills, Fill{
+				ID:       f.TradeID,
+				OrderID:  f.OrderID,
+				Pair:     p,
+				Side:     order.Side(f.Side),
+				Price:    f.Price,
+				Size:     size,
+				Fee:      f.Commission,
+				FeeAsset: p.Quote,
+				Time:     f.TradeTime,
+			})
+		}
+
+		if data.Cursor == "" {
+			break
+		}
+
+		query.Set("cursor", data.Cursor)
+	}
+
+	// Coinbase returns the newest fills first.
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].Time.Before(fills[j].Time)
+	})
+
+	return fills, nil
 }
 
-func (e *Coinbase) GetBalance(ctx context.Context, asset trading.Asset) (int64, error) {
-	return 0, nil
+// GetBalance returns the available balance of the asset. Funds that are on
+// hold for open orders are not included.
+func (e *Coinbase) GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error) {
+	type accountsResponse struct {
+		Accounts []struct {
+			Currency         string `json:"currency"`
+			AvailableBalance struct {
+				Value    string `json:"value"`
+				Currency string `json:"currency"`
+			} `json:"available_balance"`
+		} `json:"accounts"`
+		HasNext bool   `json:"has_next"`
+		Cursor  string `json:"cursor"`
+	}
+
+	// The maximum number of accounts that can be fetched in a single page.
+	const pageLimit = "250"
+
+	query := url.Values{}
+	query.Set("limit", pageLimit)
+
+	for {
+		var data accountsResponse
+
+		if err := e.doJSON(ctx, http.MethodGet, "/api/v3/brokerage/accounts", query, nil, &data); err != nil {
+			return trading.Amount{}, fmt.Errorf("list accounts: %w", err)
+		}
+
+		for _, a := range data.Accounts {
+			if a.Currency != string(asset) {
+				continue
+			}
+
+			balance, err := trading.ParseAmount(a.AvailableBalance.Value)
+			if err != nil {
+				return trading.Amount{}, fmt.Errorf("parse balance: %w", err)
+			}
+
+			return balance, nil
+		}
+
+		if !data.HasNext || data.Cursor == "" {
+			return trading.Amount{}, nil
+		}
+
+		query.Set("cursor", data.Cursor)
+	}
 }
@@ -1,12 +1,26 @@
 package exchange_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
 
 func TestCoinbaseConstructor(t *testing.T) {
@@ -31,6 +45,7 @@ func TestCoinbaseConstructor(t *testing.T) {
 			},
 			wants: want{
 				coinbase: &exchange.Coinbase{
+					BaseURL:   "https://api.coinbase.com",
 					APIKey:    "FOO",
 					APISecret: "BAR",
 				},
@@ -70,8 +85,882 @@ func TestCoinbaseConstructor(t *testing.T) {
 
 			res, err := exchange.NewCoinbase()
 
-			assert.Equal(t, tt.wants.coinbase, res, "test: %s", tt.name)
 			assert.ErrorIs(t, err, tt.wants.err)
+
+			if tt.wants.coinbase == nil {
+				assert.Nil(t, res)
+
+				return
+			}
+
+			assert.Equal(t, tt.wants.coinbase.BaseURL, res.BaseURL, "test: %s", tt.name)
+			assert.Equal(t, tt.wants.coinbase.APIKey, res.APIKey, "test: %s", tt.name)
+			assert.Equal(t, tt.wants.coinbase.APISecret, res.APISecret, "test: %s", tt.name)
+		})
+	}
+}
+
+// newCoinbaseServer creates a test server that checks the signature of every
+// request before passing it to the handler.
+func newCoinbaseServer(t *testing.T, handler http.HandlerFunc) *exchange.Coinbase {
+	t.Helper()
+
+	const (
+		key    = "key"
+		secret = "secret"
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		payload := r.Header.Get("CB-ACCESS-TIMESTAMP") + r.Method + r.URL.Path + string(body)
+
+		hash := hmac.New(sha256.New, []byte(secret))
+		hash.Write([]byte(payload))
+
+		assert.Equal(t, hex.EncodeToString(hash.Sum(nil)), r.Header.Get("CB-ACCESS-SIGN"))
+		assert.Equal(t, key, r.Header.Get("CB-ACCESS-KEY"))
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		handler(w, r)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	os.Setenv("COINBASE_API_KEY", key)
+	os.Setenv("COINBASE_API_SECRET", secret)
+
+	e, err := exchange.NewCoinbase(exchange.WithBaseURL(srv.URL), exchange.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestCoinbaseCreateLimitOrder(t *testing.T) {
+	expires := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name  string
+		input order.Limit
+		body  string
+	}{
+		{
+			name: "limit order is placed as good till cancelled",
+			input: order.Limit{
+				ClientID: "foobar",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("0.01"),
+				Price:    trading.MustParseAmount("500"),
+				PostOnly: true,
+			},
+			body: `{
+				"client_order_id": "foobar",
+				"product_id": "BTC-USD",
+				"side": "BUY",
+				"order_configuration": {
+					"limit_limit_gtc": {"base_size": "0.01", "limit_price": "500", "post_only": true}
+				}
+			}`,
+		},
+		{
+			name: "limit order with an expiry is placed as good till date",
+			input: order.Limit{
+				ClientID: "foobar",
+				Pair:     trading.ETHUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("1.5"),
+				Price:    trading.MustParseAmount("2000.10"),
+				Expires:  &expires,
+			},
+			body: `{
+				"client_order_id": "foobar",
+				"product_id": "ETH-USD",
+				"side": "SELL",
+				"order_configuration": {
+					"limit_limit_gtd": {
+						"base_size": "1.5",
+						"limit_price": "2000.1",
+						"end_time": "2023-01-02T03:04:05Z",
+						"post_only": false
+					}
+				}
+			}`,
+		},
+		{
+			name: "immediate or cancel order",
+			input: order.Limit{
+				ClientID:    "foobar",
+				Pair:        trading.BTCUSD,
+				Side:        order.SideBuy,
+				BaseSize:    trading.MustParseAmount("0.01"),
+				Price:       trading.MustParseAmount("500"),
+				TimeInForce: order.TimeInForceIOC,
+			},
+			body: `{
+				"client_order_id": "foobar",
+				"product_id": "BTC-USD",
+				"side": "BUY",
+				"order_configuration": {"sor_limit_ioc": {"base_size": "0.01", "limit_price": "500"}}
+			}`,
+		},
+		{
+			name: "fill or kill order",
+			input: order.Limit{
+				ClientID:    "foobar",
+				Pair:        trading.BTCUSD,
+				Side:        order.SideSell,
+				BaseSize:    trading.MustParseAmount("0.01"),
+				Price:       trading.MustParseAmount("500"),
+				TimeInForce: order.TimeInForceFOK,
+			},
+			body: `{
+				"client_order_id": "foobar",
+				"product_id": "BTC-USD",
+				"side": "SELL",
+				"order_configuration": {"limit_limit_fok": {"base_size": "0.01", "limit_price": "500"}}
+			}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/v3/brokerage/orders", r.URL.Path)
+
+				body, _ := io.ReadAll(r.Body)
+				assert.JSONEq(t, tt.body, string(body))
+
+				var req struct {
+					ProductID string `json:"product_id"`
+					Side      string `json:"side"`
+				}
+
+				_ = json.Unmarshal(body, &req)
+
+				fmt.Fprintf(w, `{
+					"success": true,
+					"order_id": "abc",
+					"success_response": {
+						"order_id": "abc",
+						"product_id": %q,
+						"side": %q,
+						"client_order_id": "foobar"
+					}
+				}`, req.ProductID, req.Side)
+			})
+
+			res, err := e.CreateLimitOrder(context.Background(), tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, exchange.Order{
+				ID:       "abc",
+				Pair:     tt.input.Pair,
+				Side:     tt.input.Side,
+				ClientID: "foobar",
+				BaseSize: tt.input.BaseSize,
+				Price:    tt.input.Price,
+			}, res)
+		})
+	}
+}
+
+func TestCoinbaseCreateLimitOrderRejected(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"success": false,
+			"failure_reason": "UNKNOWN_FAILURE_REASON",
+			"error_response": {"error": "INSUFFICIENT_FUND", "message": "Insufficient balance in source account"}
+		}`)
+	})
+
+	_, err := e.CreateLimitOrder(context.Background(), order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("1"),
+		Price:    trading.MustParseAmount("1"),
+	})
+
+	assert.ErrorIs(t, err, exchange.ErrInsufficientFunds)
+	assert.ErrorContains(t, err, "INSUFFICIENT_FUND")
+}
+
+func TestCoinbaseCreateMarketOrder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    order.Market
+		body     string
+		expected exchange.Order
+	}{
+		{
+			name: "market order by base size",
+			input: order.Market{
+				ClientID: "foobar",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.01"),
+			},
+			body: `{
+				"client_order_id": "foobar",
+				"product_id": "BTC-USD",
+				"side": "SELL",
+				"order_configuration": {"market_market_ioc": {"base_size": "0.01"}}
+			}`,
+			expected: exchange.Order{
+				ID:       "abc",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				ClientID: "foobar",
+				BaseSize: trading.MustParseAmount("0.01"),
+			},
+		},
+		{
+			name: "market order by quote size",
+			input: order.Market{
+				ClientID:  "foobar",
+				Pair:      trading.ETHUSD,
+				Side:      order.SideBuy,
+				QuoteSize: trading.MustParseAmount("25.50"),
+			},
+			body: `{
+				"client_order_id": "foobar",
+				"product_id": "ETH-USD",
+				"side": "BUY",
+				"order_configuration": {"market_market_ioc": {"quote_size": "25.5"}}
+			}`,
+			expected: exchange.Order{
+				ID:       "abc",
+				Pair:     trading.ETHUSD,
+				Side:     order.SideBuy,
+				ClientID: "foobar",
+			},
+		},
+		{
+			name: "order with a price limit is placed as immediate or cancel",
+			input: order.Market{
+				ClientID:   "foobar",
+				Pair:       trading.BTCUSD,
+				Side:       order.SideBuy,
+				QuoteSize:  trading.MustParseAmount("100"),
+				PriceLimit: trading.MustParseAmount("17500"),
+			},
+			body: `{
+				"client_order_id": "foobar",
+				"product_id": "BTC-USD",
+				"side": "BUY",
+				"order_configuration": {"sor_limit_ioc": {"base_size": "0.00571428", "limit_price": "17500"}}
+			}`,
+			expected: exchange.Order{
+				ID:       "abc",
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				ClientID: "foobar",
+				BaseSize: trading.MustParseAmount("0.00571428"),
+				Price:    trading.MustParseAmount("17500"),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/v3/brokerage/orders", r.URL.Path)
+
+				body, _ := io.ReadAll(r.Body)
+				assert.JSONEq(t, tt.body, string(body))
+
+				var req struct {
+					ProductID string `json:"product_id"`
+					Side      string `json:"side"`
+				}
+
+				_ = json.Unmarshal(body, &req)
+
+				fmt.Fprintf(w, `{
+					"success": true,
+					"order_id": "abc",
+					"success_response": {
+						"order_id": "abc",
+						"product_id": %q,
+						"side": %q,
+						"client_order_id": "foobar"
+					}
+				}`, req.ProductID, req.Side)
+			})
+
+			res, err := e.CreateMarketOrder(context.Background(), tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestCoinbaseCreateStopLimitOrder(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v3/brokerage/orders", r.URL.Path)
+
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{
+			"client_order_id": "foobar",
+			"product_id": "BTC-USD",
+			"side": "SELL",
+			"order_configuration": {
+				"stop_limit_stop_limit_gtc": {
+					"base_size": "0.01",
+					"limit_price": "15900",
+					"stop_price": "16000",
+					"stop_direction": "STOP_DIRECTION_STOP_DOWN"
+				}
+			}
+		}`, string(body))
+
+		fmt.Fprint(w, `{
+			"success": true,
+			"order_id": "abc",
+			"success_response": {"order_id": "abc", "product_id": "BTC-USD", "side": "SELL", "client_order_id": "foobar"}
+		}`)
+	})
+
+	res, err := e.CreateStopLimitOrder(context.Background(), order.StopLimit{
+		ClientID:  "foobar",
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.01"),
+		Price:     trading.MustParseAmount("15900"),
+		StopPrice: trading.MustParseAmount("16000"),
+		Direction: order.DirectionBelow,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.Order{
+		ID:       "abc",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideSell,
+		ClientID: "foobar",
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("15900"),
+	}, res)
+}
+
+func TestCoinbaseCreateStopMarketOrder(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	_, err := e.CreateStopMarketOrder(context.Background(), order.StopMarket{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.01"),
+		StopPrice: trading.MustParseAmount("16000"),
+		Direction: order.DirectionBelow,
+	})
+
+	assert.ErrorIs(t, err, exchange.ErrUnsupportedOrder)
+}
+
+func TestCoinbaseErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status int
+		body   string
+		wants  error
+	}{
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":"RESOURCE_EXHAUSTED","message":"too many requests"}`,
+			wants:  exchange.ErrRateLimited,
+		},
+		{
+			name:   "bad credentials",
+			status: http.StatusUnauthorized,
+			body:   `Unauthorized`,
+			wants:  exchange.ErrAuthFailed,
+		},
+		{
+			name:   "bad argument",
+			status: http.StatusBadRequest,
+			body:   `{"error":"INVALID_ARGUMENT","code":3,"message":"invalid product_id"}`,
+			wants:  exchange.ErrInvalidOrder,
+		},
+		{
+			name:   "unavailable",
+			status: http.StatusBadGateway,
+			body:   `<html>bad gateway</html>`,
+			wants:  exchange.ErrMaintenance,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			_, err := e.GetLastPrice(context.Background(), trading.BTCUSD)
+			assert.ErrorIs(t, err, tt.wants)
+
+			_, err = e.ListOpenOrders(context.Background())
+			assert.ErrorIs(t, err, tt.wants)
+		})
+	}
+}
+
+func TestCoinbaseCancelOrders(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+		wantsErr bool
+	}{
+		{
+			name: "all orders cancelled",
+			response: `{"results": [
+				{"success": true, "failure_reason": "UNKNOWN_CANCEL_FAILURE_REASON", "order_id": "a"},
+				{"success": true, "failure_reason": "UNKNOWN_CANCEL_FAILURE_REASON", "order_id": "b"}
+			]}`,
+		},
+		{
+			name: "orders that are no longer open are ignored",
+			response: `{"results": [
+				{"success": true, "failure_reason": "UNKNOWN_CANCEL_FAILURE_REASON", "order_id": "a"},
+				{"success": false, "failure_reason": "UNKNOWN_CANCEL_ORDER", "order_id": "b"}
+			]}`,
+		},
+		{
+			name: "other failures are returned",
+			response: `{"results": [
+				{"success": false, "failure_reason": "COMMANDER_REJECTED_CANCEL_ORDER", "order_id": "a"}
+			]}`,
+			wantsErr: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v3/brokerage/orders/batch_cancel", r.URL.Path)
+
+				body, _ := io.ReadAll(r.Body)
+				assert.JSONEq(t, `{"order_ids": ["a", "b"]}`, string(body))
+
+				fmt.Fprint(w, tt.response)
+			})
+
+			err := e.CancelOrders(context.Background(), "a", "b")
+
+			if tt.wantsErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCoinbaseGetOrder(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v3/brokerage/orders/historical/a", r.URL.Path)
+
+		fmt.Fprint(w, `{
+			"order": {
+				"order_id": "a",
+				"product_id": "BTC-USD",
+				"side": "SELL",
+				"client_order_id": "x",
+				"order_configuration": {
+					"limit_limit_gtc": {"base_size": "0.5", "limit_price": "17000.00", "post_only": false}
+				},
+				"status": "FILLED",
+				"filled_size": "0.5",
+				"average_filled_price": "17000.00",
+				"total_fees": "51.00",
+				"created_time": "2023-01-02T03:04:05Z",
+				"last_fill_time": "2023-01-02T03:05:05Z"
+			}
+		}`)
+	})
+
+	res, err := e.GetOrder(context.Background(), trading.BTCUSD, "a")
+
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.Order{
+		ID:           "a",
+		Pair:         trading.BTCUSD,
+		Side:         order.SideSell,
+		ClientID:     "x",
+		BaseSize:     trading.MustParseAmount("0.5"),
+		Price:        trading.MustParseAmount("17000"),
+		Status:       order.StatusFilled,
+		FilledSize:   trading.MustParseAmount("0.5"),
+		AveragePrice: trading.MustParseAmount("17000"),
+		Fee:          trading.MustParseAmount("51"),
+		FeeAsset:     trading.USD,
+		CreatedAt:    time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt:    time.Date(2023, 1, 2, 3, 5, 5, 0, time.UTC),
+	}, res)
+}
+
+func TestCoinbaseGetOrderBook(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v3/brokerage/product_book", r.URL.Path)
+		assert.Equal(t, "BTC-USD", r.URL.Query().Get("product_id"))
+		assert.Equal(t, "50", r.URL.Query().Get("limit"))
+
+		fmt.Fprint(w, `{
+			"pricebook": {
+				"product_id": "BTC-USD",
+				"bids": [{"price": "21921.73", "size": "0.06317902"}],
+				"asks": [{"price": "21921.74", "size": "0.5"}, {"price": "21925.00", "size": "2"}],
+				"time": "2023-01-02T03:04:05Z"
+			}
+		}`)
+	})
+
+	book, err := e.GetOrderBook(context.Background(), trading.BTCUSD, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderBook{
+		Pair: trading.BTCUSD,
+		Time: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		Bids: []exchange.Level{
+			{Price: trading.MustParseAmount("21921.73"), Size: trading.MustParseAmount("0.06317902")},
+		},
+		Asks: []exchange.Level{
+			{Price: trading.MustParseAmount("21921.74"), Size: trading.MustParseAmount("0.5")},
+			{Price: trading.MustParseAmount("21925.00"), Size: trading.MustParseAmount("2")},
+		},
+	}, book)
+}
+
+func TestCoinbaseGetCandles(t *testing.T) {
+	start := time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+	end := start.Add(351 * time.Minute)
+
+	var ranges [][2]string
+
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/brokerage/products/BTC-USD/candles", r.URL.Path)
+		assert.Equal(t, "ONE_MINUTE", r.URL.Query().Get("granularity"))
+
+		ranges = append(ranges, [2]string{r.URL.Query().Get("start"), r.URL.Query().Get("end")})
+
+		// Each request returns the first candle of its range.
+		fmt.Fprintf(w, `{"candles": [
+			{"start": "%s", "low": "95", "high": "110", "open": "100", "close": "105", "volume": "1.5"}
+		]}`, r.URL.Query().Get("start"))
+	})
+
+	candles, err := e.GetCandles(context.Background(), trading.BTCUSD, time.Minute, start, end)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][2]string{
+		{strconv.FormatInt(start.Unix(), 10), strconv.FormatInt(start.Add(350*time.Minute-time.Second).Unix(), 10)},
+		{strconv.FormatInt(start.Add(350*time.Minute).Unix(), 10), strconv.FormatInt(end.Add(-time.Second).Unix(), 10)},
+	}, ranges)
+	assert.Len(t, candles, 2)
+	assert.Equal(t, exchange.Candle{
+		Start:  start,
+		Open:   trading.MustParseAmount("100"),
+		High:   trading.MustParseAmount("110"),
+		Low:    trading.MustParseAmount("95"),
+		Close:  trading.MustParseAmount("105"),
+		Volume: trading.MustParseAmount("1.5"),
+	}, candles[0])
+	assert.Equal(t, start.Add(350*time.Minute), candles[1].Start)
+
+	_, err = e.GetCandles(context.Background(), trading.BTCUSD, 3*time.Minute, start, end)
+	assert.ErrorIs(t, err, exchange.ErrUnsupportedInterval)
+}
+
+func TestCoinbaseGetUnknownOrder(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "NOT_FOUND", "message": "order not found"}`)
+	})
+
+	_, err := e.GetOrder(context.Background(), trading.BTCUSD, "a")
+
+	assert.ErrorIs(t, err, exchange.ErrUnknownOrder)
+}
+
+func TestCoinbaseNotFound(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "NOT_FOUND", "message": "product not found"}`)
+	})
+
+	// Only the order endpoints take NOT_FOUND to mean that the order does not
+	// exist.
+	_, err := e.GetOrderBook(context.Background(), trading.BTCUSD, 10)
+
+	var apiErr *exchange.APIError
+
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "NOT_FOUND", apiErr.Code)
+	assert.NotErrorIs(t, err, exchange.ErrUnknownOrder)
+}
+
+func TestCoinbaseListFills(t *testing.T) {
+	since := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/brokerage/orders/historical/fills", r.URL.Path)
+		assert.Equal(t, "BTC-USD", r.URL.Query().Get("product_id"))
+		assert.Equal(t, "2023-01-02T03:04:05Z", r.URL.Query().Get("start_sequence_timestamp"))
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{
+				"fills": [{
+					"trade_id": "t2",
+					"order_id": "b",
+					"trade_time": "2023-01-02T03:06:05Z",
+					"price": "20000.00",
+					"size": "100.00",
+					"commission": "0.60",
+					"side": "BUY",
+					"size_in_quote": true
+				}],
+				"cursor": "page2"
+			}`)
+		case "page2":
+			fmt.Fprint(w, `{
+				"fills": [{
+					"trade_id": "t1",
+					"order_id": "a",
+					"trade_time": "2023-01-02T03:05:05Z",
+					"price": "17000.00",
+					"size": "0.5",
+					"commission": "51.00",
+					"side": "SELL",
+					"size_in_quote": false
+				}],
+				"cursor": ""
+			}`)
+		}
+	})
+
+	res, err := e.ListFills(context.Background(), trading.BTCUSD, since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []exchange.Fill{
+		{
+			ID:       "t1",
+			OrderID:  "a",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideSell,
+			Price:    trading.MustParseAmount("17000"),
+			Size:     trading.MustParseAmount("0.5"),
+			Fee:      trading.MustParseAmount("51"),
+			FeeAsset: trading.USD,
+			Time:     since.Add(time.Minute),
+		},
+		{
+			ID:       "t2",
+			OrderID:  "b",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideBuy,
+			Price:    trading.MustParseAmount("20000"),
+			Size:     trading.MustParseAmount("0.005"),
+			Fee:      trading.MustParseAmount("0.6"),
+			FeeAsset: trading.USD,
+			Time:     since.Add(2 * time.Minute),
+		},
+	}, res)
+}
+
+func TestCoinbaseListOpenOrders(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/brokerage/orders/historical/batch", r.URL.Path)
+		assert.Equal(t, "OPEN", r.URL.Query().Get("order_status"))
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{
+				"orders": [{
+					"order_id": "a",
+					"product_id": "BTC-USD",
+					"side": "BUY",
+					"client_order_id": "x",
+					"order_configuration": {
+						"limit_limit_gtc": {"base_size": "0.5", "limit_price": "17000.00", "post_only": false}
+					}
+				}],
+				"has_next": true,
+				"cursor": "page2"
+			}`)
+		case "page2":
+			fmt.Fprint(w, `{
+				"orders": [{"order_id": "b", "product_id": "SOL-USD", "side": "SELL", "client_order_id": "y"}],
+				"has_next": false,
+				"cursor": ""
+			}`)
+		}
+	})
+
+	res, err := e.ListOpenOrders(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []exchange.Order{
+		{
+			ID:       "a",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideBuy,
+			ClientID: "x",
+			BaseSize: trading.MustParseAmount("0.5"),
+			Price:    trading.MustParseAmount("17000"),
+		},
+		{ID: "b", Side: order.SideSell, ClientID: "y"},
+	}, res)
+}
+
+func TestCoinbaseGetBalance(t *testing.T) {
+	testCases := []struct {
+		name     string
+		asset    trading.Asset
+		expected trading.Amount
+	}{
+		{
+			name:     "balance on the first page",
+			asset:    trading.BTC,
+			expected: trading.MustParseAmount("0.01"),
+		},
+		{
+			name:     "balance on the second page",
+			asset:    trading.USD,
+			expected: trading.MustParseAmount("50.01"),
+		},
+		{
+			name:     "balance beyond the range of an int64 in units",
+			asset:    trading.ETH,
+			expected: trading.MustParseAmount("250000.000000000000000001"),
+		},
+		{
+			name:  "asset without an account",
+			asset: trading.Asset("SOL"),
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v3/brokerage/accounts", r.URL.Path)
+
+				switch r.URL.Query().Get("cursor") {
+				case "":
+					fmt.Fprint(w, `{
+						"accounts": [{"currency": "BTC", "available_balance": {"value": "0.01", "currency": "BTC"}}],
+						"has_next": true,
+						"cursor": "page2"
+					}`)
+				case "page2":
+					fmt.Fprint(w, `{
+						"accounts": [
+							{"currency": "USD", "available_balance": {"value": "50.01", "currency": "USD"}},
+							{"currency": "ETH", "available_balance": {"value": "250000.000000000000000001", "currency": "ETH"}}
+						],
+						"has_next": false
+					}`)
+				}
+			})
+
+			res, err := e.GetBalance(context.Background(), tt.asset)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
 		})
 	}
 }
+
+func TestCoinbaseLoadMarkets(t *testing.T) {
+	e := newCoinbaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/brokerage/products", r.URL.Path)
+		assert.Equal(t, "SPOT", r.URL.Query().Get("product_type"))
+
+		fmt.Fprint(w, `{"products": [
+			{
+				"product_id": "SOL-USDC", "product_type": "SPOT", "status": "online", "trading_disabled": false,
+				"base_currency_id": "SOL", "quote_currency_id": "USDC",
+				"base_increment": "0.00000001", "quote_increment": "0.000001", "price_increment": "0.01",
+				"base_min_size": "0.0008", "quote_min_size": "1"
+			},
+			{
+				"product_id": "BTC-USD", "product_type": "SPOT", "status": "online", "trading_disabled": false,
+				"base_currency_id": "BTC", "quote_currency_id": "USD",
+				"base_increment": "0.00000001", "quote_increment": "0.01",
+				"base_min_size": "0.000016", "quote_min_size": "1"
+			},
+			{
+				"product_id": "LUNA-USD", "product_type": "SPOT", "status": "delisted", "trading_disabled": true,
+				"base_currency_id": "LUNA", "quote_currency_id": "USD",
+				"base_increment": "0.001", "quote_increment": "0.0001"
+			}
+		], "num_products": 3}`)
+	})
+
+	assert.NoError(t, e.LoadMarkets(context.Background()))
+
+	solusdc := trading.Pair{Base: "SOL", Quote: "USDC"}
+
+	assert.Equal(t, []trading.Market{
+		{
+			Pair:        trading.BTCUSD,
+			Symbol:      "BTC-USD",
+			TickSize:    trading.MustParseAmount("0.01"),
+			LotSize:     trading.MustParseAmount("0.00000001"),
+			MinSize:     trading.MustParseAmount("0.000016"),
+			MinNotional: trading.MustParseAmount("1"),
+		},
+		{Pair: trading.ETHUSD, Symbol: "ETH-USD"},
+		{
+			Pair:        solusdc,
+			Symbol:      "SOL-USDC",
+			TickSize:    trading.MustParseAmount("0.01"),
+			LotSize:     trading.MustParseAmount("0.00000001"),
+			MinSize:     trading.MustParseAmount("0.0008"),
+			MinNotional: trading.MustParseAmount("1"),
+		},
+	}, e.Markets().Markets())
+
+	assert.Equal(t, 6, solusdc.Quote.Decimals())
+	assert.False(t, trading.Asset("LUNA").Known())
+}
+
+func TestCoinbaseWithMarkets(t *testing.T) {
+	os.Setenv("COINBASE_API_KEY", "FOO")
+	os.Setenv("COINBASE_API_SECRET", "BAR")
+
+	markets := trading.NewRegistry(trading.Market{Pair: trading.Pair{Base: "SOL", Quote: "USDC"}, Symbol: "SOL-USDC"})
+
+	e, err := exchange.NewCoinbase(exchange.WithMarkets(markets))
+
+	assert.NoError(t, err)
+	assert.Same(t, markets, e.Markets())
+
+	_, err = e.CreateLimitOrder(context.Background(), order.Limit{Pair: trading.BTCUSD})
+
+	assert.ErrorIs(t, err, exchange.ErrMissingPair)
+}
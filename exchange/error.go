@@ -1,6 +1,12 @@
 package exchange
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
 
 var (
 	// ErrAPIKeyNotSet describes an error in which the API key is not set for
@@ -14,4 +20,100 @@ var (
 	// ErrMissingPair describes an error that occurs when a pair has not
 	// been implemented for an exchange.
 	ErrMissingPair = errors.New("pair value is missing for exchange")
+
+	// ErrRateLimited describes an error in which the exchange has rejected a
+	// request because too many requests or orders have been sent.
+	ErrRateLimited = errors.New("rate limited by exchange")
+
+	// ErrInsufficientFunds describes an error in which the account does not
+	// hold enough of an asset to place an order.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrInvalidOrder describes an error in which the exchange has rejected
+	// an order or request because of its parameters, such as a price with
+	// too many decimal places or a size below the minimum.
+	ErrInvalidOrder = errors.New("invalid order")
+
+	// ErrUnsupportedOrder describes an error in which the exchange does not
+	// support the type of an order, such as stop market orders on coinbase.
+	ErrUnsupportedOrder = errors.New("order type not supported by exchange")
+
+	// ErrUnsupportedInterval describes an error in which the exchange does
+	// not offer candles of an interval, such as three minute candles on
+	// coinbase.
+	ErrUnsupportedInterval = errors.New("candle interval not supported by exchange")
+
+	// ErrAuthFailed describes an error in which the exchange did not accept
+	// the credentials or signature of a request.
+	ErrAuthFailed = errors.New("authentication failed")
+
+	// ErrUnknownOrder describes an error in which the order does not exist
+	// on the exchange, or is no longer open.
+	ErrUnknownOrder = errors.New("unknown order")
+
+	// ErrMaintenance describes an error in which the exchange is unavailable,
+	// either due to maintenance or an internal error on their side.
+	ErrMaintenance = errors.New("exchange unavailable")
 )
+
+// APIError describes an error response returned by an exchange api. The
+// Kind holds one of the error classes above when the response could be
+// classified, which allows for checking the class with errors.Is.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+	Kind       error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("api error (status %d)", e.StatusCode)
+
+	if e.Kind != nil {
+		msg += ": " + e.Kind.Error()
+	}
+
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+
+	return msg
+}
+
+// Unwrap returns the class of the error.
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// classifyStatus returns the error class that the HTTP status code of a
+// response maps to, regardless of the exchange.
+func classifyStatus(statusCode int) error {
+	switch {
+	// Binance responds with a 418 once an IP has been banned for carrying on
+	// sending requests after being rate limited.
+	case statusCode == http.StatusTooManyRequests, statusCode == http.StatusTeapot:
+		return ErrRateLimited
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return ErrAuthFailed
+	case statusCode >= http.StatusInternalServerError:
+		return ErrMaintenance
+	default:
+		return nil
+	}
+}
+
+// retryAfter parses the Retry-After header of a response, which holds the
+// number of seconds to wait before sending another request.
+func retryAfter(res *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
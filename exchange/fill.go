@@ -0,0 +1,23 @@
+package exchange
+
+import (
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Fill represents a single execution of an order on the exchange. An order
+// can have many fills if it is matched in parts.
+type Fill struct {
+	ID       string
+	OrderID  string
+	ClientID string
+	Pair     trading.Pair
+	Side     order.Side
+	Price    trading.Amount
+	Size     trading.Amount
+	Fee      trading.Amount
+	FeeAsset trading.Asset
+	Time     time.Time
+}
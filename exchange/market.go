@@ -0,0 +1,42 @@
+package exchange
+
+import (
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// registerUnknownAsset registers the decimal places of an asset that has
+// been loaded from the markets of an exchange. Assets which are already known
+// are left alone, as exchanges often give fewer decimal places for an asset
+// than it has, such as 8 for ETH.
+func registerUnknownAsset(asset trading.Asset, decimals int) {
+	if asset.Known() {
+		return
+	}
+
+	trading.RegisterAsset(asset, decimals)
+}
+
+// protectedBaseSize returns the base size of a market order that is protected
+// from slippage, which is placed as a limit order at the price limit. Orders
+// that are sized in the quote asset are converted at the price limit, rounded
+// down to the lot size of the market, or the decimal places of the base asset
+// if there is no lot size.
+func protectedBaseSize(markets *trading.Registry, o order.Market) trading.Amount {
+	if o.QuoteSize.IsZero() {
+		return o.BaseSize
+	}
+
+	scale := o.Pair.Base.Decimals()
+
+	m, err := markets.Market(o.Pair)
+	if err == nil && m.LotSize.Sign() > 0 {
+		scale = m.LotSize.Scale()
+	}
+
+	// The price limit has already been checked to be positive, so there is
+	// no division by zero.
+	size, _ := o.QuoteSize.Div(o.PriceLimit, scale, trading.RoundDown)
+
+	return size.RoundToStep(m.LotSize, trading.RoundDown)
+}
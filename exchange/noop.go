@@ -2,6 +2,8 @@ package exchange
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/project-code-io/crypto-trading-bot-go/order"
 	"github.com/project-code-io/crypto-trading-bot-go/trading"
@@ -10,21 +12,15 @@ import (
 // Noop is an exchange that performs no operations on it's functions. This
 // type is only used in the scaffolding to help build out the logic.
 type Noop struct {
-	balances map[trading.Asset]int64
+	balances map[trading.Asset]trading.Amount
 }
 
 func NewNoop() (*Noop, error) {
-	const (
-		usdAmount = 50
-		btcAmount = 0.00001
-		ethAmount = 0.05
-	)
-
 	return &Noop{
-		balances: map[trading.Asset]int64{
-			trading.USD: trading.USD.Unit(usdAmount),
-			trading.BTC: trading.BTC.Unit(btcAmount),
-			trading.ETH: trading.ETH.Unit(ethAmount),
+		balances: map[trading.Asset]trading.Amount{
+			trading.USD: trading.MustParseAmount("50"),
+			trading.BTC: trading.MustParseAmount("0.00001"),
+			trading.ETH: trading.MustParseAmount("0.05"),
 		},
 	}, nil
 }
@@ -42,14 +38,26 @@ func (e *Noop) CreateLimitOrder(ctx context.Context, order order.Limit) (Order,
 	return Order{}, nil
 }
 
+func (e *Noop) CreateMarketOrder(ctx context.Context, order order.Market) (Order, error) {
+	return Order{}, nil
+}
+
 func (e *Noop) CancelOrders(ctx context.Context, orderID ...string) error {
 	return nil
 }
 
+func (e *Noop) GetOrder(ctx context.Context, p trading.Pair, orderID string) (Order, error) {
+	return Order{}, fmt.Errorf("order %s: %w", orderID, ErrUnknownOrder)
+}
+
 func (e *Noop) ListOpenOrders(ctx context.Context) ([]Order, error) {
 	return nil, nil
 }
 
-func (e *Noop) GetBalance(ctx context.Context, asset trading.Asset) (int64, error) {
+func (e *Noop) ListFills(ctx context.Context, p trading.Pair, since time.Time) ([]Fill, error) {
+	return nil, nil
+}
+
+func (e *Noop) GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error) {
 	return e.balances[asset], nil
 }
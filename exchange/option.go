@@ -0,0 +1,130 @@
+package exchange
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// defaultTimeout is the timeout of requests made to an exchange when no
+// HTTP client or timeout has been given.
+const defaultTimeout = 10 * time.Second
+
+// Option allows for overriding of the defaults of an exchange client. Use
+// these to point a client at a sandbox or test server, or to control how
+// requests are made.
+type Option func(o *options)
+
+type options struct {
+	baseURL   string
+	client    *http.Client
+	userAgent string
+	timeout   time.Duration
+	markets   *trading.Registry
+}
+
+func newOptions(baseURL string, opts []Option) options {
+	o := options{
+		baseURL: baseURL,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// requester returns the requester that has been configured by the options.
+// The given HTTP client is copied when a timeout is set, so that the
+// caller's client is left untouched.
+func (o options) requester() requester {
+	client := o.client
+
+	switch {
+	case client == nil:
+		client = &http.Client{Timeout: defaultTimeout}
+	case o.timeout > 0:
+		c := *client
+		client = &c
+	}
+
+	if o.timeout > 0 {
+		client.Timeout = o.timeout
+	}
+
+	return requester{
+		client:    client,
+		userAgent: o.userAgent,
+	}
+}
+
+// registry returns the market registry that has been given by the options,
+// or the default markets of the exchange if none has been given.
+func (o options) registry(defaults func() *trading.Registry) *trading.Registry {
+	if o.markets != nil {
+		return o.markets
+	}
+
+	return defaults()
+}
+
+// WithBaseURL overrides the base URL of the exchange api. Use this method to
+// point the client at a sandbox environment or a local test server.
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient overrides the HTTP client used to perform requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header that is sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithTimeout overrides the time limit of each request made to the exchange,
+// including that of a client given with WithHTTPClient.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// requester performs the HTTP requests of an exchange client.
+type requester struct {
+	client    *http.Client
+	userAgent string
+}
+
+func (r requester) do(req *http.Request) (*http.Response, error) {
+	if r.userAgent != "" {
+		req.Header.Set("User-Agent", r.userAgent)
+	}
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return client.Do(req)
+}
+
+// WithMarkets overrides the markets that the exchange client supports, which
+// maps pairs to the exchange's symbols. Use this method to trade pairs that
+// are not supported by default, such as from a registry loaded with
+// trading.LoadRegistry.
+func WithMarkets(markets *trading.Registry) Option {
+	return func(o *options) {
+		o.markets = markets
+	}
+}
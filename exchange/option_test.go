@@ -0,0 +1,66 @@
+package exchange_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestOptions(t *testing.T) {
+	os.Setenv("BINANCE_API_KEY", "FOO")
+	os.Setenv("BINANCE_API_SECRET", "BAR")
+
+	t.Run("requests are sent to the base url with the user agent", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "trading-bot/test", r.Header.Get("User-Agent"))
+
+			fmt.Fprint(w, `{"symbol":"BTCUSD","price":"17000.00"}`)
+		}))
+		defer srv.Close()
+
+		e, err := exchange.NewBinance(
+			exchange.BinanceDomainUS,
+			exchange.WithBaseURL(srv.URL),
+			exchange.WithUserAgent("trading-bot/test"),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, srv.URL, e.BaseURL)
+
+		price, err := e.GetLastPrice(context.Background(), trading.BTCUSD)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "17000.00", price)
+	})
+
+	t.Run("requests to a hung exchange time out", func(t *testing.T) {
+		done := make(chan struct{})
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		}))
+		defer srv.Close()
+		defer close(done)
+
+		e, err := exchange.NewBinance(
+			exchange.BinanceDomainUS,
+			exchange.WithBaseURL(srv.URL),
+			exchange.WithHTTPClient(srv.Client()),
+			exchange.WithTimeout(time.Millisecond*50),
+		)
+		assert.NoError(t, err)
+
+		_, err = e.GetLastPrice(context.Background(), trading.BTCUSD)
+
+		assert.Error(t, err)
+		assert.Zero(t, srv.Client().Timeout, "the given client should not be modified")
+	})
+}
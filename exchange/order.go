@@ -1,6 +1,8 @@
 package exchange
 
 import (
+	"time"
+
 	"github.com/project-code-io/crypto-trading-bot-go/order"
 	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
@@ -11,4 +13,32 @@ type Order struct {
 	Pair     trading.Pair
 	Side     order.Side
 	ClientID string
+	BaseSize trading.Amount
+	Price    trading.Amount
+
+	// Status is the state of the order when it was last seen, which is empty
+	// if the exchange did not report it, such as in the response to placing
+	// an order on coinbase.
+	Status order.Status
+
+	// FilledSize is the amount of the base asset that has been filled, at
+	// the AveragePrice.
+	FilledSize   trading.Amount
+	AveragePrice trading.Amount
+
+	// Fee is the total of the fees charged on the fills of the order, in the
+	// FeeAsset. Exchanges that only report fees on fills leave it as zero.
+	Fee      trading.Amount
+	FeeAsset trading.Asset
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrderList represents linked orders placed on the exchange, such as the legs
+// of an OCO order.
+type OrderList struct {
+	ID       string
+	ClientID string
+	Orders   []Order
 }
@@ -0,0 +1,517 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// PriceFeed represents a source of prices that the paper exchange matches
+// orders against. Any of the other exchanges can be used as a price feed.
+type PriceFeed interface {
+	GetLastPrice(ctx context.Context, p trading.Pair) (string, error)
+}
+
+// ErrPriceFeedEnded describes an error in which a recorded price feed has
+// no more prices to replay.
+var ErrPriceFeedEnded = errors.New("price feed has ended")
+
+// RecordedPrices is a price feed that replays a recorded series of prices
+// for each pair. Every call to GetLastPrice returns the next price in the
+// series of the pair.
+type RecordedPrices struct {
+	mu     sync.Mutex
+	prices map[trading.Pair][]string
+}
+
+// NewRecordedPrices creates a price feed that replays the given prices.
+func NewRecordedPrices(prices map[trading.Pair][]string) *RecordedPrices {
+	copied := make(map[trading.Pair][]string, len(prices))
+
+	for p, series := range prices {
+		copied[p] = append([]string(nil), series...)
+	}
+
+	return &RecordedPrices{
+		prices: copied,
+	}
+}
+
+// GetLastPrice returns the next recorded price of the pair, or
+// ErrPriceFeedEnded once all of the prices have been replayed.
+func (f *RecordedPrices) GetLastPrice(ctx context.Context, p trading.Pair) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	series := f.prices[p]
+	if len(series) == 0 {
+		return "", ErrPriceFeedEnded
+	}
+
+	f.prices[p] = series[1:]
+
+	return series[0], nil
+}
+
+// PaperOption allows for overriding the defaults of the paper exchange.
+type PaperOption func(e *Paper)
+
+// WithPaperBalance sets the starting balance of an asset.
+func WithPaperBalance(asset trading.Asset, amount trading.Amount) PaperOption {
+	return func(e *Paper) {
+		e.balances[asset] = amount
+	}
+}
+
+// WithPaperFees sets the fees that are charged on fills, in basis points of
+// the quote value of the fill. Maker fees are charged on resting orders and
+// taker fees on orders that match as soon as they are placed.
+func WithPaperFees(makerBps int64, takerBps int64) PaperOption {
+	return func(e *Paper) {
+		e.makerFeeBps = makerBps
+		e.takerFeeBps = takerBps
+	}
+}
+
+// WithPaperClock overrides the clock that is used to timestamp fills and to
+// expire orders. Use this method when running against recorded prices.
+func WithPaperClock(now func() time.Time) PaperOption {
+	return func(e *Paper) {
+		e.now = now
+	}
+}
+
+// Paper is an exchange that simulates trading without using real money. It
+// keeps the resting limit orders in memory and fills them against the prices
+// of a price feed, adjusting the balances for each fill.
+type Paper struct {
+	mu sync.Mutex
+
+	feed        PriceFeed
+	now         func() time.Time
+	makerFeeBps int64
+	takerFeeBps int64
+
+	balances   map[trading.Asset]trading.Amount
+	lastPrices map[trading.Pair]trading.Amount
+	orders     map[string]*paperOrder
+	closed     map[string]Order
+	fills      []Fill
+	nextID     int64
+}
+
+type paperOrder struct {
+	order   Order
+	price   trading.Amount
+	size    trading.Amount
+	hold    trading.Amount
+	expires *time.Time
+	seq     int64
+}
+
+// holdAsset returns the asset that is put on hold for the order, which is the
+// quote asset for buy orders and the base asset for sell orders.
+func (o *paperOrder) holdAsset() trading.Asset {
+	if o.order.Side == order.SideBuy {
+		return o.order.Pair.Quote
+	}
+
+	return o.order.Pair.Base
+}
+
+// basisPointScale is the scale of an amount of basis points as a fraction
+// of a whole.
+const basisPointScale = 4
+
+// NewPaper acts as the default constructor for the Paper exchange type. The
+// feed is used as the source of the last price of each pair.
+func NewPaper(feed PriceFeed, opts ...PaperOption) *Paper {
+	e := &Paper{
+		feed:       feed,
+		now:        time.Now,
+		balances:   make(map[trading.Asset]trading.Amount),
+		lastPrices: make(map[trading.Pair]trading.Amount),
+		orders:     make(map[string]*paperOrder),
+		closed:     make(map[string]Order),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// GetLastPrice obtains the last price of the pair from the price feed and
+// matches the resting orders of the pair against it.
+func (e *Paper) GetLastPrice(ctx context.Context, p trading.Pair) (string, error) {
+	price, err := e.feed.GetLastPrice(ctx, p)
+	if err != nil {
+		return "", fmt.Errorf("get price from feed: %w", err)
+	}
+
+	if err := e.SetPrice(p, price); err != nil {
+		return "", err
+	}
+
+	return price, nil
+}
+
+// SetPrice sets the last price of the pair and matches the resting orders
+// of the pair against it. Use this method to drive the exchange directly,
+// such as when replaying historical data.
+func (e *Paper) SetPrice(p trading.Pair, price string) error {
+	last, err := trading.ParseAmount(price)
+	if err != nil {
+		return fmt.Errorf("parse price: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.lastPrices[p] = last
+
+	for _, o := range e.sortedOrders() {
+		if o.order.Pair != p {
+			continue
+		}
+
+		switch {
+		case e.isExpired(o):
+			e.removeOrder(o, order.StatusExpired)
+		case o.order.Side == order.SideBuy && last.Cmp(o.price) <= 0,
+			o.order.Side == order.SideSell && last.Cmp(o.price) >= 0:
+			e.fill(o, o.price, e.makerFeeBps)
+		}
+	}
+
+	return nil
+}
+
+// CreateLimitOrder places a limit order on the paper exchange. Orders that
+// would match the last price are filled straight away at the last price,
+// unless they are post only, in which case they are rejected. Immediate or
+// cancel and fill or kill orders that would not match are rejected, as no
+// part of them could be filled.
+func (e *Paper) CreateLimitOrder(ctx context.Context, o order.Limit) (Order, error) {
+	if err := o.Validate(); err != nil {
+		return Order{}, fmt.Errorf("%v: %w", err, ErrInvalidOrder)
+	}
+
+	price := o.Pair.Quote.Round(o.Price, trading.RoundDown)
+	size := o.Pair.Base.Round(o.BaseSize, trading.RoundDown)
+
+	if price.Sign() <= 0 || size.Sign() <= 0 {
+		return Order{}, fmt.Errorf("price and size must be positive: %w", ErrInvalidOrder)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if o.Expires != nil && !o.Expires.After(e.now()) {
+		return Order{}, fmt.Errorf("order has already expired: %w", ErrInvalidOrder)
+	}
+
+	last, hasPrice := e.lastPrices[o.Pair]
+	marketable := hasPrice && (o.Side == order.SideBuy && price.Cmp(last) >= 0 ||
+		o.Side == order.SideSell && price.Cmp(last) <= 0)
+
+	if marketable && o.PostOnly {
+		return Order{}, fmt.Errorf("post only order would match immediately: %w", ErrInvalidOrder)
+	}
+
+	if tif := o.EffectiveTimeInForce(); !marketable && (tif == order.TimeInForceIOC || tif == order.TimeInForceFOK) {
+		return Order{}, fmt.Errorf("%s order cannot be filled immediately: %w", tif, ErrInvalidOrder)
+	}
+
+	po, err := e.addOrder(o, price, size)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if marketable {
+		e.fill(po, last, e.takerFeeBps)
+	}
+
+	return po.order, nil
+}
+
+// CreateMarketOrder fills a market order on the paper exchange straight away
+// at the last price. Orders sized in the quote asset are converted to a base
+// size at the last price. Orders with a price limit that the last price is
+// beyond are rejected, as none of the order could be filled.
+func (e *Paper) CreateMarketOrder(ctx context.Context, o order.Market) (Order, error) {
+	if err := o.Validate(); err != nil {
+		return Order{}, fmt.Errorf("%v: %w", err, ErrInvalidOrder)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	last, hasPrice := e.lastPrices[o.Pair]
+	if !hasPrice {
+		return Order{}, fmt.Errorf("no price for %s/%s: %w", o.Pair.Base, o.Pair.Quote, ErrInvalidOrder)
+	}
+
+	if o.PriceLimit.Sign() > 0 && (o.Side == order.SideBuy && last.Cmp(o.PriceLimit) > 0 ||
+		o.Side == order.SideSell && last.Cmp(o.PriceLimit) < 0) {
+		return Order{}, fmt.Errorf("last price %s is beyond the price limit: %w", last, ErrInvalidOrder)
+	}
+
+	size := o.Pair.Base.Round(o.BaseSize, trading.RoundDown)
+
+	if !o.QuoteSize.IsZero() {
+		// The last price is positive, so there is no division by zero.
+		size, _ = o.QuoteSize.Div(last, o.Pair.Base.Decimals(), trading.RoundDown)
+	}
+
+	if size.Sign() <= 0 {
+		return Order{}, fmt.Errorf("size must be positive: %w", ErrInvalidOrder)
+	}
+
+	po, err := e.addOrder(order.Limit{ClientID: o.ClientID, Pair: o.Pair, Side: o.Side, BaseSize: size}, last, size)
+	if err != nil {
+		return Order{}, err
+	}
+
+	e.fill(po, last, e.takerFeeBps)
+
+	return po.order, nil
+}
+
+// addOrder places the funds needed by the order on hold and adds it to the
+// resting orders.
+func (e *Paper) addOrder(o order.Limit, price trading.Amount, size trading.Amount) (*paperOrder, error) {
+	holdAsset, hold := o.Pair.Base, size
+
+	if o.Side == order.SideBuy {
+		value := o.Pair.Value(price, size)
+		holdAsset, hold = o.Pair.Quote, value.Add(e.fee(o.Pair, value, max64(e.makerFeeBps, e.takerFeeBps)))
+	}
+
+	if e.balances[holdAsset].Cmp(hold) < 0 {
+		return nil, fmt.Errorf("%s balance too low: %w", holdAsset, ErrInsufficientFunds)
+	}
+
+	e.balances[holdAsset] = e.balances[holdAsset].Sub(hold)
+	e.nextID++
+
+	now := e.now()
+
+	po := &paperOrder{
+		order: Order{
+			ID:        strconv.FormatInt(e.nextID, 10),
+			Pair:      o.Pair,
+			Side:      o.Side,
+			ClientID:  o.ClientID,
+			BaseSize:  size,
+			Price:     price,
+			Status:    order.StatusNew,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		price:   price,
+		size:    size,
+		hold:    hold,
+		expires: o.Expires,
+		seq:     e.nextID,
+	}
+
+	e.orders[po.order.ID] = po
+
+	return po, nil
+}
+
+// fill executes the whole of the order at the given price, releasing the
+// hold on the funds and settling the balances.
+func (e *Paper) fill(o *paperOrder, price trading.Amount, feeBps int64) {
+	pair := o.order.Pair
+	value := pair.Value(price, o.size)
+	fee := e.fee(pair, value, feeBps)
+
+	o.order.FilledSize, o.order.AveragePrice = o.size, price
+	o.order.Fee, o.order.FeeAsset = fee, pair.Quote
+
+	e.removeOrder(o, order.StatusFilled)
+
+	if o.order.Side == order.SideBuy {
+		e.balances[pair.Quote] = e.balances[pair.Quote].Sub(value.Add(fee))
+		e.balances[pair.Base] = e.balances[pair.Base].Add(o.size)
+	} else {
+		e.balances[pair.Base] = e.balances[pair.Base].Sub(o.size)
+		e.balances[pair.Quote] = e.balances[pair.Quote].Add(value.Sub(fee))
+	}
+
+	e.fills = append(e.fills, Fill{
+		ID:       strconv.Itoa(len(e.fills) + 1),
+		OrderID:  o.order.ID,
+		ClientID: o.order.ClientID,
+		Pair:     pair,
+		Side:     o.order.Side,
+		Price:    price,
+		Size:     o.size,
+		Fee:      fee,
+		FeeAsset: pair.Quote,
+		Time:     e.now(),
+	})
+}
+
+// removeOrder removes the order from the resting orders with the status that
+// it ended with, and returns the funds on hold to the balance.
+func (e *Paper) removeOrder(o *paperOrder, status order.Status) {
+	e.balances[o.holdAsset()] = e.balances[o.holdAsset()].Add(o.hold)
+
+	o.order.Status, o.order.UpdatedAt = status, e.now()
+
+	delete(e.orders, o.order.ID)
+	e.closed[o.order.ID] = o.order
+}
+
+func (e *Paper) isExpired(o *paperOrder) bool {
+	return o.expires != nil && !o.expires.After(e.now())
+}
+
+// fee returns the fee on the value at the rate in basis points, truncated to
+// the decimal places of the quote asset.
+func (e *Paper) fee(p trading.Pair, value trading.Amount, bps int64) trading.Amount {
+	return p.Quote.Round(value.Mul(trading.NewAmount(bps, basisPointScale)), trading.RoundDown)
+}
+
+// sortedOrders returns the resting orders in the order they were placed.
+func (e *Paper) sortedOrders() []*paperOrder {
+	orders := make([]*paperOrder, 0, len(e.orders))
+
+	for _, o := range e.orders {
+		orders = append(orders, o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].seq < orders[j].seq
+	})
+
+	return orders
+}
+
+// CancelOrders cancels the resting orders with the given IDs. Orders which
+// are no longer open are ignored as there is nothing to cancel.
+func (e *Paper) CancelOrders(ctx context.Context, orderIDs ...string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, id := range orderIDs {
+		if o, exists := e.orders[id]; exists {
+			e.removeOrder(o, order.StatusCancelled)
+		}
+	}
+
+	return nil
+}
+
+// ListOpenOrders returns the resting orders which have not yet expired, in
+// the order they were placed.
+func (e *Paper) ListOpenOrders(ctx context.Context) ([]Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	orders := make([]Order, 0, len(e.orders))
+
+	for _, o := range e.sortedOrders() {
+		if e.isExpired(o) {
+			e.removeOrder(o, order.StatusExpired)
+			continue
+		}
+
+		orders = append(orders, o.order)
+	}
+
+	return orders, nil
+}
+
+// GetOrder returns the order with the given ID, whether it is open or not.
+// ErrUnknownOrder is returned if there is no such order.
+func (e *Paper) GetOrder(ctx context.Context, p trading.Pair, orderID string) (Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if o, exists := e.orders[orderID]; exists {
+		if !e.isExpired(o) {
+			return o.order, nil
+		}
+
+		e.removeOrder(o, order.StatusExpired)
+	}
+
+	if o, exists := e.closed[orderID]; exists {
+		return o, nil
+	}
+
+	return Order{}, fmt.Errorf("order %s: %w", orderID, ErrUnknownOrder)
+}
+
+// GetBalance returns the balance of the asset that is not on hold for open
+// orders.
+func (e *Paper) GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.balances[asset], nil
+}
+
+// Balances returns the total balance of every asset, including the funds
+// that are on hold for open orders.
+func (e *Paper) Balances() map[trading.Asset]trading.Amount {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	balances := make(map[trading.Asset]trading.Amount, len(e.balances))
+
+	for asset, amount := range e.balances {
+		balances[asset] = amount
+	}
+
+	for _, o := range e.orders {
+		balances[o.holdAsset()] = balances[o.holdAsset()].Add(o.hold)
+	}
+
+	return balances
+}
+
+// ListFills returns the fills on the pair since the given time, oldest
+// first.
+func (e *Paper) ListFills(ctx context.Context, p trading.Pair, since time.Time) ([]Fill, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	fills := make([]Fill, 0)
+
+	for _, f := range e.fills {
+		if f.Pair == p && !f.Time.Before(since) {
+			fills = append(fills, f)
+		}
+	}
+
+	return fills, nil
+}
+
+// Fills returns every fill that has happened on the exchange, oldest first.
+func (e *Paper) Fills() []Fill {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]Fill(nil), e.fills...)
+}
+
+func max64(a int64, b int64) int64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,545 @@
+package exchange_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func newPaper(prices []string, opts ...exchange.PaperOption) *exchange.Paper {
+	feed := exchange.NewRecordedPrices(map[trading.Pair][]string{
+		trading.BTCUSD: prices,
+	})
+
+	opts = append([]exchange.PaperOption{
+		exchange.WithPaperBalance(trading.USD, trading.MustParseAmount("1000")),
+		exchange.WithPaperBalance(trading.BTC, trading.MustParseAmount("1")),
+		exchange.WithPaperFees(10, 20),
+	}, opts...)
+
+	return exchange.NewPaper(feed, opts...)
+}
+
+func balances(t *testing.T, e *exchange.Paper) (trading.Amount, trading.Amount) {
+	t.Helper()
+
+	usd, err := e.GetBalance(context.Background(), trading.USD)
+	assert.NoError(t, err)
+
+	btc, err := e.GetBalance(context.Background(), trading.BTC)
+	assert.NoError(t, err)
+
+	return usd, btc
+}
+
+func TestPaperRestingOrderFills(t *testing.T) {
+	ctx := context.Background()
+	e := newPaper([]string{"20000.00", "19000.00", "17999.99"})
+
+	_, err := e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	o, err := e.CreateLimitOrder(ctx, order.Limit{
+		ClientID: "foobar",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("18000"),
+		PostOnly: true,
+	})
+	assert.NoError(t, err)
+
+	// The 180.00 value of the order plus the larger of the fees is on hold.
+	usd, _ := balances(t, e)
+	assert.Equal(t, trading.MustParseAmount("819.64"), usd)
+
+	open, err := e.ListOpenOrders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []exchange.Order{o}, open)
+
+	_, err = e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+	assert.Empty(t, e.Fills(), "order should not fill above its price")
+
+	_, err = e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	fills := e.Fills()
+	assert.Len(t, fills, 1)
+	assert.Equal(t, o.ID, fills[0].OrderID)
+	assert.Equal(t, "foobar", fills[0].ClientID)
+	assert.Equal(t, trading.MustParseAmount("18000"), fills[0].Price)
+	assert.Equal(t, trading.MustParseAmount("0.01"), fills[0].Size)
+	assert.Equal(t, trading.MustParseAmount("0.18"), fills[0].Fee)
+	assert.Equal(t, trading.USD, fills[0].FeeAsset)
+
+	usd, btc := balances(t, e)
+	assert.Equal(t, trading.MustParseAmount("819.82"), usd)
+	assert.Equal(t, trading.MustParseAmount("1.01"), btc)
+
+	open, err = e.ListOpenOrders(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, open)
+}
+
+func TestPaperMarketableOrder(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		input    order.Limit
+		wantsErr error
+		usd      trading.Amount
+		btc      trading.Amount
+	}{
+		{
+			name: "post only order that would match is rejected",
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+				Price:    trading.MustParseAmount("19000"),
+				PostOnly: true,
+			},
+			wantsErr: exchange.ErrInvalidOrder,
+			usd:      trading.MustParseAmount("1000"),
+			btc:      trading.MustParseAmount("1"),
+		},
+		{
+			name: "order that would match is filled at the last price as a taker",
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+				Price:    trading.MustParseAmount("19000"),
+			},
+			usd: trading.MustParseAmount("10980"),
+			btc: trading.MustParseAmount("0.5"),
+		},
+		{
+			name: "order without the funds is rejected",
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("2"),
+				Price:    trading.MustParseAmount("21000"),
+			},
+			wantsErr: exchange.ErrInsufficientFunds,
+			usd:      trading.MustParseAmount("1000"),
+			btc:      trading.MustParseAmount("1"),
+		},
+		{
+			name: "immediate or cancel order that would match is filled",
+			input: order.Limit{
+				Pair:        trading.BTCUSD,
+				Side:        order.SideSell,
+				BaseSize:    trading.MustParseAmount("0.5"),
+				Price:       trading.MustParseAmount("19000"),
+				TimeInForce: order.TimeInForceIOC,
+			},
+			usd: trading.MustParseAmount("10980"),
+			btc: trading.MustParseAmount("0.5"),
+		},
+		{
+			name: "fill or kill order that would not match is rejected",
+			input: order.Limit{
+				Pair:        trading.BTCUSD,
+				Side:        order.SideSell,
+				BaseSize:    trading.MustParseAmount("0.5"),
+				Price:       trading.MustParseAmount("21000"),
+				TimeInForce: order.TimeInForceFOK,
+			},
+			wantsErr: exchange.ErrInvalidOrder,
+			usd:      trading.MustParseAmount("1000"),
+			btc:      trading.MustParseAmount("1"),
+		},
+		{
+			name: "order without a price is rejected",
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("1"),
+			},
+			wantsErr: exchange.ErrInvalidOrder,
+			usd:      trading.MustParseAmount("1000"),
+			btc:      trading.MustParseAmount("1"),
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			e := newPaper([]string{"20000.00"})
+
+			_, err := e.GetLastPrice(ctx, trading.BTCUSD)
+			assert.NoError(t, err)
+
+			_, err = e.CreateLimitOrder(ctx, tt.input)
+			assert.ErrorIs(t, err, tt.wantsErr)
+
+			usd, btc := balances(t, e)
+			assert.Equal(t, tt.usd, usd)
+			assert.Equal(t, tt.btc, btc)
+		})
+	}
+}
+
+func TestPaperMarketOrder(t *testing.T) {
+	type want struct {
+		err  error
+		usd  trading.Amount
+		btc  trading.Amount
+		size trading.Amount
+	}
+
+	testCases := []struct {
+		name  string
+		input order.Market
+		wants want
+	}{
+		{
+			name: "buy by quote size fills at the last price with the taker fee",
+			input: order.Market{
+				Pair:      trading.BTCUSD,
+				Side:      order.SideBuy,
+				QuoteSize: trading.MustParseAmount("200"),
+			},
+			wants: want{
+				usd:  trading.MustParseAmount("799.6"),
+				btc:  trading.MustParseAmount("1.01"),
+				size: trading.MustParseAmount("0.01"),
+			},
+		},
+		{
+			name: "sell by base size",
+			input: order.Market{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.5"),
+			},
+			wants: want{
+				usd:  trading.MustParseAmount("10980"),
+				btc:  trading.MustParseAmount("0.5"),
+				size: trading.MustParseAmount("0.5"),
+			},
+		},
+		{
+			name: "sell within the price limit",
+			input: order.Market{
+				Pair:       trading.BTCUSD,
+				Side:       order.SideSell,
+				BaseSize:   trading.MustParseAmount("0.5"),
+				PriceLimit: trading.MustParseAmount("19900"),
+			},
+			wants: want{
+				usd:  trading.MustParseAmount("10980"),
+				btc:  trading.MustParseAmount("0.5"),
+				size: trading.MustParseAmount("0.5"),
+			},
+		},
+		{
+			name: "buy beyond the price limit",
+			input: order.Market{
+				Pair:       trading.BTCUSD,
+				Side:       order.SideBuy,
+				BaseSize:   trading.MustParseAmount("0.01"),
+				PriceLimit: trading.MustParseAmount("19999.99"),
+			},
+			wants: want{
+				err: exchange.ErrInvalidOrder,
+				usd: trading.MustParseAmount("1000"),
+				btc: trading.MustParseAmount("1"),
+			},
+		},
+		{
+			name: "buy beyond the balance",
+			input: order.Market{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("1"),
+			},
+			wants: want{
+				err: exchange.ErrInsufficientFunds,
+				usd: trading.MustParseAmount("1000"),
+				btc: trading.MustParseAmount("1"),
+			},
+		},
+		{
+			name: "both sizes",
+			input: order.Market{
+				Pair:      trading.BTCUSD,
+				Side:      order.SideBuy,
+				BaseSize:  trading.MustParseAmount("0.01"),
+				QuoteSize: trading.MustParseAmount("200"),
+			},
+			wants: want{
+				err: exchange.ErrInvalidOrder,
+				usd: trading.MustParseAmount("1000"),
+				btc: trading.MustParseAmount("1"),
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			e := newPaper([]string{"20000.00"})
+
+			_, err := e.GetLastPrice(ctx, trading.BTCUSD)
+			assert.NoError(t, err)
+
+			_, err = e.CreateMarketOrder(ctx, tt.input)
+			assert.ErrorIs(t, err, tt.wants.err)
+
+			usd, btc := balances(t, e)
+			assert.Equal(t, tt.wants.usd, usd)
+			assert.Equal(t, tt.wants.btc, btc)
+
+			open, err := e.ListOpenOrders(ctx)
+			assert.NoError(t, err)
+			assert.Empty(t, open)
+
+			if tt.wants.err != nil {
+				assert.Empty(t, e.Fills())
+				return
+			}
+
+			fills := e.Fills()
+			assert.Len(t, fills, 1)
+			assert.Equal(t, trading.MustParseAmount("20000"), fills[0].Price)
+			assert.Equal(t, tt.wants.size, fills[0].Size)
+		})
+	}
+}
+
+func TestPaperMarketOrderWithoutPrice(t *testing.T) {
+	e := newPaper(nil)
+
+	_, err := e.CreateMarketOrder(context.Background(), order.Market{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+	})
+
+	assert.ErrorIs(t, err, exchange.ErrInvalidOrder)
+}
+
+func TestPaperCancelOrders(t *testing.T) {
+	ctx := context.Background()
+	e := newPaper([]string{"20000.00", "17000.00"})
+
+	_, err := e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	o, err := e.CreateLimitOrder(ctx, order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("18000"),
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, e.CancelOrders(ctx, o.ID, "unknown"))
+
+	_, err = e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	usd, btc := balances(t, e)
+	assert.Equal(t, trading.MustParseAmount("1000"), usd)
+	assert.Equal(t, trading.MustParseAmount("1"), btc)
+	assert.Empty(t, e.Fills())
+}
+
+func TestPaperExpiredOrders(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Minute)
+
+	e := newPaper([]string{"20000.00", "17000.00"}, exchange.WithPaperClock(func() time.Time {
+		return now
+	}))
+
+	_, err := e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	_, err = e.CreateLimitOrder(ctx, order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("18000"),
+		Expires:  &expires,
+	})
+	assert.NoError(t, err)
+
+	now = now.Add(time.Minute)
+
+	_, err = e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+	assert.Empty(t, e.Fills(), "expired order should not fill")
+
+	usd, _ := balances(t, e)
+	assert.Equal(t, trading.MustParseAmount("1000"), usd)
+
+	_, err = e.CreateLimitOrder(ctx, order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("18000"),
+		Expires:  &expires,
+	})
+	assert.ErrorIs(t, err, exchange.ErrInvalidOrder)
+}
+
+func TestRecordedPrices(t *testing.T) {
+	ctx := context.Background()
+	feed := exchange.NewRecordedPrices(map[trading.Pair][]string{
+		trading.BTCUSD: {"1", "2"},
+	})
+
+	for _, want := range []string{"1", "2"} {
+		price, err := feed.GetLastPrice(ctx, trading.BTCUSD)
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, price)
+	}
+
+	_, err := feed.GetLastPrice(ctx, trading.BTCUSD)
+	assert.ErrorIs(t, err, exchange.ErrPriceFeedEnded)
+
+	_, err = feed.GetLastPrice(ctx, trading.ETHUSD)
+	assert.ErrorIs(t, err, exchange.ErrPriceFeedEnded)
+}
+
+func TestPaperLargeBalances(t *testing.T) {
+	ctx := context.Background()
+	feed := exchange.NewRecordedPrices(map[trading.Pair][]string{
+		trading.ETHUSD: {"1850.25"},
+	})
+
+	e := exchange.NewPaper(feed,
+		exchange.WithPaperBalance(trading.ETH, trading.MustParseAmount("250000.123456789012345678")),
+		exchange.WithPaperFees(10, 20),
+	)
+
+	_, err := e.GetLastPrice(ctx, trading.ETHUSD)
+	assert.NoError(t, err)
+
+	_, err = e.CreateLimitOrder(ctx, order.Limit{
+		Pair:     trading.ETHUSD,
+		Side:     order.SideSell,
+		BaseSize: trading.MustParseAmount("10000.000000000000000001"),
+		Price:    trading.MustParseAmount("1800"),
+	})
+	assert.NoError(t, err)
+
+	eth, err := e.GetBalance(ctx, trading.ETH)
+	assert.NoError(t, err)
+	assert.Equal(t, trading.MustParseAmount("240000.123456789012345677"), eth)
+
+	// 10000 ETH at 1850.25 is worth 18502500 USD, less a fee of 0.2%.
+	usd, err := e.GetBalance(ctx, trading.USD)
+	assert.NoError(t, err)
+	assert.Equal(t, trading.MustParseAmount("18465495"), usd)
+}
+
+func TestPaperGetOrder(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := start
+
+	e := newPaper([]string{"20000.00", "17999.99", "17999.99"}, exchange.WithPaperClock(func() time.Time { return now }))
+
+	_, err := e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	limit := order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.01"),
+		Price:    trading.MustParseAmount("18000"),
+	}
+
+	filled, err := e.CreateLimitOrder(ctx, limit)
+	assert.NoError(t, err)
+	assert.Equal(t, order.StatusNew, filled.Status)
+
+	cancelled, err := e.CreateLimitOrder(ctx, limit)
+	assert.NoError(t, err)
+	assert.NoError(t, e.CancelOrders(ctx, cancelled.ID))
+
+	expires := start.Add(time.Minute)
+	limit.Price = trading.MustParseAmount("17000")
+	limit.Expires = &expires
+
+	expired, err := e.CreateLimitOrder(ctx, limit)
+	assert.NoError(t, err)
+
+	now = expires
+
+	_, err = e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	res, err := e.GetOrder(ctx, trading.BTCUSD, filled.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.StatusFilled, res.Status)
+	assert.Equal(t, trading.MustParseAmount("0.01"), res.FilledSize)
+	assert.Equal(t, trading.MustParseAmount("18000"), res.AveragePrice)
+	assert.Equal(t, trading.MustParseAmount("0.18"), res.Fee)
+	assert.Equal(t, trading.USD, res.FeeAsset)
+	assert.Equal(t, start, res.CreatedAt)
+	assert.Equal(t, expires, res.UpdatedAt)
+
+	res, err = e.GetOrder(ctx, trading.BTCUSD, cancelled.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.StatusCancelled, res.Status)
+
+	res, err = e.GetOrder(ctx, trading.BTCUSD, expired.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, order.StatusExpired, res.Status)
+
+	_, err = e.GetOrder(ctx, trading.BTCUSD, "unknown")
+	assert.ErrorIs(t, err, exchange.ErrUnknownOrder)
+}
+
+func TestPaperListFills(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	now := start
+
+	e := newPaper([]string{"20000.00"}, exchange.WithPaperClock(func() time.Time { return now }))
+
+	_, err := e.GetLastPrice(ctx, trading.BTCUSD)
+	assert.NoError(t, err)
+
+	market := order.Market{Pair: trading.BTCUSD, Side: order.SideBuy, BaseSize: trading.MustParseAmount("0.001")}
+
+	_, err = e.CreateMarketOrder(ctx, market)
+	assert.NoError(t, err)
+
+	now = start.Add(time.Minute)
+
+	second, err := e.CreateMarketOrder(ctx, market)
+	assert.NoError(t, err)
+
+	fills, err := e.ListFills(ctx, trading.BTCUSD, now)
+	assert.NoError(t, err)
+	assert.Len(t, fills, 1)
+	assert.Equal(t, second.ID, fills[0].OrderID)
+
+	fills, err = e.ListFills(ctx, trading.BTCUSD, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, fills, 2)
+
+	fills, err = e.ListFills(ctx, trading.ETHUSD, time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, fills)
+}
@@ -0,0 +1,17 @@
+package generator
+
+import "time"
+
+// SystemClock is a clock that returns the time of the system.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (c *SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func (c *SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -1,12 +1,14 @@
 package generator
 
 import (
+	"crypto/rand"
 	"fmt"
 
 	"github.com/google/uuid"
 )
 
-// RandomUUIDGenerator generates a random ID based on a UUID.
+// RandomUUIDGenerator generates a random ID based on a UUID. Its IDs are too
+// long for the client IDs of binance, use RandomIDGenerator there instead.
 type RandomUUIDGenerator struct{}
 
 // GenerateID will generate a random ID with the prefix + ":" added before.
@@ -15,3 +17,44 @@ func (r *RandomUUIDGenerator) GenerateID(prefix string) string {
 
 	return fmt.Sprintf("%s:%s", prefix, &randomID)
 }
+
+// idLength is the length of the random part of the IDs that RandomIDGenerator
+// generates. Binance limits client IDs to 36 characters, which leaves room for
+// a prefix and for the suffixes that tag the legs of linked orders, such as
+// "-exit-tp".
+const idLength = 12
+
+// base62 holds the characters that the random part of an ID is made of, which
+// every exchange accepts in a client ID.
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// RandomIDGenerator generates a short random ID, which fits within the limits
+// that exchanges such as binance put on the length of client IDs.
+type RandomIDGenerator struct{}
+
+// GenerateID will generate a random ID of 12 base62 characters with the
+// prefix + ":" added before.
+func (r *RandomIDGenerator) GenerateID(prefix string) string {
+	id := make([]byte, 0, idLength)
+	buf := make([]byte, idLength)
+
+	for len(id) < idLength {
+		if _, err := rand.Read(buf); err != nil {
+			// crypto/rand only fails if the system has no source of
+			// randomness, which a UUID relies on as well.
+			panic(fmt.Errorf("read random bytes: %w", err))
+		}
+
+		for _, b := range buf {
+			// Bytes from 248 up are skipped so that each character is as
+			// likely as any other.
+			if b >= 248 || len(id) == idLength {
+				continue
+			}
+
+			id = append(id, base62[b%62])
+		}
+	}
+
+	return fmt.Sprintf("%s:%s", prefix, id)
+}
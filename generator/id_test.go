@@ -0,0 +1,28 @@
+package generator_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/generator"
+)
+
+func TestRandomIDGenerator(t *testing.T) {
+	// Binance accepts client IDs of up to 36 of these characters.
+	binanceClientID := regexp.MustCompile(`^[.A-Z:/a-z0-9_-]{1,36}$`)
+
+	gen := &generator.RandomIDGenerator{}
+	seen := make(map[string]bool)
+
+	for i := 0; i < 1000; i++ {
+		id := gen.GenerateID("go-trading-bot")
+
+		assert.Regexp(t, `^go-trading-bot:[0-9A-Za-z]{12}$`, id)
+		assert.Regexp(t, binanceClientID, id+"-exit-tp", "the legs of a bracket exit should fit on binance")
+		assert.False(t, seen[id], "ids should not repeat")
+
+		seen[id] = true
+	}
+}
@@ -0,0 +1,144 @@
+package indicator
+
+import (
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+)
+
+// SMA is the simple moving average of the closes of the last period candles.
+type SMA struct {
+	window *window
+	sum    float64
+}
+
+// NewSMA acts as the default constructor for the SMA type. A period below one
+// is treated as one.
+func NewSMA(n int) *SMA {
+	return &SMA{window: newWindow(period(n))}
+}
+
+// Update adds the close of the candle, if it is closed.
+func (s *SMA) Update(c marketdata.Candle) {
+	if c.Closed {
+		s.Add(c.Close.Float64())
+	}
+}
+
+// Add adds a value to the series that is averaged.
+func (s *SMA) Add(v float64) {
+	old, full := s.window.push(v)
+	if full {
+		s.sum -= old
+	}
+
+	s.sum += v
+}
+
+// Ready returns whether the average has a full period of values.
+func (s *SMA) Ready() bool {
+	return s.window.full
+}
+
+// Value returns the average, and whether it is ready.
+func (s *SMA) Value() (float64, bool) {
+	return s.sum / float64(len(s.window.values)), s.Ready()
+}
+
+// EMA is the exponential moving average of the closes of candles, which
+// weighs each close by 2 / (period + 1). The average starts from the simple
+// average of the first period closes.
+type EMA struct {
+	seed  *SMA
+	alpha float64
+	value float64
+	ready bool
+}
+
+// NewEMA acts as the default constructor for the EMA type. A period below one
+// is treated as one.
+func NewEMA(n int) *EMA {
+	n = period(n)
+
+	return &EMA{seed: NewSMA(n), alpha: 2 / float64(n+1)}
+}
+
+// Update adds the close of the candle, if it is closed.
+func (e *EMA) Update(c marketdata.Candle) {
+	if c.Closed {
+		e.Add(c.Close.Float64())
+	}
+}
+
+// Add adds a value to the series that is averaged.
+func (e *EMA) Add(v float64) {
+	if e.ready {
+		e.value += e.alpha * (v - e.value)
+		return
+	}
+
+	e.seed.Add(v)
+	e.value, e.ready = e.seed.Value()
+}
+
+// Ready returns whether the average has been seeded with a full period of
+// values.
+func (e *EMA) Ready() bool {
+	return e.ready
+}
+
+// Value returns the average, and whether it is ready.
+func (e *EMA) Value() (float64, bool) {
+	return e.value, e.ready
+}
+
+// WMA is the linearly weighted moving average of the closes of the last
+// period candles, in which the latest close has a weight of period and the
+// oldest a weight of one.
+type WMA struct {
+	window   *window
+	count    int
+	sum      float64
+	weighted float64
+}
+
+// NewWMA acts as the default constructor for the WMA type. A period below one
+// is treated as one.
+func NewWMA(n int) *WMA {
+	return &WMA{window: newWindow(period(n))}
+}
+
+// Update adds the close of the candle, if it is closed.
+func (w *WMA) Update(c marketdata.Candle) {
+	if c.Closed {
+		w.Add(c.Close.Float64())
+	}
+}
+
+// Add adds a value to the series that is averaged.
+func (w *WMA) Add(v float64) {
+	old, full := w.window.push(v)
+
+	// Once the window is full, every value already in it loses one weight,
+	// which drops the oldest value entirely.
+	if full {
+		w.weighted += float64(len(w.window.values))*v - w.sum
+		w.sum += v - old
+
+		return
+	}
+
+	w.count++
+	w.weighted += float64(w.count) * v
+	w.sum += v
+}
+
+// Ready returns whether the average has a full period of values.
+func (w *WMA) Ready() bool {
+	return w.window.full
+}
+
+// Value returns the average, and whether it is ready.
+func (w *WMA) Value() (float64, bool) {
+	n := float64(len(w.window.values))
+
+	return w.weighted / (n * (n + 1) / 2), w.Ready()
+}
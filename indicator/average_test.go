@@ -0,0 +1,60 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/indicator"
+)
+
+func TestSMA(t *testing.T) {
+	sma := indicator.NewSMA(10)
+
+	warmup, values := run(t, sma, sma.Value)
+
+	assert.Equal(t, 9, warmup)
+	assert.InDeltaSlice(t, []float64{
+		44.779000, 44.934000, 45.128000, 45.274000, 45.541000, 45.736000, 45.853000, 45.946000,
+		46.045000, 46.083000, 46.039000, 46.071000, 46.093000, 46.103000, 46.120000, 46.070000,
+		46.005000, 45.805000, 45.582000, 45.382000, 45.275000, 44.996000, 44.637000, 44.379000,
+	}, values, delta)
+}
+
+func TestSMAPeriodBelowOne(t *testing.T) {
+	sma := indicator.NewSMA(0)
+
+	sma.Add(3)
+	sma.Add(5)
+
+	value, ok := sma.Value()
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, value)
+}
+
+func TestEMA(t *testing.T) {
+	ema := indicator.NewEMA(10)
+
+	warmup, values := run(t, ema, ema.Value)
+
+	// The first value is the simple average of the first ten closes.
+	assert.Equal(t, 9, warmup)
+	assert.InDeltaSlice(t, []float64{
+		44.779000, 44.981000, 45.171727, 45.251413, 45.438429, 45.591442, 45.665725, 45.731957,
+		45.855238, 45.921558, 45.870366, 45.932117, 45.989914, 45.939021, 46.031926, 45.986121,
+		45.870463, 45.535833, 45.289318, 45.094897, 44.999461, 44.712286, 44.339143, 44.119299,
+	}, values, delta)
+}
+
+func TestWMA(t *testing.T) {
+	wma := indicator.NewWMA(10)
+
+	warmup, values := run(t, wma, wma.Value)
+
+	assert.Equal(t, 9, warmup)
+	assert.InDeltaSlice(t, []float64{
+		45.135636, 45.337636, 45.536909, 45.624545, 45.807455, 45.941818, 45.989818, 46.022000,
+		46.106364, 46.138182, 46.057636, 46.088727, 46.121273, 46.051636, 46.114727, 46.052909,
+		45.922000, 45.562909, 45.267455, 45.019818, 44.872182, 44.534909, 44.110182, 43.836182,
+	}, values, delta)
+}
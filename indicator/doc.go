@@ -0,0 +1,6 @@
+// Package indicator provides the technical indicators that strategies use,
+// such as moving averages, RSI and Bollinger Bands. The indicators are
+// streaming, each candle updates them in constant time rather than them being
+// computed again over the whole series. Values are float64, as they are
+// measures of the market rather than amounts that are traded.
+package indicator
@@ -0,0 +1,102 @@
+package indicator
+
+import (
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+)
+
+// Indicator represents an indicator that is updated with each closed candle
+// of a pair. Candles that are not closed are ignored, so that every Candle
+// event of a stream can be given.
+type Indicator interface {
+	Update(c marketdata.Candle)
+	Ready() bool
+}
+
+var (
+	_ Indicator = (*SMA)(nil)
+	_ Indicator = (*EMA)(nil)
+	_ Indicator = (*WMA)(nil)
+	_ Indicator = (*RSI)(nil)
+	_ Indicator = (*MACD)(nil)
+	_ Indicator = (*Bollinger)(nil)
+	_ Indicator = (*ATR)(nil)
+	_ Indicator = (*VWAP)(nil)
+	_ Indicator = (*Stochastic)(nil)
+	_ Indicator = (*OBV)(nil)
+)
+
+// period returns the period, treating a period below one as one.
+func period(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+// window holds the most recent values of a series, up to its size.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newWindow(size int) *window {
+	return &window{values: make([]float64, size)}
+}
+
+// push adds the value to the window. Once the window is full, it returns the
+// oldest value, which the value replaces.
+func (w *window) push(v float64) (float64, bool) {
+	old, full := w.values[w.next], w.full
+
+	w.values[w.next] = v
+	w.next++
+
+	if w.next == len(w.values) {
+		w.next = 0
+		w.full = true
+	}
+
+	return old, full
+}
+
+// extreme tracks the highest or lowest value of the most recent values of a
+// series. The values that can no longer be the extreme are dropped as values
+// are added, so that each value is added and dropped once.
+type extreme struct {
+	size    int
+	better  func(a float64, b float64) bool
+	count   int
+	indexes []int
+	values  []float64
+}
+
+func newExtreme(size int, better func(a float64, b float64) bool) *extreme {
+	return &extreme{size: size, better: better}
+}
+
+func (e *extreme) push(v float64) {
+	for n := len(e.values); n > 0 && !e.better(e.values[n-1], v); n-- {
+		e.values = e.values[:n-1]
+		e.indexes = e.indexes[:n-1]
+	}
+
+	e.values = append(e.values, v)
+	e.indexes = append(e.indexes, e.count)
+	e.count++
+
+	if e.indexes[0] <= e.count-1-e.size {
+		e.values = e.values[1:]
+		e.indexes = e.indexes[1:]
+	}
+}
+
+// value returns the extreme of the values in the window.
+func (e *extreme) value() float64 {
+	return e.values[0]
+}
+
+func higher(a float64, b float64) bool { return a > b }
+
+func lower(a float64, b float64) bool { return a < b }
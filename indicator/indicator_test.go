@@ -0,0 +1,132 @@
+package indicator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/indicator"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// delta is the tolerance of the golden values, which are rounded to six
+// decimal places.
+const delta = 1e-6
+
+var candleTime = time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+
+// prices holds the open, high, low, close and volume of hourly candles. The
+// closes are those of the RSI example of StockCharts, whose published values
+// the RSI matches to within their rounding.
+var prices = [][5]string{
+	{"44.00", "44.59", "43.90", "44.34", "1200"},
+	{"44.34", "44.44", "43.74", "44.09", "950"},
+	{"44.09", "44.45", "43.89", "44.15", "1100"},
+	{"44.15", "44.20", "43.56", "43.61", "1500"},
+	{"43.61", "44.53", "43.31", "44.33", "1300"},
+	{"44.33", "44.98", "44.18", "44.83", "800"},
+	{"44.83", "45.50", "44.58", "45.10", "1700"},
+	{"45.10", "45.52", "45.00", "45.42", "1250"},
+	{"45.42", "46.19", "45.22", "45.84", "900"},
+	{"45.84", "46.28", "45.54", "46.08", "1400"},
+	{"46.08", "46.13", "45.84", "45.89", "1000"},
+	{"45.89", "46.33", "45.74", "46.03", "1150"},
+	{"46.03", "46.18", "45.36", "45.61", "1600"},
+	{"45.61", "46.53", "45.51", "46.28", "1350"},
+	{"46.28", "46.38", "45.93", "46.28", "700"},
+	{"46.28", "46.48", "45.80", "46.00", "1050"},
+	{"46.00", "46.33", "45.95", "46.03", "1450"},
+	{"46.03", "46.46", "45.73", "46.41", "1200"},
+	{"46.41", "46.56", "46.07", "46.22", "980"},
+	{"46.22", "46.47", "45.24", "45.64", "1750"},
+	{"45.64", "46.31", "45.54", "46.21", "1300"},
+	{"46.21", "46.60", "46.01", "46.25", "1100"},
+	{"46.25", "46.45", "45.46", "45.71", "1550"},
+	{"45.71", "46.50", "45.66", "46.45", "1250"},
+	{"46.45", "46.75", "45.48", "45.78", "1650"},
+	{"45.78", "45.93", "45.20", "45.35", "1800"},
+	{"45.35", "45.75", "43.78", "44.03", "2100"},
+	{"44.03", "44.28", "43.93", "44.18", "1400"},
+	{"44.18", "44.42", "43.88", "44.22", "1200"},
+	{"44.22", "44.82", "44.17", "44.57", "1150"},
+	{"44.57", "44.62", "43.22", "43.42", "1900"},
+	{"43.42", "43.72", "42.51", "42.66", "2300"},
+	{"42.66", "43.28", "42.26", "43.13", "1600"},
+}
+
+// candles returns the closed candles of the prices.
+func candles() []marketdata.Candle {
+	candles := make([]marketdata.Candle, 0, len(prices))
+
+	for i, p := range prices {
+		candles = append(candles, marketdata.Candle{
+			Pair:     trading.BTCUSD,
+			Start:    candleTime.Add(time.Duration(i) * time.Hour),
+			Interval: time.Hour,
+			Open:     trading.MustParseAmount(p[0]),
+			High:     trading.MustParseAmount(p[1]),
+			Low:      trading.MustParseAmount(p[2]),
+			Close:    trading.MustParseAmount(p[3]),
+			Volume:   trading.MustParseAmount(p[4]),
+			Closed:   true,
+		})
+	}
+
+	return candles
+}
+
+// run updates the indicator with each of the candles. It returns the number
+// of candles that it took for the indicator to be ready, and its values from
+// then on.
+func run[T any](t *testing.T, ind indicator.Indicator, value func() (T, bool)) (int, []T) {
+	t.Helper()
+
+	var (
+		warmup int
+		values []T
+	)
+
+	for _, c := range candles() {
+		ind.Update(c)
+
+		v, ok := value()
+		assert.Equal(t, ind.Ready(), ok)
+
+		if !ok {
+			assert.Empty(t, values, "the indicator is no longer ready")
+
+			warmup++
+
+			continue
+		}
+
+		values = append(values, v)
+	}
+
+	return warmup, values
+}
+
+func TestOpenCandles(t *testing.T) {
+	indicators := []indicator.Indicator{
+		indicator.NewSMA(1),
+		indicator.NewEMA(1),
+		indicator.NewWMA(1),
+		indicator.NewRSI(1),
+		indicator.NewMACD(1, 1, 1),
+		indicator.NewBollinger(1, 2),
+		indicator.NewATR(1),
+		indicator.NewVWAP(0),
+		indicator.NewStochastic(1, 1, 1),
+		indicator.NewOBV(),
+	}
+
+	open := candles()[0]
+	open.Closed = false
+
+	for _, ind := range indicators {
+		ind.Update(open)
+		assert.False(t, ind.Ready(), "%T", ind)
+	}
+}
@@ -0,0 +1,224 @@
+package indicator
+
+import (
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+)
+
+const hundred = 100
+
+// RSI is the relative strength index of the closes of candles, using the
+// smoothing of Wilder. The first average gain and loss are the simple
+// averages of the first period changes, so it is ready after period + 1
+// closes.
+type RSI struct {
+	period int
+	count  int
+	last   float64
+	gain   float64
+	loss   float64
+}
+
+// NewRSI acts as the default constructor for the RSI type. A period below one
+// is treated as one.
+func NewRSI(n int) *RSI {
+	return &RSI{period: period(n)}
+}
+
+// Update adds the close of the candle, if it is closed.
+func (r *RSI) Update(c marketdata.Candle) {
+	if c.Closed {
+		r.Add(c.Close.Float64())
+	}
+}
+
+// Add adds a value to the series that the index measures.
+func (r *RSI) Add(v float64) {
+	r.count++
+
+	change := v - r.last
+	r.last = v
+
+	if r.count == 1 {
+		return
+	}
+
+	var gain, loss float64
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	n := float64(r.period)
+
+	if r.count <= r.period+1 {
+		r.gain += gain / n
+		r.loss += loss / n
+
+		return
+	}
+
+	r.gain = (r.gain*(n-1) + gain) / n
+	r.loss = (r.loss*(n-1) + loss) / n
+}
+
+// Ready returns whether the index has a full period of changes.
+func (r *RSI) Ready() bool {
+	return r.count > r.period
+}
+
+// Value returns the index, from 0 to 100, and whether it is ready. The index
+// is 100 when there have been no losses, and 50 when there have been neither
+// gains nor losses.
+func (r *RSI) Value() (float64, bool) {
+	switch {
+	case r.loss == 0 && r.gain == 0:
+		return hundred / 2, r.Ready()
+	case r.loss == 0:
+		return hundred, r.Ready()
+	}
+
+	return hundred - hundred/(1+r.gain/r.loss), r.Ready()
+}
+
+// MACDValue holds the lines of the MACD.
+type MACDValue struct {
+	// MACD is the fast average less the slow average.
+	MACD float64
+
+	// Signal is the average of the MACD over the signal period.
+	Signal float64
+
+	// Histogram is the MACD less the signal.
+	Histogram float64
+}
+
+// MACD is the moving average convergence divergence of the closes of
+// candles, which is the difference between a fast and a slow exponential
+// moving average. The signal line averages the difference from when the
+// slow average is ready.
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	macd   float64
+}
+
+// NewMACD acts as the default constructor for the MACD type. The periods that
+// are most commonly used are 12, 26 and 9.
+func NewMACD(fast int, slow int, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+// Update adds the close of the candle, if it is closed.
+func (m *MACD) Update(c marketdata.Candle) {
+	if c.Closed {
+		m.Add(c.Close.Float64())
+	}
+}
+
+// Add adds a value to the series that the lines follow.
+func (m *MACD) Add(v float64) {
+	m.fast.Add(v)
+	m.slow.Add(v)
+
+	fast, fastReady := m.fast.Value()
+	slow, slowReady := m.slow.Value()
+
+	if !fastReady || !slowReady {
+		return
+	}
+
+	m.macd = fast - slow
+	m.signal.Add(m.macd)
+}
+
+// Ready returns whether the signal line has been seeded.
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
+
+// Value returns the lines, and whether they are ready.
+func (m *MACD) Value() (MACDValue, bool) {
+	signal, ready := m.signal.Value()
+
+	return MACDValue{MACD: m.macd, Signal: signal, Histogram: m.macd - signal}, ready
+}
+
+// StochasticValue holds the lines of the stochastic oscillator, from 0 to
+// 100.
+type StochasticValue struct {
+	K float64
+	D float64
+}
+
+// Stochastic is the stochastic oscillator, which measures where the close of
+// a candle is within the range of the highs and lows of the last period
+// candles. %K is that measure averaged over the smoothing period, and %D is
+// %K averaged over the signal period. A smoothing period of one gives the
+// fast oscillator, and a longer one the slow oscillator.
+type Stochastic struct {
+	highs *extreme
+	lows  *extreme
+	count int
+	n     int
+	k     *SMA
+	d     *SMA
+}
+
+// NewStochastic acts as the default constructor for the Stochastic type. The
+// periods that are most commonly used are 14, 3 and 3. A period below one is
+// treated as one.
+func NewStochastic(n int, smooth int, signal int) *Stochastic {
+	n = period(n)
+
+	return &Stochastic{
+		highs: newExtreme(n, higher),
+		lows:  newExtreme(n, lower),
+		n:     n,
+		k:     NewSMA(smooth),
+		d:     NewSMA(signal),
+	}
+}
+
+// Update adds the candle, if it is closed. When the highs and lows of the
+// period are the same, the close is taken to be in the middle of the range.
+func (s *Stochastic) Update(c marketdata.Candle) {
+	if !c.Closed {
+		return
+	}
+
+	s.highs.push(c.High.Float64())
+	s.lows.push(c.Low.Float64())
+	s.count++
+
+	if s.count < s.n {
+		return
+	}
+
+	high, low := s.highs.value(), s.lows.value()
+
+	k := hundred / 2.0
+	if high > low {
+		k = hundred * (c.Close.Float64() - low) / (high - low)
+	}
+
+	s.k.Add(k)
+
+	if k, ready := s.k.Value(); ready {
+		s.d.Add(k)
+	}
+}
+
+// Ready returns whether both lines are ready.
+func (s *Stochastic) Ready() bool {
+	return s.d.Ready()
+}
+
+// Value returns the lines, and whether they are ready.
+func (s *Stochastic) Value() (StochasticValue, bool) {
+	k, _ := s.k.Value()
+	d, ready := s.d.Value()
+
+	return StochasticValue{K: k, D: d}, ready
+}
@@ -0,0 +1,142 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/indicator"
+)
+
+func TestRSI(t *testing.T) {
+	rsi := indicator.NewRSI(14)
+
+	warmup, values := run(t, rsi, rsi.Value)
+
+	assert.Equal(t, 14, warmup)
+	assert.InDeltaSlice(t, []float64{
+		70.464135, 66.249619, 66.480942, 69.346853, 66.294713, 57.915021, 62.880718, 63.208789,
+		56.011585, 62.339929, 54.670971, 50.386815, 40.019424, 41.492635, 41.902430, 45.499497,
+		37.322778, 33.090483, 37.788772,
+	}, values, delta)
+}
+
+func TestRSIWithoutLosses(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   []float64
+		expected float64
+	}{
+		{
+			name:     "only gains",
+			values:   []float64{1, 2, 4},
+			expected: 100,
+		},
+		{
+			name:     "no changes",
+			values:   []float64{3, 3, 3},
+			expected: 50,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			rsi := indicator.NewRSI(2)
+
+			for _, v := range tt.values {
+				rsi.Add(v)
+			}
+
+			value, ok := rsi.Value()
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestMACD(t *testing.T) {
+	macd := indicator.NewMACD(5, 10, 4)
+
+	warmup, values := run(t, macd, macd.Value)
+
+	// The signal line starts once the slow average is ready, after ten
+	// closes, and is ready three closes later.
+	assert.Equal(t, 12, warmup)
+
+	actual := make([]float64, 0, len(values)*3)
+	for _, v := range values {
+		actual = append(actual, v.MACD, v.Signal, v.Histogram)
+	}
+
+	assert.InDeltaSlice(t, []float64{
+		0.457722, 0.599333, -0.141611,
+		0.460994, 0.543997, -0.083003,
+		0.434840, 0.500334, -0.065494,
+		0.351796, 0.440919, -0.089123,
+		0.289724, 0.380441, -0.090717,
+		0.295883, 0.346618, -0.050735,
+		0.252522, 0.308980, -0.056457,
+		0.125688, 0.235663, -0.109975,
+		0.135252, 0.195499, -0.060247,
+		0.138332, 0.172632, -0.034300,
+		0.049810, 0.123503, -0.073693,
+		0.110628, 0.118353, -0.007725,
+		0.035581, 0.085244, -0.049663,
+		-0.072661, 0.022082, -0.094743,
+		-0.327299, -0.117670, -0.209629,
+		-0.423628, -0.240054, -0.183575,
+		-0.444437, -0.321807, -0.122630,
+		-0.375821, -0.343413, -0.032409,
+		-0.489860, -0.401991, -0.087868,
+		-0.637526, -0.496205, -0.141320,
+		-0.608221, -0.541011, -0.067209,
+	}, actual, delta)
+}
+
+func TestStochastic(t *testing.T) {
+	stochastic := indicator.NewStochastic(14, 3, 3)
+
+	warmup, values := run(t, stochastic, stochastic.Value)
+
+	assert.Equal(t, 17, warmup)
+
+	actual := make([]float64, 0, len(values)*2)
+	for _, v := range values {
+		actual = append(actual, v.K, v.D)
+	}
+
+	assert.InDeltaSlice(t, []float64{
+		88.095238, 88.060732,
+		88.819876, 87.888199,
+		78.507644, 85.140919,
+		72.271247, 79.866256,
+		68.579046, 73.119312,
+		62.253536, 67.701276,
+		66.055698, 65.629426,
+		53.097000, 60.468745,
+		44.803199, 54.651966,
+		17.952172, 38.617457,
+		10.520980, 24.425451,
+		12.233446, 13.568866,
+		18.294052, 13.682826,
+		15.693288, 15.406928,
+		11.934262, 15.307200,
+		9.526617, 12.384722,
+	}, actual, delta)
+}
+
+func TestStochasticFlatRange(t *testing.T) {
+	stochastic := indicator.NewStochastic(2, 1, 1)
+
+	c := candles()[0]
+	c.High, c.Low = c.Close, c.Close
+
+	stochastic.Update(c)
+	stochastic.Update(c)
+
+	value, ok := stochastic.Value()
+	assert.True(t, ok)
+	assert.Equal(t, indicator.StochasticValue{K: 50, D: 50}, value)
+}
@@ -0,0 +1,125 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+)
+
+// Bands holds the lines of the Bollinger Bands.
+type Bands struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+// Bollinger is the Bollinger Bands of the closes of the last period candles.
+// The middle band is their simple moving average, and the upper and lower
+// bands are a number of standard deviations above and below it.
+type Bollinger struct {
+	window *window
+	k      float64
+	sum    float64
+	sumSq  float64
+}
+
+// NewBollinger acts as the default constructor for the Bollinger type. The
+// period and deviations that are most commonly used are 20 and 2. A period
+// below one is treated as one.
+func NewBollinger(n int, deviations float64) *Bollinger {
+	return &Bollinger{window: newWindow(period(n)), k: deviations}
+}
+
+// Update adds the close of the candle, if it is closed.
+func (b *Bollinger) Update(c marketdata.Candle) {
+	if c.Closed {
+		b.Add(c.Close.Float64())
+	}
+}
+
+// Add adds a value to the series that the bands follow.
+func (b *Bollinger) Add(v float64) {
+	old, full := b.window.push(v)
+	if full {
+		b.sum -= old
+		b.sumSq -= old * old
+	}
+
+	b.sum += v
+	b.sumSq += v * v
+}
+
+// Ready returns whether the bands have a full period of values.
+func (b *Bollinger) Ready() bool {
+	return b.window.full
+}
+
+// Value returns the bands, and whether they are ready. The standard deviation
+// is that of the population of the period.
+func (b *Bollinger) Value() (Bands, bool) {
+	n := float64(len(b.window.values))
+	mean := b.sum / n
+
+	// The running sums can leave a variance slightly below zero when the
+	// values are all the same.
+	deviation := math.Sqrt(math.Max(b.sumSq/n-mean*mean, 0))
+
+	return Bands{
+		Upper:  mean + b.k*deviation,
+		Middle: mean,
+		Lower:  mean - b.k*deviation,
+	}, b.Ready()
+}
+
+// ATR is the average true range of candles, using the smoothing of Wilder.
+// The true range of a candle is its range extended to the close of the
+// candle before it. The first average is the simple average of the true
+// ranges of the first period candles.
+type ATR struct {
+	period    int
+	count     int
+	prevClose float64
+	value     float64
+}
+
+// NewATR acts as the default constructor for the ATR type. A period below one
+// is treated as one.
+func NewATR(n int) *ATR {
+	return &ATR{period: period(n)}
+}
+
+// Update adds the candle, if it is closed.
+func (a *ATR) Update(c marketdata.Candle) {
+	if !c.Closed {
+		return
+	}
+
+	high, low := c.High.Float64(), c.Low.Float64()
+
+	tr := high - low
+	if a.count > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(high-a.prevClose), math.Abs(low-a.prevClose)))
+	}
+
+	a.prevClose = c.Close.Float64()
+	a.count++
+
+	n := float64(a.period)
+
+	if a.count <= a.period {
+		a.value += tr / n
+		return
+	}
+
+	a.value = (a.value*(n-1) + tr) / n
+}
+
+// Ready returns whether the average has a full period of candles.
+func (a *ATR) Ready() bool {
+	return a.count >= a.period
+}
+
+// Value returns the average, and whether it is ready.
+func (a *ATR) Value() (float64, bool) {
+	return a.value, a.Ready()
+}
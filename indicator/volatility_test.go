@@ -0,0 +1,77 @@
+package indicator_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/indicator"
+)
+
+func TestBollinger(t *testing.T) {
+	bollinger := indicator.NewBollinger(10, 2)
+
+	warmup, values := run(t, bollinger, bollinger.Value)
+
+	assert.Equal(t, 9, warmup)
+
+	actual := make([]float64, 0, len(values)*3)
+	for _, v := range values {
+		actual = append(actual, v.Upper, v.Middle, v.Lower)
+	}
+
+	assert.InDeltaSlice(t, []float64{
+		46.323809, 44.779000, 43.234191,
+		46.579289, 44.934000, 43.288711,
+		46.786910, 45.128000, 43.469090,
+		46.815770, 45.274000, 43.732230,
+		46.719625, 45.541000, 44.362375,
+		46.668146, 45.736000, 44.803854,
+		46.569718, 45.853000, 45.136282,
+		46.460603, 45.946000, 45.431397,
+		46.493397, 46.045000, 45.596603,
+		46.519720, 46.083000, 45.646280,
+		46.550347, 46.039000, 45.527653,
+		46.581094, 46.071000, 45.560906,
+		46.613004, 46.093000, 45.572996,
+		46.588143, 46.103000, 45.617857,
+		46.639461, 46.120000, 45.600539,
+		46.613912, 46.070000, 45.526088,
+		46.700945, 46.005000, 45.309055,
+		47.177713, 45.805000, 44.432287,
+		47.192982, 45.582000, 43.971018,
+		47.118221, 45.382000, 43.645779,
+		47.065469, 45.275000, 43.484531,
+		46.976186, 44.996000, 43.015814,
+		46.863963, 44.637000, 42.410037,
+		46.646376, 44.379000, 42.111624,
+	}, actual, delta)
+}
+
+func TestBollingerFlat(t *testing.T) {
+	bollinger := indicator.NewBollinger(3, 2)
+
+	for i := 0; i < 5; i++ {
+		bollinger.Add(0.1)
+	}
+
+	value, ok := bollinger.Value()
+	assert.True(t, ok)
+	assert.InDelta(t, 0.1, value.Upper, delta)
+	assert.InDelta(t, 0.1, value.Lower, delta)
+}
+
+func TestATR(t *testing.T) {
+	atr := indicator.NewATR(14)
+
+	warmup, values := run(t, atr, atr.Value)
+
+	// The first candle has no close before it, so its true range is its
+	// range.
+	assert.Equal(t, 13, warmup)
+	assert.InDeltaSlice(t, []float64{
+		0.748571, 0.727245, 0.723870, 0.699308, 0.701500, 0.686393, 0.725222, 0.728421,
+		0.718533, 0.737924, 0.745215, 0.782700, 0.778935, 0.864012, 0.827296, 0.806775,
+		0.795577, 0.838750, 0.865268, 0.876320,
+	}, values, delta)
+}
@@ -0,0 +1,105 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+)
+
+// VWAP is the volume weighted average of the typical prices of candles, which
+// is the average of their high, low and close. The average is anchored to
+// the start of a session, such as a day, and starts over with each one.
+type VWAP struct {
+	session time.Duration
+	start   time.Time
+	value   float64
+	volume  float64
+}
+
+// NewVWAP acts as the default constructor for the VWAP type. Sessions are
+// aligned in the same way as candles, so a session of a day starts at
+// midnight UTC. A session of zero never starts over.
+func NewVWAP(session time.Duration) *VWAP {
+	return &VWAP{session: session}
+}
+
+// Update adds the candle, if it is closed.
+func (v *VWAP) Update(c marketdata.Candle) {
+	if !c.Closed {
+		return
+	}
+
+	if v.session > 0 {
+		if start := c.Start.UTC().Truncate(v.session); !start.Equal(v.start) {
+			v.start = start
+			v.value = 0
+			v.volume = 0
+		}
+	}
+
+	const parts = 3
+
+	typical := (c.High.Float64() + c.Low.Float64() + c.Close.Float64()) / parts
+	volume := c.Volume.Float64()
+
+	v.value += typical * volume
+	v.volume += volume
+}
+
+// Ready returns whether there has been volume in the session.
+func (v *VWAP) Ready() bool {
+	return v.volume > 0
+}
+
+// Value returns the average, and whether it is ready.
+func (v *VWAP) Value() (float64, bool) {
+	if !v.Ready() {
+		return 0, false
+	}
+
+	return v.value / v.volume, true
+}
+
+// OBV is the on balance volume of candles, the running total of the volume of
+// candles that close higher, less that of candles that close lower. It
+// starts from zero at the first candle.
+type OBV struct {
+	started   bool
+	prevClose float64
+	value     float64
+}
+
+// NewOBV acts as the default constructor for the OBV type.
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+// Update adds the candle, if it is closed.
+func (o *OBV) Update(c marketdata.Candle) {
+	if !c.Closed {
+		return
+	}
+
+	closePrice := c.Close.Float64()
+
+	switch {
+	case !o.started:
+		o.started = true
+	case closePrice > o.prevClose:
+		o.value += c.Volume.Float64()
+	case closePrice < o.prevClose:
+		o.value -= c.Volume.Float64()
+	}
+
+	o.prevClose = closePrice
+}
+
+// Ready returns whether there has been a candle.
+func (o *OBV) Ready() bool {
+	return o.started
+}
+
+// Value returns the volume, and whether it is ready.
+func (o *OBV) Value() (float64, bool) {
+	return o.value, o.started
+}
@@ -0,0 +1,52 @@
+package indicator_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/indicator"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestVWAP(t *testing.T) {
+	vwap := indicator.NewVWAP(12 * time.Hour)
+
+	warmup, values := run(t, vwap, vwap.Value)
+
+	// The average starts over with the candles at 12:00 and midnight.
+	assert.Zero(t, warmup)
+	assert.InDeltaSlice(t, []float64{
+		44.276667, 44.194186, 44.183744, 44.059404, 44.058815, 44.129416, 44.314444, 44.441854,
+		44.551885, 44.715579, 44.810064, 44.908784, 45.716667, 45.895141, 45.952968, 45.984326,
+		46.012385, 46.043016, 46.071289, 46.021296, 46.021148, 46.044551, 46.025636, 46.040172,
+		46.003333, 45.737246, 45.276667, 45.045683, 44.917239, 44.868118, 44.679003, 44.386704,
+		44.228113,
+	}, values, delta)
+}
+
+func TestVWAPWithoutVolume(t *testing.T) {
+	vwap := indicator.NewVWAP(0)
+
+	c := candles()[0]
+	c.Volume = trading.Amount{}
+
+	vwap.Update(c)
+
+	_, ok := vwap.Value()
+	assert.False(t, ok)
+}
+
+func TestOBV(t *testing.T) {
+	obv := indicator.NewOBV()
+
+	warmup, values := run(t, obv, obv.Value)
+
+	assert.Zero(t, warmup)
+	assert.Equal(t, []float64{
+		0, -950, 150, -1350, -50, 750, 2450, 3700, 4600, 6000, 5000, 6150,
+		4550, 5900, 5900, 4850, 6300, 7500, 6520, 4770, 6070, 7170, 5620, 6870,
+		5220, 3420, 1320, 2720, 3920, 5070, 3170, 870, 2470,
+	}, values)
+}
@@ -2,18 +2,26 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 
 	"github.com/project-code-io/crypto-trading-bot-go/app"
 	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/portfolio"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
 
 func main() {
+	taxYear := flag.Int("tax-year", 0, "write the disposals of the calendar year as CSV to stdout, then exit")
+	taxMethod := flag.String("tax-method", string(portfolio.MethodFIFO), "the cost basis method of the tax report")
+	flag.Parse()
+
 	logger, err := zap.NewProduction()
 	if err != nil {
 		fmt.Println("error creating logger:", err)
@@ -38,6 +46,23 @@ func main() {
 		return
 	}
 
-	a := app.New(logger, exc)
+	if *taxYear != 0 {
+		year := portfolio.CalendarYear(*taxYear, time.UTC)
+		method := portfolio.Method(*taxMethod)
+
+		if err := portfolio.WriteTaxReport(ctx, os.Stdout, exc, method, year, trading.BTCUSD); err != nil {
+			logger.Error("failed to write tax report", zap.Error(err))
+		}
+
+		return
+	}
+
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	if err != nil {
+		logger.Error("failed to create portfolio", zap.Error(err))
+		return
+	}
+
+	a := app.New(logger, exc, app.WithPortfolio(p))
 	a.Start(ctx)
 }
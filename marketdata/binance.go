@@ -0,0 +1,643 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// ErrListenKeyExpired describes an error in which binance has ended the user
+// data stream as its listen key expired, such as when it has not been kept
+// alive.
+var ErrListenKeyExpired = errors.New("listen key expired")
+
+// ListenKeyManager represents a client that is able to manage the listen keys
+// of the binance user data stream.
+type ListenKeyManager interface {
+	CreateListenKey(ctx context.Context) (string, error)
+	KeepAliveListenKey(ctx context.Context, listenKey string) error
+	CloseListenKey(ctx context.Context, listenKey string) error
+}
+
+var _ ListenKeyManager = (*exchange.Binance)(nil)
+
+// Binance represents a stream of the binance websocket api, which multiplexes
+// every stream of a subscription over a single combined stream. The user data
+// stream is subscribed to with a listen key, which is kept alive while the
+// stream runs and closed once it stops.
+type Binance struct {
+	keys    ListenKeyManager
+	opts    options
+	markets *trading.Registry
+}
+
+const (
+	// binanceIdle is how long to wait for a message, which is well over the
+	// 20 seconds between the pings of binance.
+	binanceIdle = time.Minute
+
+	// binanceLifetime is how long a connection is kept, which is just under
+	// the 24 hours after which binance drops it.
+	binanceLifetime = 23*time.Hour + 30*time.Minute
+
+	// binanceKeepAlive is how often the listen key is kept alive, as binance
+	// recommends.
+	binanceKeepAlive = 30 * time.Minute
+)
+
+// NewBinance acts as the default constructor for the Binance stream type. The
+// domain picks the websocket api of either binance.us or binance.com. The
+// listen keys are only needed for the user channel, so they may be nil, and
+// are typically the exchange client, i.e. exchange.Binance. Options can be
+// passed to override the URL and the markets.
+func NewBinance(domain exchange.BinanceDomain, keys ListenKeyManager, opts ...Option) *Binance {
+	url := "wss://stream.binance.us:9443"
+	if domain == exchange.BinanceDomainDotCom {
+		url = "wss://stream.binance.com:9443"
+	}
+
+	o := newOptions(url, opts)
+
+	return &Binance{
+		keys: keys,
+		opts: o,
+		markets: o.registry(func() *trading.Registry {
+			return trading.NewRegistry(
+				trading.Market{Pair: trading.BTCUSD, Symbol: "BTCUSD"},
+				trading.Market{Pair: trading.ETHUSD, Symbol: "ETHUSD"},
+			)
+		}),
+	}
+}
+
+// Subscribe connects to the combined stream of the channels for the pairs,
+// along with the user data stream if the user channel is subscribed to. The
+// events are sent on the returned channel until the context is cancelled.
+func (b *Binance) Subscribe(ctx context.Context, s Subscription) (<-chan Event, error) {
+	p := &binanceProtocol{
+		base:    b.opts.url,
+		keys:    b.keys,
+		markets: b.markets,
+	}
+
+	for _, ch := range s.Channels {
+		if ch != ChannelUser {
+			continue
+		}
+
+		if b.keys == nil {
+			return nil, fmt.Errorf("user channel: %w", exchange.ErrAPIKeyNotSet)
+		}
+
+		p.user = true
+	}
+
+	for _, pair := range s.Pairs {
+		streams, err := b.streams(pair, s.Channels)
+		if err != nil {
+			return nil, err
+		}
+
+		p.streams = append(p.streams, streams...)
+	}
+
+	events, err := startStream(ctx, b.opts, p, limits{idle: binanceIdle, lifetime: binanceLifetime})
+	if err != nil {
+		return nil, err
+	}
+
+	if p.user {
+		go p.keepAlive(ctx, b.opts.keepAlive(binanceKeepAlive))
+	}
+
+	return events, nil
+}
+
+// streams returns the names of the streams of the channels for the pair.
+func (b *Binance) streams(pair trading.Pair, channels []Channel) ([]string, error) {
+	m, err := b.markets.Market(pair)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, exchange.ErrMissingPair)
+	}
+
+	symbol := strings.ToLower(m.Symbol)
+	streams := make([]string, 0, len(channels))
+
+	for _, ch := range channels {
+		switch ch {
+		case ChannelTicker:
+			streams = append(streams, symbol+"@bookTicker")
+		case ChannelBook:
+			streams = append(streams, symbol+"@depth@100ms")
+		case ChannelTrades:
+			streams = append(streams, symbol+"@trade")
+		case ChannelUser:
+			// The user data stream is for the whole account rather than a
+			// pair, and is added with the listen key.
+		default:
+			interval, ok := ch.Interval()
+			if !ok || exchange.BinanceIntervals[interval] == "" {
+				return nil, fmt.Errorf("%s: %w", ch, ErrUnknownChannel)
+			}
+
+			streams = append(streams, symbol+"@kline_"+exchange.BinanceIntervals[interval])
+		}
+	}
+
+	return streams, nil
+}
+
+// binanceProtocol connects to the combined stream of binance and decodes its
+// messages. The listen key is shared with the keep alive, so it is guarded by
+// the mutex.
+type binanceProtocol struct {
+	base    string
+	streams []string
+	user    bool
+	keys    ListenKeyManager
+	markets *trading.Registry
+
+	mu        sync.Mutex
+	listenKey string
+}
+
+func (p *binanceProtocol) url(ctx context.Context) (string, error) {
+	streams := append([]string(nil), p.streams...)
+
+	if p.user {
+		key, err := p.currentKey(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		streams = append(streams, key)
+	}
+
+	return p.base + "/stream?streams=" + strings.Join(streams, "/"), nil
+}
+
+// subscribe sends nothing, as the streams of a combined stream are given in
+// its URL.
+func (p *binanceProtocol) subscribe(*websocket.Conn) error {
+	return nil
+}
+
+// currentKey returns the listen key of the user data stream, creating one if
+// there is none yet or the last one has expired.
+func (p *binanceProtocol) currentKey(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listenKey != "" {
+		return p.listenKey, nil
+	}
+
+	key, err := p.keys.CreateListenKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("create listen key: %w", err)
+	}
+
+	p.listenKey = key
+
+	return key, nil
+}
+
+// expireKey forgets the listen key, so that a new one is created when the
+// stream reconnects.
+func (p *binanceProtocol) expireKey() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := p.listenKey
+	p.listenKey = ""
+
+	return key
+}
+
+func (p *binanceProtocol) key() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.listenKey
+}
+
+// keepAlive keeps the listen key alive on every interval until the context
+// is cancelled, then closes it. A key that cannot be kept alive is forgotten,
+// so that the stream creates a new one once binance ends the user data stream.
+func (p *binanceProtocol) keepAlive(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if key := p.key(); key != "" && p.keys.KeepAliveListenKey(ctx, key) != nil {
+				p.expireKey()
+			}
+		case <-ctx.Done():
+			if key := p.expireKey(); key != "" {
+				// The context has been cancelled, so the key is closed on a
+				// context of its own.
+				closeCtx, cancel := context.WithTimeout(context.Background(), writeWait)
+				_ = p.keys.CloseListenKey(closeCtx, key)
+
+				cancel()
+			}
+
+			return
+		}
+	}
+}
+
+func (p *binanceProtocol) handle(data []byte) ([]Event, error) {
+	var msg struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("decode message: %w", err)
+	}
+
+	switch {
+	case strings.HasSuffix(msg.Stream, "@bookTicker"):
+		return p.ticker(msg.Data)
+	case strings.Contains(msg.Stream, "@depth"):
+		return p.depth(msg.Data)
+	case strings.HasSuffix(msg.Stream, "@trade"):
+		return p.trade(msg.Data)
+	case strings.Contains(msg.Stream, "@kline_"):
+		return p.kline(msg.Data)
+	default:
+		return p.userData(msg.Data)
+	}
+}
+
+// The payloads of binance have keys that differ only by case, which the json
+// package matches without regard to case if there is no exact match. The
+// structs below declare both keys of every such pair, so that one is never
+// decoded into the field of the other.
+
+func (p *binanceProtocol) pair(symbol string) (trading.Pair, error) {
+	m, err := p.markets.MarketBySymbol(symbol)
+	if err != nil {
+		return trading.Pair{}, fmt.Errorf("%v: %w", err, exchange.ErrMissingPair)
+	}
+
+	return m.Pair, nil
+}
+
+func (p *binanceProtocol) ticker(data []byte) ([]Event, error) {
+	var t struct {
+		UpdateID int64          `json:"u"`
+		Symbol   string         `json:"s"`
+		BidPrice trading.Amount `json:"b"`
+		BidQty   trading.Amount `json:"B"`
+		AskPrice trading.Amount `json:"a"`
+		AskQty   trading.Amount `json:"A"`
+	}
+
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("decode book ticker: %w", err)
+	}
+
+	pair, err := p.pair(t.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// The book ticker has no time, nor the last price, which is left as zero.
+	return []Event{Ticker{
+		Pair:        pair,
+		Time:        time.Now().UTC(),
+		BestBid:     t.BidPrice,
+		BestBidSize: t.BidQty,
+		BestAsk:     t.AskPrice,
+		BestAskSize: t.AskQty,
+	}}, nil
+}
+
+func (p *binanceProtocol) depth(data []byte) ([]Event, error) {
+	var d struct {
+		Event         string              `json:"e"`
+		EventTime     int64               `json:"E"`
+		Symbol        string              `json:"s"`
+		FirstUpdateID int64               `json:"U"`
+		FinalUpdateID int64               `json:"u"`
+		Bids          [][2]trading.Amount `json:"b"`
+		Asks          [][2]trading.Amount `json:"a"`
+	}
+
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("decode depth: %w", err)
+	}
+
+	pair, err := p.pair(d.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Event{BookUpdate{
+		Pair:          pair,
+		Time:          exchange.BinanceTime(d.EventTime),
+		Bids:          binanceLevels(d.Bids),
+		Asks:          binanceLevels(d.Asks),
+		FirstUpdateID: d.FirstUpdateID,
+		LastUpdateID:  d.FinalUpdateID,
+	}}, nil
+}
+
+func binanceLevels(levels [][2]trading.Amount) []Level {
+	out := make([]Level, 0, len(levels))
+
+	for _, l := range levels {
+		out = append(out, Level{Price: l[0], Size: l[1]})
+	}
+
+	return out
+}
+
+func (p *binanceProtocol) trade(data []byte) ([]Event, error) {
+	var t struct {
+		Event        string         `json:"e"`
+		EventTime    int64          `json:"E"`
+		Symbol       string         `json:"s"`
+		TradeID      int64          `json:"t"`
+		TradeTime    int64          `json:"T"`
+		Price        trading.Amount `json:"p"`
+		Quantity     trading.Amount `json:"q"`
+		BuyerIsMaker bool           `json:"m"`
+		Ignore       bool           `json:"M"`
+	}
+
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("decode trade: %w", err)
+	}
+
+	pair, err := p.pair(t.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// The side of the trade is that of the taker, which sold into the bid if
+	// the buyer was the maker.
+	side := order.SideBuy
+	if t.BuyerIsMaker {
+		side = order.SideSell
+	}
+
+	return []Event{Trade{
+		ID:    strconv.FormatInt(t.TradeID, 10),
+		Pair:  pair,
+		Time:  exchange.BinanceTime(t.TradeTime),
+		Side:  side,
+		Price: t.Price,
+		Size:  t.Quantity,
+	}}, nil
+}
+
+func (p *binanceProtocol) kline(data []byte) ([]Event, error) {
+	var k struct {
+		Symbol string `json:"s"`
+		Kline  struct {
+			StartTime   int64          `json:"t"`
+			CloseTime   int64          `json:"T"`
+			Interval    string         `json:"i"`
+			Open        trading.Amount `json:"o"`
+			Close       trading.Amount `json:"c"`
+			High        trading.Amount `json:"h"`
+			Low         trading.Amount `json:"l"`
+			LastTradeID int64          `json:"L"`
+			Volume      trading.Amount `json:"v"`
+			TakerVolume trading.Amount `json:"V"`
+			QuoteVolume trading.Amount `json:"q"`
+			TakerQuote  trading.Amount `json:"Q"`
+			Closed      bool           `json:"x"`
+		} `json:"k"`
+	}
+
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, fmt.Errorf("decode kline: %w", err)
+	}
+
+	pair, err := p.pair(k.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var interval time.Duration
+
+	for d, i := range exchange.BinanceIntervals {
+		if i == k.Kline.Interval {
+			interval = d
+		}
+	}
+
+	return []Event{Candle{
+		Pair:     pair,
+		Start:    exchange.BinanceTime(k.Kline.StartTime),
+		Interval: interval,
+		Open:     k.Kline.Open,
+		High:     k.Kline.High,
+		Low:      k.Kline.Low,
+		Close:    k.Kline.Close,
+		Volume:   k.Kline.Volume,
+		Closed:   k.Kline.Closed,
+	}}, nil
+}
+
+func (p *binanceProtocol) userData(data []byte) ([]Event, error) {
+	var head struct {
+		Event     string `json:"e"`
+		EventTime int64  `json:"E"`
+	}
+
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("decode user data: %w", err)
+	}
+
+	switch head.Event {
+	case "executionReport":
+		return p.executionReport(data)
+	case "outboundAccountPosition":
+		return p.accountPosition(data)
+	case "balanceUpdate":
+		return p.balanceUpdate(data)
+	case "listenKeyExpired":
+		p.expireKey()
+		return nil, ErrListenKeyExpired
+	default:
+		return nil, nil
+	}
+}
+
+// binanceExecutionReport is the update of an order in the user data stream.
+type binanceExecutionReport struct {
+	Event                 string         `json:"e"`
+	EventTime             int64          `json:"E"`
+	Symbol                string         `json:"s"`
+	Side                  string         `json:"S"`
+	ClientOrderID         string         `json:"c"`
+	OriginalClientOrderID string         `json:"C"`
+	Quantity              trading.Amount `json:"q"`
+	QuoteOrderQuantity    trading.Amount `json:"Q"`
+	Price                 trading.Amount `json:"p"`
+	StopPrice             trading.Amount `json:"P"`
+	ExecutionType         string         `json:"x"`
+	Status                string         `json:"X"`
+	OrderID               int64          `json:"i"`
+	Ignore                int64          `json:"I"`
+	LastQuantity          trading.Amount `json:"l"`
+	LastPrice             trading.Amount `json:"L"`
+	FilledQuantity        trading.Amount `json:"z"`
+	FilledQuoteQuantity   trading.Amount `json:"Z"`
+	Commission            trading.Amount `json:"n"`
+	CommissionAsset       *string        `json:"N"`
+	TradeID               int64          `json:"t"`
+	TransactionTime       int64          `json:"T"`
+	OrderType             string         `json:"o"`
+	CreationTime          int64          `json:"O"`
+	IsMaker               bool           `json:"m"`
+	IgnoreToo             bool           `json:"M"`
+	IsWorking             bool           `json:"w"`
+	WorkingTime           int64          `json:"W"`
+	TimeInForce           string         `json:"f"`
+	IcebergQuantity       trading.Amount `json:"F"`
+}
+
+// binanceStatuses maps the statuses of binance orders which are not open to
+// the status of an order.
+var binanceStatuses = map[string]order.Status{
+	"FILLED":           order.StatusFilled,
+	"CANCELED":         order.StatusCancelled,
+	"REJECTED":         order.StatusRejected,
+	"EXPIRED":          order.StatusExpired,
+	"EXPIRED_IN_MATCH": order.StatusExpired,
+}
+
+func (p *binanceProtocol) executionReport(data []byte) ([]Event, error) {
+	var r binanceExecutionReport
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("decode execution report: %w", err)
+	}
+
+	// Orders on pairs that are not supported are still sent, as the client
+	// still knows them by their IDs.
+	pair, _ := p.pair(r.Symbol)
+
+	// The client ID of a cancelled order is that of the cancel request, with
+	// the client ID of the order itself given separately.
+	clientID := r.ClientOrderID
+	if r.OriginalClientOrderID != "" {
+		clientID = r.OriginalClientOrderID
+	}
+
+	status, ok := binanceStatuses[r.Status]
+	if !ok {
+		status = order.OpenStatus(r.FilledQuantity)
+	}
+
+	o := exchange.Order{
+		ID:         strconv.FormatInt(r.OrderID, 10),
+		Pair:       pair,
+		Side:       order.Side(r.Side),
+		ClientID:   clientID,
+		BaseSize:   r.Quantity,
+		Price:      r.Price,
+		Status:     status,
+		FilledSize: r.FilledQuantity,
+		CreatedAt:  exchange.BinanceTime(r.CreationTime),
+		UpdatedAt:  exchange.BinanceTime(r.TransactionTime),
+	}
+
+	if r.FilledQuantity.Sign() > 0 {
+		// The error is ignored as the filled quantity is above zero.
+		o.AveragePrice, _ = r.FilledQuoteQuantity.Div(r.FilledQuantity, pair.Quote.Decimals(), trading.RoundHalfEven)
+	}
+
+	events := []Event{OrderUpdate{Order: o}}
+
+	if r.ExecutionType == "TRADE" {
+		fill := exchange.Fill{
+			ID:       strconv.FormatInt(r.TradeID, 10),
+			OrderID:  o.ID,
+			ClientID: clientID,
+			Pair:     pair,
+			Side:     o.Side,
+			Price:    r.LastPrice,
+			Size:     r.LastQuantity,
+			Fee:      r.Commission,
+			Time:     exchange.BinanceTime(r.TransactionTime),
+		}
+
+		if r.CommissionAsset != nil {
+			fill.FeeAsset = trading.Asset(*r.CommissionAsset)
+		}
+
+		events = append(events, Fill{Fill: fill})
+	}
+
+	return events, nil
+}
+
+func (p *binanceProtocol) accountPosition(data []byte) ([]Event, error) {
+	var a struct {
+		Event      string `json:"e"`
+		EventTime  int64  `json:"E"`
+		UpdateTime int64  `json:"u"`
+		Balances   []struct {
+			Asset  string         `json:"a"`
+			Free   trading.Amount `json:"f"`
+			Locked trading.Amount `json:"l"`
+		} `json:"B"`
+	}
+
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("decode account position: %w", err)
+	}
+
+	events := make([]Event, 0, len(a.Balances))
+
+	for _, b := range a.Balances {
+		events = append(events, Balance{
+			Asset:  trading.Asset(b.Asset),
+			Time:   exchange.BinanceTime(a.UpdateTime),
+			Free:   b.Free,
+			Locked: b.Locked,
+		})
+	}
+
+	return events, nil
+}
+
+func (p *binanceProtocol) balanceUpdate(data []byte) ([]Event, error) {
+	var b struct {
+		Event     string         `json:"e"`
+		EventTime int64          `json:"E"`
+		Asset     string         `json:"a"`
+		Delta     trading.Amount `json:"d"`
+		ClearTime int64          `json:"T"`
+	}
+
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("decode balance update: %w", err)
+	}
+
+	return []Event{BalanceChange{
+		Asset: trading.Asset(b.Asset),
+		Time:  exchange.BinanceTime(b.ClearTime),
+		Delta: b.Delta,
+	}}, nil
+}
@@ -0,0 +1,403 @@
+package marketdata_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata/marketdatatest"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// listenKeys is a ListenKeyManager that hands out numbered listen keys and
+// records what is done with them.
+type listenKeys struct {
+	err error
+
+	mu         sync.Mutex
+	created    int
+	keptAlive  []string
+	closedKeys []string
+}
+
+func (l *listenKeys) CreateListenKey(context.Context) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return "", l.err
+	}
+
+	l.created++
+
+	return "key-" + strconv.Itoa(l.created), nil
+}
+
+func (l *listenKeys) KeepAliveListenKey(_ context.Context, listenKey string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.keptAlive = append(l.keptAlive, listenKey)
+
+	return nil
+}
+
+func (l *listenKeys) CloseListenKey(_ context.Context, listenKey string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closedKeys = append(l.closedKeys, listenKey)
+
+	return nil
+}
+
+func (l *listenKeys) kept() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.keptAlive...)
+}
+
+func (l *listenKeys) closed() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.closedKeys...)
+}
+
+func connected(t *testing.T, server *marketdatatest.Server) string {
+	t.Helper()
+
+	select {
+	case uri := <-server.Connected():
+		return uri
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for connection")
+		return ""
+	}
+}
+
+func newBinance(
+	server *marketdatatest.Server,
+	keys marketdata.ListenKeyManager,
+	opts ...marketdata.Option,
+) *marketdata.Binance {
+	opts = append([]marketdata.Option{
+		marketdata.WithURL(server.URL),
+		marketdata.WithReconnectWait(time.Millisecond, time.Millisecond),
+	}, opts...)
+
+	return marketdata.NewBinance(exchange.BinanceDomainUS, keys, opts...)
+}
+
+func binanceMessage(stream string, data string) map[string]any {
+	return map[string]any{
+		"stream": stream,
+		"data":   json.RawMessage(data),
+	}
+}
+
+func TestBinanceSubscribe(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	testCases := []struct {
+		name         string
+		subscription marketdata.Subscription
+		expected     string
+	}{
+		{
+			name: "market channels",
+			subscription: marketdata.Subscription{
+				Pairs: []trading.Pair{trading.BTCUSD, trading.ETHUSD},
+				Channels: []marketdata.Channel{
+					marketdata.ChannelTicker,
+					marketdata.ChannelBook,
+					marketdata.ChannelTrades,
+					marketdata.CandleChannel(time.Minute),
+				},
+			},
+			expected: "/stream?streams=btcusd@bookTicker/btcusd@depth@100ms/btcusd@trade/btcusd@kline_1m" +
+				"/ethusd@bookTicker/ethusd@depth@100ms/ethusd@trade/ethusd@kline_1m",
+		},
+		{
+			name: "user channel",
+			subscription: marketdata.Subscription{
+				Pairs:    []trading.Pair{trading.BTCUSD},
+				Channels: []marketdata.Channel{marketdata.ChannelTrades, marketdata.ChannelUser},
+			},
+			expected: "/stream?streams=btcusd@trade/key-1",
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			b := newBinance(server, &listenKeys{})
+
+			_, err := b.Subscribe(ctx, tt.subscription)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, connected(t, server))
+		})
+	}
+}
+
+func TestBinanceSubscribeErrors(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	errCreate := errors.New("create failed")
+
+	testCases := []struct {
+		name         string
+		keys         marketdata.ListenKeyManager
+		subscription marketdata.Subscription
+		expected     error
+	}{
+		{
+			name: "unknown pair",
+			keys: &listenKeys{},
+			subscription: marketdata.Subscription{
+				Pairs:    []trading.Pair{{Base: "DOGE", Quote: "USD"}},
+				Channels: []marketdata.Channel{marketdata.ChannelTicker},
+			},
+			expected: exchange.ErrMissingPair,
+		},
+		{
+			name: "unknown channel",
+			keys: &listenKeys{},
+			subscription: marketdata.Subscription{
+				Pairs:    []trading.Pair{trading.BTCUSD},
+				Channels: []marketdata.Channel{"level3"},
+			},
+			expected: marketdata.ErrUnknownChannel,
+		},
+		{
+			name: "unknown interval",
+			keys: &listenKeys{},
+			subscription: marketdata.Subscription{
+				Pairs:    []trading.Pair{trading.BTCUSD},
+				Channels: []marketdata.Channel{marketdata.CandleChannel(7 * time.Minute)},
+			},
+			expected: marketdata.ErrUnknownChannel,
+		},
+		{
+			name:         "user channel without credentials",
+			subscription: marketdata.Subscription{Channels: []marketdata.Channel{marketdata.ChannelUser}},
+			expected:     exchange.ErrAPIKeyNotSet,
+		},
+		{
+			name:         "listen key not created",
+			keys:         &listenKeys{err: errCreate},
+			subscription: marketdata.Subscription{Channels: []marketdata.Channel{marketdata.ChannelUser}},
+			expected:     errCreate,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBinance(server, tt.keys)
+
+			_, err := b.Subscribe(context.Background(), tt.subscription)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+//nolint:funlen // The events are checked one after another, as they are sent.
+func TestBinanceEvents(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := newBinance(server, &listenKeys{})
+
+	events, err := b.Subscribe(ctx, marketdata.Subscription{
+		Pairs:    []trading.Pair{trading.BTCUSD},
+		Channels: []marketdata.Channel{marketdata.ChannelBook, marketdata.ChannelUser},
+	})
+	assert.NoError(t, err)
+
+	connected(t, server)
+
+	msgTime := time.Date(2023, 2, 9, 20, 30, 37, 167000000, time.UTC)
+
+	assert.NoError(t, server.Send(binanceMessage("btcusd@bookTicker", `{"u":400900217,"s":"BTCUSD",
+		"b":"21921.73","B":"0.06317902","a":"21921.74","A":"0.5"}`)))
+	assert.NoError(t, server.Send(binanceMessage("btcusd@depth@100ms", `{"e":"depthUpdate","E":1675974637167,
+		"s":"BTCUSD","U":157,"u":160,"b":[["21921.73","0.06317902"]],"a":[["21921.74","0"]]}`)))
+	assert.NoError(t, server.Send(binanceMessage("btcusd@trade", `{"e":"trade","E":1675974637167,"s":"BTCUSD",
+		"t":12345,"p":"21932.98","q":"0.1","b":88,"a":50,"T":1675974637167,"m":true,"M":true}`)))
+	assert.NoError(t, server.Send(binanceMessage("btcusd@kline_1m", `{"e":"kline","E":1675974637167,
+		"s":"BTCUSD","k":{"t":1675974600000,"T":1675974659999,"s":"BTCUSD","i":"1m","f":100,"L":200,
+		"o":"21900","c":"21932.98","h":"21940","l":"21890","v":"12.5","n":100,"x":false,"q":"274000",
+		"V":"6","Q":"131000","B":"0"}}`)))
+	assert.NoError(t, server.Send(binanceMessage("key-1", `{"e":"executionReport","E":1675974637167,
+		"s":"BTCUSD","c":"go-trading-bot-1","S":"BUY","o":"LIMIT","f":"GTC","q":"1","p":"21000","P":"0",
+		"F":"0","g":-1,"C":"","x":"TRADE","X":"PARTIALLY_FILLED","r":"NONE","i":4293153,"l":"0.4",
+		"z":"0.4","L":"21000","n":"1.5","N":"USD","T":1675974637167,"t":54321,"I":8641984,"w":false,
+		"m":true,"M":true,"O":1675972800000,"Z":"8400","Y":"8400","Q":"0","W":1675972800000,"V":"NONE"}`)))
+	assert.NoError(t, server.Send(binanceMessage("key-1", `{"e":"outboundAccountPosition","E":1675974637167,
+		"u":1675974637167,"B":[{"a":"BTC","f":"0.4","l":"0"},{"a":"USD","f":"1000","l":"12600"}]}`)))
+	assert.NoError(t, server.Send(binanceMessage("key-1", `{"e":"balanceUpdate","E":1675974637167,"a":"BTC",
+		"d":"0.1","T":1675974637167}`)))
+
+	ticker, ok := receive(t, events).(marketdata.Ticker)
+	assert.True(t, ok)
+	assert.Equal(t, trading.BTCUSD, ticker.Pair)
+	assert.True(t, ticker.Price.IsZero())
+	assert.Equal(t, trading.MustParseAmount("21921.73"), ticker.BestBid)
+	assert.Equal(t, trading.MustParseAmount("0.06317902"), ticker.BestBidSize)
+	assert.Equal(t, trading.MustParseAmount("21921.74"), ticker.BestAsk)
+	assert.Equal(t, trading.MustParseAmount("0.5"), ticker.BestAskSize)
+
+	assert.Equal(t, marketdata.BookUpdate{
+		Pair: trading.BTCUSD,
+		Time: msgTime,
+		Bids: []marketdata.Level{
+			{Price: trading.MustParseAmount("21921.73"), Size: trading.MustParseAmount("0.06317902")},
+		},
+		Asks: []marketdata.Level{
+			{Price: trading.MustParseAmount("21921.74"), Size: trading.MustParseAmount("0")},
+		},
+		FirstUpdateID: 157,
+		LastUpdateID:  160,
+	}, receive(t, events))
+
+	assert.Equal(t, marketdata.Trade{
+		ID:    "12345",
+		Pair:  trading.BTCUSD,
+		Time:  msgTime,
+		Side:  order.SideSell,
+		Price: trading.MustParseAmount("21932.98"),
+		Size:  trading.MustParseAmount("0.1"),
+	}, receive(t, events))
+
+	assert.Equal(t, marketdata.Candle{
+		Pair:     trading.BTCUSD,
+		Start:    time.Date(2023, 2, 9, 20, 30, 0, 0, time.UTC),
+		Interval: time.Minute,
+		Open:     trading.MustParseAmount("21900"),
+		High:     trading.MustParseAmount("21940"),
+		Low:      trading.MustParseAmount("21890"),
+		Close:    trading.MustParseAmount("21932.98"),
+		Volume:   trading.MustParseAmount("12.5"),
+	}, receive(t, events))
+
+	update, ok := receive(t, events).(marketdata.OrderUpdate)
+	assert.True(t, ok)
+	assert.Equal(t, "4293153", update.Order.ID)
+	assert.Equal(t, "go-trading-bot-1", update.Order.ClientID)
+	assert.Equal(t, order.SideBuy, update.Order.Side)
+	assert.Equal(t, order.StatusPartiallyFilled, update.Order.Status)
+	assert.Zero(t, update.Order.FilledSize.Cmp(trading.MustParseAmount("0.4")))
+	assert.Zero(t, update.Order.AveragePrice.Cmp(trading.MustParseAmount("21000")))
+	assert.Equal(t, msgTime, update.Order.UpdatedAt)
+
+	assert.Equal(t, marketdata.Fill{Fill: exchange.Fill{
+		ID:       "54321",
+		OrderID:  "4293153",
+		ClientID: "go-trading-bot-1",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		Price:    trading.MustParseAmount("21000"),
+		Size:     trading.MustParseAmount("0.4"),
+		Fee:      trading.MustParseAmount("1.5"),
+		FeeAsset: trading.USD,
+		Time:     msgTime,
+	}}, receive(t, events))
+
+	assert.Equal(t, marketdata.Balance{
+		Asset:  trading.BTC,
+		Time:   msgTime,
+		Free:   trading.MustParseAmount("0.4"),
+		Locked: trading.MustParseAmount("0"),
+	}, receive(t, events))
+
+	assert.Equal(t, marketdata.Balance{
+		Asset:  trading.USD,
+		Time:   msgTime,
+		Free:   trading.MustParseAmount("1000"),
+		Locked: trading.MustParseAmount("12600"),
+	}, receive(t, events))
+
+	assert.Equal(t, marketdata.BalanceChange{
+		Asset: trading.BTC,
+		Time:  msgTime,
+		Delta: trading.MustParseAmount("0.1"),
+	}, receive(t, events))
+}
+
+func TestBinanceListenKey(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	keys := &listenKeys{}
+	b := newBinance(server, keys, marketdata.WithKeepAlive(10*time.Millisecond))
+
+	events, err := b.Subscribe(ctx, marketdata.Subscription{Channels: []marketdata.Channel{marketdata.ChannelUser}})
+	assert.NoError(t, err)
+	assert.Equal(t, "/stream?streams=key-1", connected(t, server))
+
+	assert.Eventually(t, func() bool {
+		return len(keys.kept()) > 0
+	}, timeout, time.Millisecond)
+	assert.Equal(t, "key-1", keys.kept()[0])
+
+	// Binance ends the user data stream once the listen key expires, after
+	// which the stream reconnects with a new one.
+	assert.NoError(t, server.Send(binanceMessage("key-1", `{"e":"listenKeyExpired","E":1675974637167}`)))
+
+	reconnect, ok := receive(t, events).(marketdata.Reconnect)
+	assert.True(t, ok)
+	assert.ErrorIs(t, reconnect.Err, marketdata.ErrListenKeyExpired)
+	assert.Equal(t, "/stream?streams=key-2", connected(t, server))
+
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		return len(keys.closed()) > 0
+	}, timeout, time.Millisecond)
+	assert.Equal(t, []string{"key-2"}, keys.closed())
+}
+
+func TestBinanceConnectionLifetime(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := newBinance(server, nil, marketdata.WithConnectionLifetime(20*time.Millisecond))
+
+	events, err := b.Subscribe(ctx, marketdata.Subscription{
+		Pairs:    []trading.Pair{trading.BTCUSD},
+		Channels: []marketdata.Channel{marketdata.ChannelTrades},
+	})
+	assert.NoError(t, err)
+	connected(t, server)
+
+	reconnect, ok := receive(t, events).(marketdata.Reconnect)
+	assert.True(t, ok)
+	assert.ErrorIs(t, reconnect.Err, marketdata.ErrConnectionExpired)
+	assert.Equal(t, "/stream?streams=btcusd@trade", connected(t, server))
+}
@@ -0,0 +1,368 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// CandleSource represents a client that is able to fetch the candles of a
+// pair, i.e. exchange.Binance or exchange.Coinbase.
+type CandleSource interface {
+	CandleIntervals() []time.Duration
+	GetCandles(
+		ctx context.Context, p trading.Pair, interval time.Duration, start time.Time, end time.Time,
+	) ([]exchange.Candle, error)
+}
+
+var (
+	_ CandleSource = (*exchange.Binance)(nil)
+	_ CandleSource = (*exchange.Coinbase)(nil)
+)
+
+// candleStart returns the start of the candle of the interval that the time
+// falls in. Candles are aligned to the zero time, which aligns daily and
+// shorter candles to midnight UTC and weekly candles to Monday.
+func candleStart(t time.Time, interval time.Duration) time.Time {
+	return t.UTC().Truncate(interval)
+}
+
+// FetchCandles obtains the candles of the pair with the interval that start
+// from the start time up to but not including the end time, oldest first. An
+// interval that the source does not offer is built from the candles of the
+// longest interval that it does offer and that divides it. Intervals without
+// trades, which exchanges leave out, are filled with flat candles. Candles are
+// closed once their interval has passed.
+func FetchCandles(
+	ctx context.Context, source CandleSource, p trading.Pair, interval time.Duration, start time.Time, end time.Time,
+) ([]Candle, error) {
+	var base time.Duration
+
+	for _, d := range source.CandleIntervals() {
+		if interval%d == 0 && d > base {
+			base = d
+		}
+	}
+
+	if base == 0 {
+		return nil, fmt.Errorf("%s: %w", interval, exchange.ErrUnsupportedInterval)
+	}
+
+	fetched, err := source.GetCandles(ctx, p, base, candleStart(start, interval), end)
+	if err != nil {
+		return nil, fmt.Errorf("get candles: %w", err)
+	}
+
+	now := time.Now()
+	candles := make([]Candle, 0, len(fetched))
+
+	for _, c := range fetched {
+		candles = append(candles, Candle{
+			Pair:     p,
+			Start:    c.Start,
+			Interval: base,
+			Open:     c.Open,
+			High:     c.High,
+			Low:      c.Low,
+			Close:    c.Close,
+			Volume:   c.Volume,
+			Closed:   !c.Start.Add(base).After(now),
+		})
+	}
+
+	// The gaps are filled before the candles are merged, so that a merged
+	// candle whose last candles had no trades still ends with its interval.
+	candles = FillGaps(candles)
+
+	if base == interval {
+		return candles, nil
+	}
+
+	candles = FillGaps(Resample(candles, interval))
+
+	// A merged candle whose last candles are missing altogether, as there
+	// has been no trade since, is still closed once its interval has passed.
+	for i, c := range candles {
+		if !c.Closed && !c.Start.Add(interval).After(now) {
+			candles[i].Closed = true
+		}
+	}
+
+	return candles, nil
+}
+
+// Resample merges the candles, which must be in order, into candles of the
+// longer interval. A merged candle is only closed once the last of its
+// candles is closed and ends with it.
+func Resample(candles []Candle, interval time.Duration) []Candle {
+	out := make([]Candle, 0, len(candles))
+
+	for _, c := range candles {
+		start := candleStart(c.Start, interval)
+		closed := c.Closed && c.Start.Add(c.Interval).Equal(start.Add(interval))
+
+		if n := len(out); n > 0 && out[n-1].Start.Equal(start) {
+			out[n-1] = merge(out[n-1], c)
+			out[n-1].Closed = closed
+
+			continue
+		}
+
+		c.Start = start
+		c.Interval = interval
+		c.Closed = closed
+		out = append(out, c)
+	}
+
+	return out
+}
+
+// merge returns the candle extended by the later candle.
+func merge(c Candle, later Candle) Candle {
+	if later.High.Cmp(c.High) > 0 {
+		c.High = later.High
+	}
+
+	if later.Low.Cmp(c.Low) < 0 {
+		c.Low = later.Low
+	}
+
+	c.Close = later.Close
+	c.Volume = c.Volume.Add(later.Volume)
+
+	return c
+}
+
+// FillGaps returns the candles, which must be in order, with a flat candle at
+// the close of the candle before it for each interval that is missing.
+func FillGaps(candles []Candle) []Candle {
+	if len(candles) == 0 {
+		return candles
+	}
+
+	out := make([]Candle, 0, len(candles))
+	out = append(out, candles[0])
+
+	for _, c := range candles[1:] {
+		for last := out[len(out)-1]; last.Interval > 0 && last.Start.Add(last.Interval).Before(c.Start); {
+			last = flat(last, last.Start.Add(last.Interval))
+			out = append(out, last)
+		}
+
+		out = append(out, c)
+	}
+
+	return out
+}
+
+// flat returns a closed candle without trades that starts at the time, at the
+// close of the candle before it.
+func flat(before Candle, start time.Time) Candle {
+	return Candle{
+		Pair:     before.Pair,
+		Start:    start,
+		Interval: before.Interval,
+		Open:     before.Close,
+		High:     before.Close,
+		Low:      before.Close,
+		Close:    before.Close,
+		Closed:   true,
+	}
+}
+
+// Aggregator builds the candles of a pair with an interval from its trades,
+// such as for intervals that the exchange does not stream. It is not safe for
+// concurrent use.
+type Aggregator struct {
+	pair     trading.Pair
+	interval time.Duration
+
+	current Candle
+	started bool
+	traded  bool
+}
+
+// NewAggregator acts as the default constructor for the Aggregator type.
+func NewAggregator(pair trading.Pair, interval time.Duration) *Aggregator {
+	return &Aggregator{pair: pair, interval: interval}
+}
+
+// Add adds the trade to the candle of its interval. It returns the candles
+// that the trade closes, along with a flat candle for each interval without
+// trades since. Trades of other pairs, and trades that are older than the
+// current candle, are ignored.
+func (a *Aggregator) Add(t Trade) []Candle {
+	start := candleStart(t.Time, a.interval)
+
+	if t.Pair != a.pair || a.started && start.Before(a.current.Start) {
+		return nil
+	}
+
+	var closed []Candle
+
+	if a.started {
+		closed = a.closeBefore(start)
+	} else {
+		a.current = Candle{Pair: a.pair, Start: start, Interval: a.interval}
+		a.started = true
+	}
+
+	if !a.traded {
+		a.current.Open = t.Price
+		a.current.High = t.Price
+		a.current.Low = t.Price
+		a.traded = true
+	}
+
+	a.current = merge(a.current, Candle{High: t.Price, Low: t.Price, Close: t.Price, Volume: t.Size})
+
+	return closed
+}
+
+// Flush closes the current candle once its interval has ended by the time,
+// along with a flat candle for each interval without trades since, so that
+// candles are closed even when there are no trades.
+func (a *Aggregator) Flush(now time.Time) []Candle {
+	if !a.started {
+		return nil
+	}
+
+	return a.closeBefore(candleStart(now, a.interval))
+}
+
+// Current returns the candle that is still being built, and whether there is
+// one.
+func (a *Aggregator) Current() (Candle, bool) {
+	return a.current, a.started
+}
+
+// closeBefore closes the candles that start before the time, which leaves a
+// flat candle that starts at the time as the current candle.
+func (a *Aggregator) closeBefore(start time.Time) []Candle {
+	var closed []Candle
+
+	for a.current.Start.Before(start) {
+		c := a.current
+		c.Closed = true
+		closed = append(closed, c)
+
+		a.current = flat(c, c.Start.Add(a.interval))
+		a.current.Closed = false
+		a.traded = false
+	}
+
+	return closed
+}
+
+// CandleWindow holds the most recent candles of a pair with an interval, for
+// strategies to query. Candles that are not closed are held as the current
+// candle until they close, and missing intervals are filled with flat
+// candles. It is safe for concurrent use.
+type CandleWindow struct {
+	pair     trading.Pair
+	interval time.Duration
+	size     int
+
+	mu      sync.RWMutex
+	candles []Candle
+	current *Candle
+}
+
+// NewCandleWindow acts as the default constructor for the CandleWindow type.
+// The window holds up to size closed candles, dropping the oldest.
+func NewCandleWindow(pair trading.Pair, interval time.Duration, size int) *CandleWindow {
+	return &CandleWindow{
+		pair:     pair,
+		interval: interval,
+		size:     size,
+		candles:  make([]Candle, 0, size),
+	}
+}
+
+// Apply adds the candle of a Candle event to the window. Any other event is
+// ignored, so every event of a stream can be applied.
+func (w *CandleWindow) Apply(event Event) {
+	if c, ok := event.(Candle); ok {
+		w.Add(c)
+	}
+}
+
+// Add adds the candle to the window. Candles of other pairs or intervals, and
+// candles that are older than the last closed candle, are ignored.
+func (w *CandleWindow) Add(c Candle) {
+	if c.Pair != w.pair || c.Interval != w.interval {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(w.candles)
+	if n > 0 && !c.Start.After(w.candles[n-1].Start) {
+		return
+	}
+
+	if !c.Closed {
+		w.current = &c
+		return
+	}
+
+	if w.current != nil && !w.current.Start.After(c.Start) {
+		w.current = nil
+	}
+
+	if n > 0 {
+		w.candles = append(w.candles, FillGaps([]Candle{w.candles[n-1], c})[1:]...)
+	} else {
+		w.candles = append(w.candles, c)
+	}
+
+	if extra := len(w.candles) - w.size; extra > 0 {
+		w.candles = append(w.candles[:0], w.candles[extra:]...)
+	}
+}
+
+// Candles returns the closed candles of the window, oldest first.
+func (w *CandleWindow) Candles() []Candle {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return append([]Candle(nil), w.candles...)
+}
+
+// Last returns the latest closed candle, and whether there is one.
+func (w *CandleWindow) Last() (Candle, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.candles) == 0 {
+		return Candle{}, false
+	}
+
+	return w.candles[len(w.candles)-1], true
+}
+
+// Current returns the candle that is not closed yet, and whether there is
+// one.
+func (w *CandleWindow) Current() (Candle, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.current == nil {
+		return Candle{}, false
+	}
+
+	return *w.current, true
+}
+
+// Len returns the number of closed candles in the window.
+func (w *CandleWindow) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return len(w.candles)
+}
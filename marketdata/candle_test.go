@@ -0,0 +1,255 @@
+package marketdata_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+var candleTime = time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+
+// candle returns a closed candle of BTC/USD that starts at the offset from
+// the candle time.
+func candle(offset time.Duration, interval time.Duration, prices [4]string, volume string) marketdata.Candle {
+	return marketdata.Candle{
+		Pair:     trading.BTCUSD,
+		Start:    candleTime.Add(offset),
+		Interval: interval,
+		Open:     trading.MustParseAmount(prices[0]),
+		High:     trading.MustParseAmount(prices[1]),
+		Low:      trading.MustParseAmount(prices[2]),
+		Close:    trading.MustParseAmount(prices[3]),
+		Volume:   trading.MustParseAmount(volume),
+		Closed:   true,
+	}
+}
+
+// flatCandle returns a closed candle without trades at the price.
+func flatCandle(offset time.Duration, interval time.Duration, price string) marketdata.Candle {
+	c := candle(offset, interval, [4]string{price, price, price, price}, "0")
+	c.Volume = trading.Amount{}
+
+	return c
+}
+
+func trade(offset time.Duration, price string, size string) marketdata.Trade {
+	return marketdata.Trade{
+		Pair:  trading.BTCUSD,
+		Time:  candleTime.Add(offset),
+		Price: trading.MustParseAmount(price),
+		Size:  trading.MustParseAmount(size),
+	}
+}
+
+func TestFillGaps(t *testing.T) {
+	candles := []marketdata.Candle{
+		candle(0, time.Minute, [4]string{"100", "110", "90", "105"}, "1"),
+		candle(3*time.Minute, time.Minute, [4]string{"106", "107", "104", "104"}, "2"),
+		candle(4*time.Minute, time.Minute, [4]string{"104", "104", "101", "102"}, "3"),
+	}
+
+	assert.Equal(t, []marketdata.Candle{
+		candles[0],
+		flatCandle(time.Minute, time.Minute, "105"),
+		flatCandle(2*time.Minute, time.Minute, "105"),
+		candles[1],
+		candles[2],
+	}, marketdata.FillGaps(candles))
+
+	assert.Empty(t, marketdata.FillGaps(nil))
+}
+
+func TestResample(t *testing.T) {
+	candles := []marketdata.Candle{
+		candle(0, time.Minute, [4]string{"100", "110", "90", "105"}, "1"),
+		candle(time.Minute, time.Minute, [4]string{"105", "120", "95", "115"}, "2"),
+		candle(2*time.Minute, time.Minute, [4]string{"115", "116", "80", "85"}, "3"),
+		candle(3*time.Minute, time.Minute, [4]string{"85", "90", "85", "88"}, "0.5"),
+	}
+
+	// The last candle is not closed, so neither is the candle it is part of.
+	candles[3].Closed = false
+
+	expected := []marketdata.Candle{
+		candle(0, 2*time.Minute, [4]string{"100", "120", "90", "115"}, "3"),
+		candle(2*time.Minute, 2*time.Minute, [4]string{"115", "116", "80", "88"}, "3.5"),
+	}
+	expected[1].Closed = false
+
+	assert.Equal(t, expected, marketdata.Resample(candles, 2*time.Minute))
+}
+
+// candleSource is a CandleSource that offers one and five minute candles.
+type candleSource struct {
+	candles  []exchange.Candle
+	interval time.Duration
+	start    time.Time
+	end      time.Time
+}
+
+func (s *candleSource) CandleIntervals() []time.Duration {
+	return []time.Duration{time.Minute, 5 * time.Minute}
+}
+
+func (s *candleSource) GetCandles(
+	_ context.Context, _ trading.Pair, interval time.Duration, start time.Time, end time.Time,
+) ([]exchange.Candle, error) {
+	s.interval, s.start, s.end = interval, start, end
+
+	return s.candles, nil
+}
+
+func TestFetchCandles(t *testing.T) {
+	amount := trading.MustParseAmount
+
+	source := &candleSource{candles: []exchange.Candle{
+		{Start: candleTime, Open: amount("100"), High: amount("110"), Low: amount("90"), Close: amount("105"),
+			Volume: amount("1")},
+		{Start: candleTime.Add(5 * time.Minute), Open: amount("105"), High: amount("106"), Low: amount("100"),
+			Close: amount("101"), Volume: amount("2")},
+		{Start: candleTime.Add(20 * time.Minute), Open: amount("99"), High: amount("99"), Low: amount("95"),
+			Close: amount("96"), Volume: amount("4")},
+	}}
+
+	end := candleTime.Add(30 * time.Minute)
+
+	// Ten minute candles are built from five minute candles, starting from
+	// the start of the candle that the start time falls in.
+	candles, err := marketdata.FetchCandles(
+		context.Background(), source, trading.BTCUSD, 10*time.Minute, candleTime.Add(3*time.Minute), end,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, source.interval)
+	assert.Equal(t, candleTime, source.start)
+	assert.Equal(t, end, source.end)
+	assert.Equal(t, []marketdata.Candle{
+		candle(0, 10*time.Minute, [4]string{"100", "110", "90", "101"}, "3"),
+		flatCandle(10*time.Minute, 10*time.Minute, "101"),
+		// The candle is closed though its last five minutes are missing, as
+		// its interval has passed.
+		candle(20*time.Minute, 10*time.Minute, [4]string{"99", "99", "95", "96"}, "4"),
+	}, candles)
+
+	_, err = marketdata.FetchCandles(context.Background(), source, trading.BTCUSD, 90*time.Second, candleTime, end)
+	assert.ErrorIs(t, err, exchange.ErrUnsupportedInterval)
+}
+
+func TestFetchCandlesMissingLastMinute(t *testing.T) {
+	amount := trading.MustParseAmount
+	source := &oneMinuteSource{}
+
+	// Minute 4 had no trades, so the exchange leaves its candle out.
+	for _, m := range []int{0, 1, 2, 3, 5, 6, 7, 8, 9} {
+		price := amount("100").Add(amount(fmt.Sprint(m)))
+
+		source.candles = append(source.candles, exchange.Candle{
+			Start: candleTime.Add(time.Duration(m) * time.Minute),
+			Open:  price, High: price, Low: price, Close: price, Volume: amount("1"),
+		})
+	}
+
+	candles, err := marketdata.FetchCandles(
+		context.Background(), source, trading.BTCUSD, 5*time.Minute, candleTime, candleTime.Add(10*time.Minute),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []marketdata.Candle{
+		candle(0, 5*time.Minute, [4]string{"100", "103", "100", "103"}, "4"),
+		candle(5*time.Minute, 5*time.Minute, [4]string{"105", "109", "105", "109"}, "5"),
+	}, candles)
+}
+
+// oneMinuteSource is a CandleSource that only offers one minute candles.
+type oneMinuteSource struct {
+	candleSource
+}
+
+func (s *oneMinuteSource) CandleIntervals() []time.Duration {
+	return []time.Duration{time.Minute}
+}
+
+func TestAggregator(t *testing.T) {
+	a := marketdata.NewAggregator(trading.BTCUSD, time.Minute)
+
+	_, ok := a.Current()
+	assert.False(t, ok)
+
+	assert.Empty(t, a.Add(trade(10*time.Second, "100", "1")))
+	assert.Empty(t, a.Add(trade(20*time.Second, "110", "0.5")))
+	assert.Empty(t, a.Add(trade(30*time.Second, "95", "0.25")))
+	assert.Empty(t, a.Add(marketdata.Trade{Pair: trading.ETHUSD, Time: candleTime.Add(time.Hour)}))
+
+	current, ok := a.Current()
+	assert.True(t, ok)
+	assert.False(t, current.Closed)
+	assert.Equal(t, trading.MustParseAmount("95"), current.Close)
+
+	// A trade two minutes later closes the first candle, and fills the minute
+	// without trades.
+	assert.Equal(t, []marketdata.Candle{
+		candle(0, time.Minute, [4]string{"100", "110", "95", "95"}, "1.75"),
+		flatCandle(time.Minute, time.Minute, "95"),
+	}, a.Add(trade(2*time.Minute+time.Second, "97", "2")))
+
+	assert.Empty(t, a.Add(trade(time.Minute, "1", "1")), "older trades are ignored")
+	assert.Empty(t, a.Flush(candleTime.Add(2*time.Minute+30*time.Second)))
+
+	// Flushing closes the candle once its minute has passed, even without a
+	// trade.
+	assert.Equal(t, []marketdata.Candle{
+		candle(2*time.Minute, time.Minute, [4]string{"97", "97", "97", "97"}, "2"),
+		flatCandle(3*time.Minute, time.Minute, "97"),
+	}, a.Flush(candleTime.Add(4*time.Minute)))
+
+	current, ok = a.Current()
+	assert.True(t, ok)
+	assert.Equal(t, candleTime.Add(4*time.Minute), current.Start)
+}
+
+func TestCandleWindow(t *testing.T) {
+	w := marketdata.NewCandleWindow(trading.BTCUSD, time.Minute, 3)
+
+	_, ok := w.Last()
+	assert.False(t, ok)
+
+	open := candle(0, time.Minute, [4]string{"100", "101", "99", "100"}, "1")
+	open.Closed = false
+
+	w.Apply(open)
+	w.Apply(marketdata.Trade{})
+	w.Add(candle(0, time.Hour, [4]string{"1", "1", "1", "1"}, "1"))
+
+	current, ok := w.Current()
+	assert.True(t, ok)
+	assert.Equal(t, open, current)
+	assert.Zero(t, w.Len())
+
+	first := candle(0, time.Minute, [4]string{"100", "102", "99", "101"}, "2")
+	w.Apply(first)
+
+	_, ok = w.Current()
+	assert.False(t, ok, "the candle has closed")
+
+	last := candle(3*time.Minute, time.Minute, [4]string{"103", "104", "102", "104"}, "1")
+	w.Apply(last)
+	w.Apply(first)
+
+	// The oldest candle is dropped to make room for the gap to be filled.
+	assert.Equal(t, []marketdata.Candle{
+		flatCandle(time.Minute, time.Minute, "101"),
+		flatCandle(2*time.Minute, time.Minute, "101"),
+		last,
+	}, w.Candles())
+
+	latest, ok := w.Last()
+	assert.True(t, ok)
+	assert.Equal(t, last, latest)
+}
@@ -0,0 +1,458 @@
+package marketdata
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Coinbase represents a stream of the coinbase advanced trade websocket api.
+// The credentials are only needed for the user channel. A secret that holds
+// a PEM encoded EC private key, as with the keys of the coinbase developer
+// platform, is signed in to with a JWT, while any other secret signs the
+// subscription with HMAC as the legacy keys do.
+type Coinbase struct {
+	APIKey    string
+	APISecret string
+
+	opts    options
+	markets *trading.Registry
+}
+
+const (
+	coinbaseURL = "wss://advanced-trade-ws.coinbase.com"
+
+	// coinbaseIdle is how long to wait for a message, which is well over the
+	// second between heartbeats.
+	coinbaseIdle = 30 * time.Second
+)
+
+// coinbaseChannels maps the channels to the names of the coinbase channels.
+var coinbaseChannels = map[Channel]string{
+	ChannelTicker: "ticker",
+	ChannelBook:   "level2",
+	ChannelTrades: "market_trades",
+	ChannelUser:   "user",
+}
+
+// NewCoinbase acts as the default constructor for the Coinbase stream type.
+// The credentials are loaded from the same environment variables as the
+// exchange client, but unlike the client they are optional. Options can be
+// passed to override the URL and the markets.
+func NewCoinbase(opts ...Option) *Coinbase {
+	o := newOptions(coinbaseURL, opts)
+
+	return &Coinbase{
+		APIKey:    os.Getenv("COINBASE_API_KEY"),
+		APISecret: os.Getenv("COINBASE_API_SECRET"),
+		opts:      o,
+		markets: o.registry(func() *trading.Registry {
+			return trading.NewRegistry(
+				trading.Market{Pair: trading.BTCUSD, Symbol: "BTC-USD"},
+				trading.Market{Pair: trading.ETHUSD, Symbol: "ETH-USD"},
+			)
+		}),
+	}
+}
+
+// Subscribe connects to the websocket api and subscribes to the channels for
+// the pairs, along with the heartbeats that keep the connection open. The
+// events are sent on the returned channel until the context is cancelled.
+func (c *Coinbase) Subscribe(ctx context.Context, s Subscription) (<-chan Event, error) {
+	p := &coinbaseProtocol{
+		endpoint: c.opts.url,
+		markets:  c.markets,
+		channels: []string{"heartbeats"},
+	}
+
+	for _, pair := range s.Pairs {
+		m, err := c.markets.Market(pair)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", err, exchange.ErrMissingPair)
+		}
+
+		p.productIDs = append(p.productIDs, m.Symbol)
+	}
+
+	for _, ch := range s.Channels {
+		name, ok := coinbaseChannels[ch]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", ch, ErrUnknownChannel)
+		}
+
+		if ch == ChannelUser && (c.APIKey == "" || c.APISecret == "") {
+			return nil, fmt.Errorf("user channel: %w", exchange.ErrAPIKeyNotSet)
+		}
+
+		p.channels = append(p.channels, name)
+	}
+
+	signer, err := c.signer()
+	if err != nil {
+		return nil, err
+	}
+
+	p.sign = signer
+
+	return startStream(ctx, c.opts, p, limits{idle: coinbaseIdle})
+}
+
+// signer returns the function that signs the subscriptions with the
+// credentials, which leaves them unsigned if there are none.
+func (c *Coinbase) signer() (func(msg *coinbaseSubscribe) error, error) {
+	if c.APIKey == "" || c.APISecret == "" {
+		return func(*coinbaseSubscribe) error { return nil }, nil
+	}
+
+	if !strings.Contains(c.APISecret, "PRIVATE KEY") {
+		return c.signHMAC, nil
+	}
+
+	key, err := parseECKey(c.APISecret)
+	if err != nil {
+		return nil, fmt.Errorf("parse api secret: %w", err)
+	}
+
+	return func(msg *coinbaseSubscribe) error {
+		token, err := signJWT(key, c.APIKey, time.Now())
+		if err != nil {
+			return fmt.Errorf("sign jwt: %w", err)
+		}
+
+		msg.JWT = token
+
+		return nil
+	}, nil
+}
+
+// signHMAC signs the subscription with the timestamp, the channel and the
+// product IDs, in the same way as the requests of the legacy api keys.
+func (c *Coinbase) signHMAC(msg *coinbaseSubscribe) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	payload := timestamp + msg.Channel + strings.Join(msg.ProductIDs, ",")
+
+	hash := hmac.New(sha256.New, []byte(c.APISecret))
+	hash.Write([]byte(payload))
+
+	msg.APIKey = c.APIKey
+	msg.Timestamp = timestamp
+	msg.Signature = hex.EncodeToString(hash.Sum(nil))
+
+	return nil
+}
+
+// coinbaseSubscribe is the message that subscribes to a channel. Coinbase
+// only takes one channel per message.
+type coinbaseSubscribe struct {
+	Type       string   `json:"type"`
+	ProductIDs []string `json:"product_ids"`
+	Channel    string   `json:"channel"`
+	APIKey     string   `json:"api_key,omitempty"`
+	Timestamp  string   `json:"timestamp,omitempty"`
+	Signature  string   `json:"signature,omitempty"`
+	JWT        string   `json:"jwt,omitempty"`
+}
+
+// coinbaseMessage is the envelope of every message that coinbase sends. The
+// sequence number counts the messages of the connection across every
+// channel.
+type coinbaseMessage struct {
+	Type        string          `json:"type"`
+	Message     string          `json:"message"`
+	Channel     string          `json:"channel"`
+	Timestamp   time.Time       `json:"timestamp"`
+	SequenceNum int64           `json:"sequence_num"`
+	Events      json.RawMessage `json:"events"`
+}
+
+// coinbaseProtocol subscribes to the channels of the coinbase websocket api
+// and decodes its messages.
+type coinbaseProtocol struct {
+	endpoint   string
+	markets    *trading.Registry
+	productIDs []string
+	channels   []string
+	sign       func(msg *coinbaseSubscribe) error
+
+	started bool
+	next    int64
+}
+
+func (p *coinbaseProtocol) url(context.Context) (string, error) {
+	return p.endpoint, nil
+}
+
+func (p *coinbaseProtocol) subscribe(conn *websocket.Conn) error {
+	p.started = false
+
+	for _, channel := range p.channels {
+		msg := coinbaseSubscribe{
+			Type:       "subscribe",
+			ProductIDs: p.productIDs,
+			Channel:    channel,
+		}
+
+		if err := p.sign(&msg); err != nil {
+			return err
+		}
+
+		if err := conn.WriteJSON(msg); err != nil {
+			return fmt.Errorf("write %s subscription: %w", channel, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *coinbaseProtocol) handle(data []byte) ([]Event, error) {
+	var msg coinbaseMessage
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("decode message: %w", err)
+	}
+
+	if msg.Type == "error" {
+		return nil, fmt.Errorf("%s: %w", msg.Message, ErrRejected)
+	}
+
+	// Messages that are sent again are dropped, while a message that skips
+	// ahead means that some were missed, which leaves the book out of date.
+	if p.started && msg.SequenceNum < p.next {
+		return nil, nil
+	}
+
+	if p.started && msg.SequenceNum > p.next {
+		return nil, fmt.Errorf("expected message %d, got %d: %w", p.next, msg.SequenceNum, ErrSequenceGap)
+	}
+
+	p.started, p.next = true, msg.SequenceNum+1
+
+	switch msg.Channel {
+	case "ticker", "ticker_batch":
+		return p.tickers(msg)
+	case "l2_data":
+		return p.book(msg)
+	case "market_trades":
+		return p.trades(msg)
+	case "user":
+		return p.orders(msg)
+	default:
+		// Heartbeats and the confirmations of subscriptions hold no events.
+		return nil, nil
+	}
+}
+
+func (p *coinbaseProtocol) pair(productID string) (trading.Pair, error) {
+	m, err := p.markets.MarketBySymbol(productID)
+	if err != nil {
+		return trading.Pair{}, fmt.Errorf("%v: %w", err, exchange.ErrMissingPair)
+	}
+
+	return m.Pair, nil
+}
+
+func (p *coinbaseProtocol) tickers(msg coinbaseMessage) ([]Event, error) {
+	var events []struct {
+		Tickers []struct {
+			ProductID       string         `json:"product_id"`
+			Price           trading.Amount `json:"price"`
+			BestBid         trading.Amount `json:"best_bid"`
+			BestBidQuantity trading.Amount `json:"best_bid_quantity"`
+			BestAsk         trading.Amount `json:"best_ask"`
+			BestAskQuantity trading.Amount `json:"best_ask_quantity"`
+		} `json:"tickers"`
+	}
+
+	if err := json.Unmarshal(msg.Events, &events); err != nil {
+		return nil, fmt.Errorf("decode tickers: %w", err)
+	}
+
+	out := make([]Event, 0)
+
+	for _, e := range events {
+		for _, t := range e.Tickers {
+			pair, err := p.pair(t.ProductID)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, Ticker{
+				Pair:        pair,
+				Time:        msg.Timestamp,
+				Price:       t.Price,
+				BestBid:     t.BestBid,
+				BestBidSize: t.BestBidQuantity,
+				BestAsk:     t.BestAsk,
+				BestAskSize: t.BestAskQuantity,
+			})
+		}
+	}
+
+	return out, nil
+}
+
+func (p *coinbaseProtocol) book(msg coinbaseMessage) ([]Event, error) {
+	var events []struct {
+		Type      string `json:"type"`
+		ProductID string `json:"product_id"`
+		Updates   []struct {
+			Side        string         `json:"side"`
+			PriceLevel  trading.Amount `json:"price_level"`
+			NewQuantity trading.Amount `json:"new_quantity"`
+		} `json:"updates"`
+	}
+
+	if err := json.Unmarshal(msg.Events, &events); err != nil {
+		return nil, fmt.Errorf("decode book: %w", err)
+	}
+
+	out := make([]Event, 0, len(events))
+
+	for _, e := range events {
+		pair, err := p.pair(e.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		update := BookUpdate{
+			Pair:     pair,
+			Time:     msg.Timestamp,
+			Snapshot: e.Type == "snapshot",
+		}
+
+		for _, u := range e.Updates {
+			level := Level{Price: u.PriceLevel, Size: u.NewQuantity}
+
+			if u.Side == "bid" {
+				update.Bids = append(update.Bids, level)
+			} else {
+				update.Asks = append(update.Asks, level)
+			}
+		}
+
+		out = append(out, update)
+	}
+
+	return out, nil
+}
+
+func (p *coinbaseProtocol) trades(msg coinbaseMessage) ([]Event, error) {
+	var events []struct {
+		Trades []struct {
+			TradeID   string         `json:"trade_id"`
+			ProductID string         `json:"product_id"`
+			Price     trading.Amount `json:"price"`
+			Size      trading.Amount `json:"size"`
+			Side      string         `json:"side"`
+			Time      time.Time      `json:"time"`
+		} `json:"trades"`
+	}
+
+	if err := json.Unmarshal(msg.Events, &events); err != nil {
+		return nil, fmt.Errorf("decode trades: %w", err)
+	}
+
+	out := make([]Event, 0)
+
+	for _, e := range events {
+		for _, t := range e.Trades {
+			pair, err := p.pair(t.ProductID)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, Trade{
+				ID:    t.TradeID,
+				Pair:  pair,
+				Time:  t.Time,
+				Side:  order.Side(t.Side),
+				Price: t.Price,
+				Size:  t.Size,
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// coinbaseStatuses maps the statuses of coinbase orders which are not open to
+// the status of an order.
+var coinbaseStatuses = map[string]order.Status{
+	"FILLED":    order.StatusFilled,
+	"CANCELLED": order.StatusCancelled,
+	"EXPIRED":   order.StatusExpired,
+	"FAILED":    order.StatusRejected,
+}
+
+func (p *coinbaseProtocol) orders(msg coinbaseMessage) ([]Event, error) {
+	var events []struct {
+		Orders []struct {
+			OrderID            string         `json:"order_id"`
+			ClientOrderID      string         `json:"client_order_id"`
+			ProductID          string         `json:"product_id"`
+			OrderSide          string         `json:"order_side"`
+			Status             string         `json:"status"`
+			LimitPrice         trading.Amount `json:"limit_price"`
+			CumulativeQuantity trading.Amount `json:"cumulative_quantity"`
+			LeavesQuantity     trading.Amount `json:"leaves_quantity"`
+			AvgPrice           trading.Amount `json:"avg_price"`
+			TotalFees          trading.Amount `json:"total_fees"`
+			CreationTime       time.Time      `json:"creation_time"`
+		} `json:"orders"`
+	}
+
+	if err := json.Unmarshal(msg.Events, &events); err != nil {
+		return nil, fmt.Errorf("decode orders: %w", err)
+	}
+
+	out := make([]Event, 0)
+
+	for _, e := range events {
+		for _, o := range e.Orders {
+			// Orders on products that are not supported are still sent, as
+			// the client still knows them by their IDs.
+			pair, _ := p.pair(o.ProductID)
+
+			status, ok := coinbaseStatuses[o.Status]
+			if !ok {
+				status = order.OpenStatus(o.CumulativeQuantity)
+			}
+
+			update := exchange.Order{
+				ID:           o.OrderID,
+				Pair:         pair,
+				Side:         order.Side(o.OrderSide),
+				ClientID:     o.ClientOrderID,
+				BaseSize:     o.CumulativeQuantity.Add(o.LeavesQuantity),
+				Price:        o.LimitPrice,
+				Status:       status,
+				FilledSize:   o.CumulativeQuantity,
+				AveragePrice: o.AvgPrice,
+				Fee:          o.TotalFees,
+				CreatedAt:    o.CreationTime,
+				UpdatedAt:    msg.Timestamp,
+			}
+
+			if !o.TotalFees.IsZero() {
+				update.FeeAsset = pair.Quote
+			}
+
+			out = append(out, OrderUpdate{Order: update})
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,367 @@
+package marketdata_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata/marketdatatest"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+const timeout = time.Second
+
+func receive(t *testing.T, events <-chan marketdata.Event) marketdata.Event {
+	t.Helper()
+
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+type subscribeMessage struct {
+	Type       string   `json:"type"`
+	ProductIDs []string `json:"product_ids"`
+	Channel    string   `json:"channel"`
+	APIKey     string   `json:"api_key"`
+	Timestamp  string   `json:"timestamp"`
+	Signature  string   `json:"signature"`
+	JWT        string   `json:"jwt"`
+}
+
+func subscriptions(t *testing.T, server *marketdatatest.Server, n int) []subscribeMessage {
+	t.Helper()
+
+	messages := make([]subscribeMessage, 0, n)
+
+	for len(messages) < n {
+		select {
+		case m := <-server.Messages():
+			var msg subscribeMessage
+			assert.NoError(t, json.Unmarshal(m.Data, &msg))
+
+			messages = append(messages, msg)
+		case <-time.After(timeout):
+			t.Fatal("timed out waiting for subscriptions")
+		}
+	}
+
+	return messages
+}
+
+func newCoinbase(t *testing.T, server *marketdatatest.Server, key string, secret string) *marketdata.Coinbase {
+	t.Setenv("COINBASE_API_KEY", key)
+	t.Setenv("COINBASE_API_SECRET", secret)
+
+	return marketdata.NewCoinbase(
+		marketdata.WithURL(server.URL),
+		marketdata.WithReconnectWait(time.Millisecond, time.Millisecond),
+	)
+}
+
+func message(channel string, sequence int, events string) map[string]any {
+	return map[string]any{
+		"channel":      channel,
+		"timestamp":    "2023-02-09T20:30:37.167359596Z",
+		"sequence_num": sequence,
+		"events":       json.RawMessage(events),
+	}
+}
+
+func TestCoinbaseSubscribe(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := newCoinbase(t, server, "key", "secret")
+
+	_, err := c.Subscribe(ctx, marketdata.Subscription{
+		Pairs:    []trading.Pair{trading.BTCUSD, trading.ETHUSD},
+		Channels: []marketdata.Channel{marketdata.ChannelTicker, marketdata.ChannelBook, marketdata.ChannelUser},
+	})
+	assert.NoError(t, err)
+
+	messages := subscriptions(t, server, 4)
+
+	for i, channel := range []string{"heartbeats", "ticker", "level2", "user"} {
+		msg := messages[i]
+
+		assert.Equal(t, "subscribe", msg.Type)
+		assert.Equal(t, channel, msg.Channel)
+		assert.Equal(t, []string{"BTC-USD", "ETH-USD"}, msg.ProductIDs)
+		assert.Equal(t, "key", msg.APIKey)
+
+		hash := hmac.New(sha256.New, []byte("secret"))
+		hash.Write([]byte(msg.Timestamp + channel + "BTC-USD,ETH-USD"))
+		assert.Equal(t, hex.EncodeToString(hash.Sum(nil)), msg.Signature)
+	}
+}
+
+func TestCoinbaseSubscribeJWT(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	secret := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	name := "organizations/org/apiKeys/key"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The newlines of the key are escaped, as they are in a dotenv file.
+	c := newCoinbase(t, server, name, strings.ReplaceAll(secret, "\n", `\n`))
+
+	_, err = c.Subscribe(ctx, marketdata.Subscription{
+		Pairs:    []trading.Pair{trading.BTCUSD},
+		Channels: []marketdata.Channel{marketdata.ChannelUser},
+	})
+	assert.NoError(t, err)
+
+	messages := subscriptions(t, server, 2)
+	assert.Empty(t, messages[1].Signature)
+
+	parts := strings.Split(messages[1].JWT, ".")
+	assert.Len(t, parts, 3)
+
+	var claims struct {
+		Iss string `json:"iss"`
+		Sub string `json:"sub"`
+		Nbf int64  `json:"nbf"`
+		Exp int64  `json:"exp"`
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &claims))
+	assert.Equal(t, "cdp", claims.Iss)
+	assert.Equal(t, name, claims.Sub)
+	assert.Equal(t, int64(120), claims.Exp-claims.Nbf)
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+	assert.Len(t, sig, 64)
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	assert.True(t, ecdsa.Verify(&key.PublicKey, digest[:], r, s))
+}
+
+func TestCoinbaseSubscribeErrors(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	testCases := []struct {
+		name         string
+		key          string
+		subscription marketdata.Subscription
+		expected     error
+	}{
+		{
+			name:         "unknown pair",
+			key:          "key",
+			subscription: marketdata.Subscription{Pairs: []trading.Pair{{Base: "DOGE", Quote: "USD"}}},
+			expected:     exchange.ErrMissingPair,
+		},
+		{
+			name:         "unknown channel",
+			key:          "key",
+			subscription: marketdata.Subscription{Channels: []marketdata.Channel{"candles"}},
+			expected:     marketdata.ErrUnknownChannel,
+		},
+		{
+			name:         "user channel without credentials",
+			subscription: marketdata.Subscription{Channels: []marketdata.Channel{marketdata.ChannelUser}},
+			expected:     exchange.ErrAPIKeyNotSet,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoinbase(t, server, tt.key, "secret")
+
+			_, err := c.Subscribe(context.Background(), tt.subscription)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestCoinbaseEvents(t *testing.T) {
+	server := marketdatatest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := newCoinbase(t, server, "", "")
+
+	events, err := c.Subscribe(ctx, marketdata.Subscription{
+		Pairs:    []trading.Pair{trading.BTCUSD},
+		Channels: []marketdata.Channel{marketdata.ChannelTicker},
+	})
+	assert.NoError(t, err)
+
+	subscriptions(t, server, 2)
+
+	msgTime := time.Date(2023, 2, 9, 20, 30, 37, 167359596, time.UTC)
+
+	assert.NoError(t, server.Send(message("subscriptions", 0, `[]`)))
+	assert.NoError(t, server.Send(message("ticker", 1, `[{"type":"update","tickers":[{"type":"ticker",
+		"product_id":"BTC-USD","price":"21932.98","best_bid":"21921.73","best_bid_quantity":"0.06317902",
+		"best_ask":"21921.74","best_ask_quantity":"0.5"}]}]`)))
+	assert.NoError(t, server.Send(message("l2_data", 2, `[{"type":"snapshot","product_id":"BTC-USD","updates":[
+		{"side":"bid","price_level":"21921.73","new_quantity":"0.06317902"},
+		{"side":"offer","price_level":"21921.74","new_quantity":"0.5"}]}]`)))
+	assert.NoError(t, server.Send(message("heartbeats", 3, `[]`)))
+	assert.NoError(t, server.Send(message("market_trades", 4, `[{"type":"update","trades":[{"trade_id":"1",
+		"product_id":"BTC-USD","price":"21932.98","size":"0.1","side":"SELL","time":"2023-02-09T20:30:37Z"}]}]`)))
+	assert.NoError(t, server.Send(message("user", 5, `[{"type":"update","orders":[{"order_id":"abc",
+		"client_order_id":"go-trading-bot-1","product_id":"BTC-USD","order_side":"BUY","status":"OPEN",
+		"limit_price":"21000","cumulative_quantity":"0.4","leaves_quantity":"0.6","avg_price":"21000",
+		"total_fees":"1.5","creation_time":"2023-02-09T20:00:00Z"}]}]`)))
+
+	assert.Equal(t, marketdata.Ticker{
+		Pair:        trading.BTCUSD,
+		Time:        msgTime,
+		Price:       trading.MustParseAmount("21932.98"),
+		BestBid:     trading.MustParseAmount("21921.73"),
+		BestBidSize: trading.MustParseAmount("0.06317902"),
+		BestAsk:     trading.MustParseAmount("21921.74"),
+		BestAskSize: trading.MustParseAmount("0.5"),
+	}, receive(t, events))
+
+	assert.Equal(t, marketdata.BookUpdate{
+		Pair:     trading.BTCUSD,
+		Time:     msgTime,
+		Snapshot: true,
+		Bids:     []marketdata.Level{{Price: trading.MustParseAmount("21921.73"), Size: trading.MustParseAmount("0.06317902")}},
+		Asks:     []marketdata.Level{{Price: trading.MustParseAmount("21921.74"), Size: trading.MustParseAmount("0.5")}},
+	}, receive(t, events))
+
+	assert.Equal(t, marketdata.Trade{
+		ID:    "1",
+		Pair:  trading.BTCUSD,
+		Time:  time.Date(2023, 2, 9, 20, 30, 37, 0, time.UTC),
+		Side:  order.SideSell,
+		Price: trading.MustParseAmount("21932.98"),
+		Size:  trading.MustParseAmount("0.1"),
+	}, receive(t, events))
+
+	update, ok := receive(t, events).(marketdata.OrderUpdate)
+	assert.True(t, ok)
+	assert.Equal(t, "abc", update.Order.ID)
+	assert.Equal(t, "go-trading-bot-1", update.Order.ClientID)
+	assert.Equal(t, order.SideBuy, update.Order.Side)
+	assert.Equal(t, order.StatusPartiallyFilled, update.Order.Status)
+	assert.Zero(t, update.Order.BaseSize.Cmp(trading.MustParseAmount("1")))
+	assert.Zero(t, update.Order.FilledSize.Cmp(trading.MustParseAmount("0.4")))
+	assert.Equal(t, trading.USD, update.Order.FeeAsset)
+	assert.Equal(t, msgTime, update.Order.UpdatedAt)
+}
+
+func TestCoinbaseReconnect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		drop     func(server *marketdatatest.Server) error
+		expected error
+	}{
+		{
+			name: "sequence gap",
+			drop: func(server *marketdatatest.Server) error {
+				if err := server.Send(message("heartbeats", 0, `[]`)); err != nil {
+					return err
+				}
+
+				return server.Send(message("heartbeats", 2, `[]`))
+			},
+			expected: marketdata.ErrSequenceGap,
+		},
+		{
+			name: "error message",
+			drop: func(server *marketdatatest.Server) error {
+				return server.Send(map[string]string{"type": "error", "message": "authentication failure"})
+			},
+			expected: marketdata.ErrRejected,
+		},
+		{
+			name: "disconnected",
+			drop: func(server *marketdatatest.Server) error {
+				server.Disconnect()
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			server := marketdatatest.NewServer()
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			c := newCoinbase(t, server, "", "")
+
+			events, err := c.Subscribe(ctx, marketdata.Subscription{
+				Pairs:    []trading.Pair{trading.BTCUSD},
+				Channels: []marketdata.Channel{marketdata.ChannelBook},
+			})
+			assert.NoError(t, err)
+
+			subscriptions(t, server, 2)
+			assert.NoError(t, tt.drop(server))
+
+			reconnect, ok := receive(t, events).(marketdata.Reconnect)
+			assert.True(t, ok)
+			assert.Error(t, reconnect.Err)
+
+			if tt.expected != nil {
+				assert.ErrorIs(t, reconnect.Err, tt.expected)
+			}
+
+			// The stream subscribes again once it has reconnected, and starts
+			// counting the sequence from the new connection.
+			messages := subscriptions(t, server, 2)
+			assert.Equal(t, "level2", messages[1].Channel)
+
+			assert.NoError(t, server.Send(message("l2_data", 7, `[{"type":"snapshot","product_id":"BTC-USD"}]`)))
+			assert.IsType(t, marketdata.BookUpdate{}, receive(t, events))
+
+			cancel()
+
+			for range events {
+				// The channel is closed once the stream has stopped.
+			}
+		})
+	}
+}
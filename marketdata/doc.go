@@ -0,0 +1,6 @@
+// Package marketdata provides clients for the websocket apis of exchanges,
+// which stream market data and the updates of the orders of the account as
+// typed events, rather than the application polling for them. Candles can be
+// built from the stream of trades or fetched from the exchange, and held in a
+// rolling window for strategies to query.
+package marketdata
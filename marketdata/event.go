@@ -0,0 +1,182 @@
+package marketdata
+
+import (
+	"strings"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Channel represents a kind of data that a stream can be subscribed to. Each
+// exchange maps the channels to its own channel names.
+type Channel string
+
+const (
+	// ChannelTicker streams the last price and the best bid and ask of a
+	// pair as Ticker events.
+	ChannelTicker Channel = "ticker"
+
+	// ChannelBook streams the changes to the price levels of the order book
+	// of a pair as BookUpdate events.
+	ChannelBook Channel = "book"
+
+	// ChannelTrades streams the trades of a pair as Trade events.
+	ChannelTrades Channel = "trades"
+
+	// ChannelUser streams the changes to the orders of the account as
+	// OrderUpdate events, along with Fill, Balance and BalanceChange events on
+	// exchanges that send them. It needs the credentials of the account.
+	ChannelUser Channel = "user"
+)
+
+// candlePrefix is the prefix of the channels of candles, which are followed
+// by the interval of the candles.
+const candlePrefix = "candles:"
+
+// CandleChannel returns the channel that streams the candles of a pair with
+// the interval as Candle events.
+func CandleChannel(interval time.Duration) Channel {
+	return Channel(candlePrefix + interval.String())
+}
+
+// Interval returns the interval of the candles of the channel, and whether
+// the channel is a channel of candles.
+func (c Channel) Interval() (time.Duration, bool) {
+	if !strings.HasPrefix(string(c), candlePrefix) {
+		return 0, false
+	}
+
+	interval, err := time.ParseDuration(strings.TrimPrefix(string(c), candlePrefix))
+	if err != nil || interval <= 0 {
+		return 0, false
+	}
+
+	return interval, true
+}
+
+// Subscription represents the channels that a stream is subscribed to for
+// each of the pairs.
+type Subscription struct {
+	Pairs    []trading.Pair
+	Channels []Channel
+}
+
+// Event represents a message of a stream. Use a type switch to handle the
+// kinds of events that are of interest.
+type Event interface {
+	event()
+}
+
+// Ticker represents an event in which the last price or the best bid or ask
+// of a pair has changed. Exchanges that only stream the best bid and ask,
+// such as binance, leave the price as zero.
+type Ticker struct {
+	Pair        trading.Pair
+	Time        time.Time
+	Price       trading.Amount
+	BestBid     trading.Amount
+	BestBidSize trading.Amount
+	BestAsk     trading.Amount
+	BestAskSize trading.Amount
+}
+
+// Trade represents an event in which an order was matched on the exchange.
+// The side is that of the taker of the trade.
+type Trade struct {
+	ID    string
+	Pair  trading.Pair
+	Time  time.Time
+	Side  order.Side
+	Price trading.Amount
+	Size  trading.Amount
+}
+
+// Level represents the total size of the orders at a price in the book. In a
+// BookUpdate, a size of zero removes the price level from the book. It is the
+// level of the snapshots of the exchange clients, so that both can be applied
+// to the same book.
+type Level = exchange.Level
+
+// BookUpdate represents an event in which price levels of the order book of
+// a pair have changed. A snapshot replaces the book as a whole, while any
+// other update only sets the levels that it holds.
+type BookUpdate struct {
+	Pair     trading.Pair
+	Time     time.Time
+	Snapshot bool
+	Bids     []Level
+	Asks     []Level
+
+	// FirstUpdateID and LastUpdateID are the range of the IDs of the changes
+	// to the book that the update holds, on exchanges that number them such
+	// as binance. They are zero on any other exchange.
+	FirstUpdateID int64
+	LastUpdateID  int64
+}
+
+// Candle represents the open, high, low and close prices and the volume of a
+// pair over the interval that starts at Start. A candle that is not closed is
+// still being built, and is sent again as it changes.
+type Candle struct {
+	Pair     trading.Pair
+	Start    time.Time
+	Interval time.Duration
+	Open     trading.Amount
+	High     trading.Amount
+	Low      trading.Amount
+	Close    trading.Amount
+	Volume   trading.Amount
+	Closed   bool
+}
+
+// OrderUpdate represents an event in which an order of the account has
+// changed, such as when it has been filled.
+type OrderUpdate struct {
+	Order exchange.Order
+}
+
+// Fill represents an event in which an order of the account has been matched,
+// sent as soon as the exchange reports it.
+type Fill struct {
+	Fill exchange.Fill
+}
+
+// Balance represents an event in which the balance of an asset of the account
+// has changed. The free balance is what can be spent on new orders, while the
+// locked balance is held by open orders.
+type Balance struct {
+	Asset  trading.Asset
+	Time   time.Time
+	Free   trading.Amount
+	Locked trading.Amount
+}
+
+// BalanceChange represents an event in which the balance of an asset of the
+// account has changed by the delta outside of trading, such as by a deposit
+// or a withdrawal.
+type BalanceChange struct {
+	Asset trading.Asset
+	Time  time.Time
+	Delta trading.Amount
+}
+
+// Reconnect represents an event in which the stream has lost its connection
+// for the reason in Err. The stream connects again and resubscribes, so state
+// that was built from earlier events, such as an order book, should be
+// rebuilt from the snapshots that follow.
+type Reconnect struct {
+	Time time.Time
+	Err  error
+}
+
+func (Ticker) event()        {}
+func (Trade) event()         {}
+func (BookUpdate) event()    {}
+func (Candle) event()        {}
+func (OrderUpdate) event()   {}
+func (Fill) event()          {}
+func (Balance) event()       {}
+func (BalanceChange) event() {}
+func (Reconnect) event()     {}
@@ -0,0 +1,116 @@
+package marketdata
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidKey describes an error in which the api secret is not a PEM
+// encoded EC private key.
+var ErrInvalidKey = errors.New("invalid EC private key")
+
+const (
+	// jwtLifetime is how long a JWT is accepted for once it has been signed.
+	jwtLifetime = 2 * time.Minute
+
+	// es256Size is the size in bytes of each of the two halves of an ES256
+	// signature.
+	es256Size = 32
+
+	// nonceSize is the number of random bytes in the nonce of a JWT.
+	nonceSize = 16
+)
+
+// parseECKey parses a PEM encoded EC private key, in either the SEC 1 or the
+// PKCS 8 form. Newlines that have been escaped, as they are when the key is
+// kept in an environment variable, are unescaped first.
+func parseECKey(secret string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(secret, `\n`, "\n")))
+	if block == nil {
+		return nil, ErrInvalidKey
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidKey)
+	}
+
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	return key, nil
+}
+
+// signJWT returns a JWT for the key name that is signed with ES256, as the
+// keys of the coinbase developer platform sign in with.
+func signJWT(key *ecdsa.PrivateKey, name string, now time.Time) (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	header := map[string]string{
+		"alg":   "ES256",
+		"typ":   "JWT",
+		"kid":   name,
+		"nonce": hex.EncodeToString(nonce),
+	}
+
+	claims := map[string]any{
+		"iss": "cdp",
+		"sub": name,
+		"nbf": now.Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+	}
+
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", err
+	}
+
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	digest := sha256.Sum256([]byte(signingInput))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+
+	// ES256 signatures are the two halves of the signature, each padded to
+	// the same size, rather than the ASN.1 form.
+	sig := make([]byte, 2*es256Size)
+	r.FillBytes(sig[:es256Size])
+	s.FillBytes(sig[es256Size:])
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func encodeSegment(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("encode jwt: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,145 @@
+// Package marketdatatest provides a local websocket server that stands in for
+// the websocket api of an exchange, for testing streams without connecting
+// to the exchange.
+package marketdatatest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// messageBuffer is the number of messages from clients that the server holds
+// until they are received.
+const messageBuffer = 256
+
+// Message represents a message that the server has received from a client,
+// along with the request URI of the connection that it was sent on.
+type Message struct {
+	URI  string
+	Data []byte
+}
+
+// Server represents a websocket server that records the messages sent by its
+// clients, and sends them whatever messages a test gives it.
+type Server struct {
+	// URL is the websocket URL of the server, i.e. ws://127.0.0.1:1234.
+	URL string
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+	messages chan Message
+	uris     chan string
+
+	mu    sync.Mutex
+	conns []*websocket.Conn
+}
+
+// NewServer acts as the default constructor for the Server type. The server
+// is started straight away and must be closed once it is no longer needed.
+func NewServer() *Server {
+	s := &Server{
+		messages: make(chan Message, messageBuffer),
+		uris:     make(chan string, messageBuffer),
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = "ws" + strings.TrimPrefix(s.server.URL, "http")
+
+	return s
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	s.mu.Unlock()
+
+	s.uris <- r.URL.RequestURI()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			s.remove(conn)
+			return
+		}
+
+		s.messages <- Message{URI: r.URL.RequestURI(), Data: data}
+	}
+}
+
+func (s *Server) remove(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.conns {
+		if c == conn {
+			s.conns = append(s.conns[:i], s.conns[i+1:]...)
+			break
+		}
+	}
+
+	conn.Close()
+}
+
+// Messages returns the channel that the messages from clients are sent on,
+// in the order they were received.
+func (s *Server) Messages() <-chan Message {
+	return s.messages
+}
+
+// Connected returns the channel that the request URIs of the connections of
+// clients are sent on, once the server is able to send to them. Use it for
+// streams that send no messages, such as those that subscribe by their URL.
+func (s *Server) Connected() <-chan string {
+	return s.uris
+}
+
+// Send writes the message as JSON to every client that is connected.
+func (s *Server) Send(v any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		if err := conn.WriteJSON(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Connections returns the number of clients that are connected.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+// Disconnect drops the connection of every client, as an exchange does when
+// it restarts.
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+
+	s.conns = nil
+}
+
+// Close drops the connections of the clients and stops the server.
+func (s *Server) Close() {
+	s.Disconnect()
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
@@ -0,0 +1,106 @@
+package marketdata
+
+import (
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+const (
+	// defaultMinWait is the wait before the first attempt to reconnect.
+	defaultMinWait = time.Second
+
+	// defaultMaxWait is the longest wait between attempts to reconnect, which
+	// the wait doubles up to after each failed attempt.
+	defaultMaxWait = 30 * time.Second
+)
+
+// Option allows for overriding of the defaults of a stream. Use these to
+// point a stream at a sandbox or test server.
+type Option func(o *options)
+
+type options struct {
+	url      string
+	markets  *trading.Registry
+	minWait  time.Duration
+	maxWait  time.Duration
+	lifetime time.Duration
+	interval time.Duration
+}
+
+func newOptions(url string, opts []Option) options {
+	o := options{
+		url:     url,
+		minWait: defaultMinWait,
+		maxWait: defaultMaxWait,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// registry returns the market registry that has been given by the options,
+// or the default markets of the exchange if none has been given.
+func (o options) registry(defaults func() *trading.Registry) *trading.Registry {
+	if o.markets != nil {
+		return o.markets
+	}
+
+	return defaults()
+}
+
+// keepAlive returns the interval that has been given by the options, or the
+// default of the exchange if none has been given.
+func (o options) keepAlive(defaultInterval time.Duration) time.Duration {
+	if o.interval > 0 {
+		return o.interval
+	}
+
+	return defaultInterval
+}
+
+// WithURL overrides the URL of the websocket api. Use this method to point
+// the stream at a sandbox environment or a local test server.
+func WithURL(url string) Option {
+	return func(o *options) {
+		o.url = url
+	}
+}
+
+// WithMarkets overrides the markets that the stream supports, which maps
+// pairs to the exchange's symbols. Use the registry of the exchange client,
+// i.e. Coinbase.Markets, so that both agree on the pairs.
+func WithMarkets(markets *trading.Registry) Option {
+	return func(o *options) {
+		o.markets = markets
+	}
+}
+
+// WithReconnectWait overrides the wait before the stream reconnects, which
+// starts at min and doubles after each failed attempt up to max.
+func WithReconnectWait(min time.Duration, max time.Duration) Option {
+	return func(o *options) {
+		o.minWait = min
+		o.maxWait = max
+	}
+}
+
+// WithConnectionLifetime overrides how long the stream keeps a connection
+// before it reconnects, which by default is just under the time that the
+// exchange drops connections after, if it does.
+func WithConnectionLifetime(lifetime time.Duration) Option {
+	return func(o *options) {
+		o.lifetime = lifetime
+	}
+}
+
+// WithKeepAlive overrides how often the stream keeps its session on the
+// exchange alive, such as the listen key of the binance user data stream.
+func WithKeepAlive(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
@@ -0,0 +1,262 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrUnknownChannel describes an error in which a stream is subscribed to
+	// a channel that the exchange does not support.
+	ErrUnknownChannel = errors.New("channel not supported by exchange")
+
+	// ErrSequenceGap describes an error in which a message of a stream has
+	// been missed, found by a gap in the sequence numbers of the messages.
+	ErrSequenceGap = errors.New("gap in message sequence")
+
+	// ErrRejected describes an error in which the exchange has sent an error
+	// message, such as when the credentials of a subscription are not
+	// accepted.
+	ErrRejected = errors.New("rejected by exchange")
+
+	// ErrConnectionExpired describes an error in which a stream has dropped a
+	// connection that has reached its lifetime, ahead of the exchange
+	// dropping it.
+	ErrConnectionExpired = errors.New("connection reached its lifetime")
+)
+
+const (
+	// writeWait is how long a stream waits to write a control message, such
+	// as the pong to a ping from the exchange.
+	writeWait = 10 * time.Second
+
+	// eventBuffer is the number of events that a stream holds for a slow
+	// reader before it stops reading from the connection.
+	eventBuffer = 256
+)
+
+// protocol is the part of a stream that is specific to an exchange.
+type protocol interface {
+	// url returns the URL of the websocket api to connect to, which may need
+	// a request to the exchange, such as for a listen key.
+	url(ctx context.Context) (string, error)
+
+	// subscribe sends the subscription once a connection has been made. It
+	// is called for every connection, so it also resets any state that is
+	// kept per connection, such as the sequence number of the messages.
+	subscribe(conn *websocket.Conn) error
+
+	// handle decodes a message into the events that it holds. An error drops
+	// the connection, after which the stream reconnects.
+	handle(data []byte) ([]Event, error)
+}
+
+// limits are the timings of the connections of a stream.
+type limits struct {
+	// idle is how long a stream waits for a message or a ping before it
+	// treats the connection as lost. Exchanges send heartbeats or pings well
+	// within this time.
+	idle time.Duration
+
+	// lifetime is how long a connection is kept before the stream reconnects,
+	// ahead of exchanges that drop connections after a time. Zero keeps the
+	// connection for as long as it lasts.
+	lifetime time.Duration
+}
+
+// stream keeps a connection to a websocket api open, reconnecting and
+// resubscribing whenever the connection is lost, and sends the events of its
+// messages on a channel.
+type stream struct {
+	opts   options
+	proto  protocol
+	limits limits
+	events chan Event
+}
+
+// startStream connects to the websocket api and subscribes, returning the
+// channel that the events are sent on. The stream runs until the context is
+// cancelled, which closes the channel. An error is only returned if the first
+// connection fails, later failures are sent as Reconnect events. The lifetime
+// of the options overrides that of the limits.
+func startStream(ctx context.Context, o options, p protocol, l limits) (<-chan Event, error) {
+	if o.lifetime > 0 {
+		l.lifetime = o.lifetime
+	}
+
+	s := &stream{
+		opts:   o,
+		proto:  p,
+		limits: l,
+		events: make(chan Event, eventBuffer),
+	}
+
+	conn, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.run(ctx, conn)
+
+	return s.events, nil
+}
+
+func (s *stream) connect(ctx context.Context) (*websocket.Conn, error) {
+	endpoint, err := s.proto.url(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+
+	// A ping shows that the connection is alive, even when there are no
+	// messages, so it extends the wait for the next message.
+	conn.SetPingHandler(func(data string) error {
+		if err := conn.SetReadDeadline(time.Now().Add(s.limits.idle)); err != nil {
+			return err
+		}
+
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeWait))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return nil
+		}
+
+		return err
+	})
+
+	if err := s.proto.subscribe(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+
+	return conn, nil
+}
+
+func (s *stream) run(ctx context.Context, conn *websocket.Conn) {
+	defer close(s.events)
+
+	for conn != nil {
+		err := s.read(ctx, conn)
+		conn.Close()
+
+		if ctx.Err() != nil || !s.send(ctx, Reconnect{Time: time.Now(), Err: err}) {
+			return
+		}
+
+		conn = s.reconnect(ctx)
+	}
+}
+
+// reconnect connects again once the wait has passed, doubling the wait after
+// each failed attempt. It returns nil once the context is cancelled.
+func (s *stream) reconnect(ctx context.Context) *websocket.Conn {
+	wait := s.opts.minWait
+
+	for {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil
+		}
+
+		conn, err := s.connect(ctx)
+		if err == nil {
+			return conn
+		}
+
+		if !s.send(ctx, Reconnect{Time: time.Now(), Err: err}) {
+			return nil
+		}
+
+		wait *= 2
+		if wait > s.opts.maxWait {
+			wait = s.opts.maxWait
+		}
+	}
+}
+
+// read sends the events of the messages of the connection until it fails,
+// reaches its lifetime or the context is cancelled.
+func (s *stream) read(ctx context.Context, conn *websocket.Conn) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	expired := s.watch(ctx, conn, done)
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(s.limits.idle)); err != nil {
+			return fmt.Errorf("set read deadline: %w", err)
+		}
+
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-expired:
+				return ErrConnectionExpired
+			default:
+				return fmt.Errorf("read message: %w", err)
+			}
+		}
+
+		events, err := s.proto.handle(data)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if !s.send(ctx, e) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// watch closes the connection to unblock the read once the context is
+// cancelled or the connection reaches its lifetime, until done is closed. The
+// returned channel is closed if the connection reached its lifetime.
+func (s *stream) watch(ctx context.Context, conn *websocket.Conn, done <-chan struct{}) <-chan struct{} {
+	expired := make(chan struct{})
+
+	go func() {
+		var expiry <-chan time.Time
+
+		if s.limits.lifetime > 0 {
+			timer := time.NewTimer(s.limits.lifetime)
+			defer timer.Stop()
+
+			expiry = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-expiry:
+			close(expired)
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	return expired
+}
+
+// send reports whether the event was sent before the context was cancelled.
+func (s *stream) send(ctx context.Context, e Event) bool {
+	select {
+	case s.events <- e:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
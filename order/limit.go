@@ -1,18 +1,91 @@
 package order
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/project-code-io/crypto-trading-bot-go/trading"
 )
 
+// ErrInvalidTimeInForce describes an error in which the time in force of a
+// limit order is unknown, or cannot be combined with the other options of
+// the order.
+var ErrInvalidTimeInForce = errors.New("invalid time in force")
+
+// TimeInForce represents how long a limit order stays open on the exchange
+// before it is cancelled.
+type TimeInForce string
+
+const (
+	// TimeInForceGTC keeps the order open until it is filled or cancelled.
+	TimeInForceGTC TimeInForce = "GTC"
+
+	// TimeInForceGTD keeps the order open until it is filled, cancelled or
+	// the Expires time of the order has passed.
+	TimeInForceGTD TimeInForce = "GTD"
+
+	// TimeInForceIOC fills as much of the order as possible straight away and
+	// cancels the rest.
+	TimeInForceIOC TimeInForce = "IOC"
+
+	// TimeInForceFOK fills the whole of the order straight away, or cancels
+	// it if that is not possible.
+	TimeInForceFOK TimeInForce = "FOK"
+)
+
 // Limit represents an order that a limit type
 type Limit struct {
 	ClientID string
 	Pair     trading.Pair
 	Side     Side
-	BaseSize string
-	Price    string
+	BaseSize trading.Amount
+	Price    trading.Amount
 	PostOnly bool
 	Expires  *time.Time
+
+	// TimeInForce is how long the order stays open. If it is not set, the
+	// order is good till date when it has an expiry, and good till cancelled
+	// otherwise.
+	TimeInForce TimeInForce
+}
+
+// EffectiveTimeInForce returns the time in force of the order, taking into
+// account the default when it is not set.
+func (l Limit) EffectiveTimeInForce() TimeInForce {
+	switch {
+	case l.TimeInForce != "":
+		return l.TimeInForce
+	case l.Expires != nil:
+		return TimeInForceGTD
+	default:
+		return TimeInForceGTC
+	}
+}
+
+// Validate checks that the time in force of the order is known and can be
+// combined with the other options of the order. Post only orders can never
+// be immediate, and only good till date orders have an expiry, which they
+// must have.
+func (l Limit) Validate() error {
+	tif := l.EffectiveTimeInForce()
+
+	switch tif {
+	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK:
+		if l.Expires != nil {
+			return fmt.Errorf("%s with an expiry: %w", tif, ErrInvalidTimeInForce)
+		}
+	case TimeInForceGTD:
+		if l.Expires == nil {
+			return fmt.Errorf("%s without an expiry: %w", tif, ErrInvalidTimeInForce)
+		}
+	default:
+		return fmt.Errorf("%q: %w", tif, ErrInvalidTimeInForce)
+	}
+
+	if l.PostOnly && (tif == TimeInForceIOC || tif == TimeInForceFOK) {
+		return fmt.Errorf("post only with %s: %w", tif, ErrInvalidTimeInForce)
+	}
+
+	return nil
 }
@@ -0,0 +1,75 @@
+package order_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+)
+
+func TestLimitValidate(t *testing.T) {
+	expires := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type want struct {
+		tif order.TimeInForce
+		err error
+	}
+
+	testCases := []struct {
+		name  string
+		input order.Limit
+		wants want
+	}{
+		{
+			name:  "good till cancelled by default",
+			input: order.Limit{PostOnly: true},
+			wants: want{tif: order.TimeInForceGTC},
+		},
+		{
+			name:  "good till date by default with an expiry",
+			input: order.Limit{Expires: &expires},
+			wants: want{tif: order.TimeInForceGTD},
+		},
+		{
+			name:  "immediate or cancel",
+			input: order.Limit{TimeInForce: order.TimeInForceIOC},
+			wants: want{tif: order.TimeInForceIOC},
+		},
+		{
+			name:  "post only with immediate or cancel",
+			input: order.Limit{TimeInForce: order.TimeInForceIOC, PostOnly: true},
+			wants: want{tif: order.TimeInForceIOC, err: order.ErrInvalidTimeInForce},
+		},
+		{
+			name:  "post only with fill or kill",
+			input: order.Limit{TimeInForce: order.TimeInForceFOK, PostOnly: true},
+			wants: want{tif: order.TimeInForceFOK, err: order.ErrInvalidTimeInForce},
+		},
+		{
+			name:  "good till date without an expiry",
+			input: order.Limit{TimeInForce: order.TimeInForceGTD},
+			wants: want{tif: order.TimeInForceGTD, err: order.ErrInvalidTimeInForce},
+		},
+		{
+			name:  "good till cancelled with an expiry",
+			input: order.Limit{TimeInForce: order.TimeInForceGTC, Expires: &expires},
+			wants: want{tif: order.TimeInForceGTC, err: order.ErrInvalidTimeInForce},
+		},
+		{
+			name:  "unknown time in force",
+			input: order.Limit{TimeInForce: order.TimeInForce("DAY")},
+			wants: want{tif: order.TimeInForce("DAY"), err: order.ErrInvalidTimeInForce},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wants.tif, tt.input.EffectiveTimeInForce())
+			assert.ErrorIs(t, tt.input.Validate(), tt.wants.err)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package order
+
+import (
+	"errors"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+var (
+	// ErrMarketSize describes an error in which a market order does not have
+	// exactly one of a base size and a quote size.
+	ErrMarketSize = errors.New("market order needs either a base size or a quote size")
+
+	// ErrNegativeAmount describes an error in which an amount of an order is
+	// below zero.
+	ErrNegativeAmount = errors.New("amount must not be negative")
+)
+
+// Market represents an order that is filled straight away at the best price
+// available on the exchange. The size of the order is given by either the
+// BaseSize or the QuoteSize, but never both.
+type Market struct {
+	ClientID string
+	Pair     trading.Pair
+	Side     Side
+
+	// BaseSize is the amount of the base asset to buy or sell.
+	BaseSize trading.Amount
+
+	// QuoteSize is the amount of the quote asset to spend on a buy, or to
+	// receive from a sell.
+	QuoteSize trading.Amount
+
+	// PriceLimit protects the order from slippage, as the worst price that
+	// the order may be filled at, which is the highest price for a buy and the
+	// lowest price for a sell. Any part of the order that cannot be filled
+	// within the limit is cancelled. A zero limit leaves the order
+	// unprotected.
+	PriceLimit trading.Amount
+}
+
+// Validate checks that the order has exactly one of a base size and a quote
+// size, and that none of its amounts are negative.
+func (m Market) Validate() error {
+	if m.BaseSize.Sign() < 0 || m.QuoteSize.Sign() < 0 || m.PriceLimit.Sign() < 0 {
+		return ErrNegativeAmount
+	}
+
+	if m.BaseSize.IsZero() == m.QuoteSize.IsZero() {
+		return ErrMarketSize
+	}
+
+	return nil
+}
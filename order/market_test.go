@@ -0,0 +1,50 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestMarketValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    order.Market
+		expected error
+	}{
+		{
+			name:  "base size",
+			input: order.Market{BaseSize: trading.MustParseAmount("0.01")},
+		},
+		{
+			name:  "quote size with a price limit",
+			input: order.Market{QuoteSize: trading.MustParseAmount("10"), PriceLimit: trading.MustParseAmount("100")},
+		},
+		{
+			name:     "no size",
+			input:    order.Market{},
+			expected: order.ErrMarketSize,
+		},
+		{
+			name:     "both sizes",
+			input:    order.Market{BaseSize: trading.MustParseAmount("0.01"), QuoteSize: trading.MustParseAmount("10")},
+			expected: order.ErrMarketSize,
+		},
+		{
+			name:     "negative price limit",
+			input:    order.Market{BaseSize: trading.MustParseAmount("0.01"), PriceLimit: trading.MustParseAmount("-1")},
+			expected: order.ErrNegativeAmount,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.input.Validate(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+var (
+	// ErrInvalidPrice describes an error in which the price of an order is
+	// not above zero once it has been rounded to the tick size.
+	ErrInvalidPrice = errors.New("price must be above zero")
+
+	// ErrBelowMinSize describes an error in which the base size of an order
+	// is below the minimum size of the market once it has been rounded to
+	// the lot size.
+	ErrBelowMinSize = errors.New("size is below the minimum size")
+
+	// ErrInvalidDirection describes an error in which a stop order does not
+	// have a known direction.
+	ErrInvalidDirection = errors.New("unknown stop direction")
+
+	// ErrBelowMinNotional describes an error in which the value of an order is
+	// below the minimum notional of the market.
+	ErrBelowMinNotional = errors.New("value is below the minimum notional")
+)
+
+// Rounding holds the rounding modes that are used for the price and the base
+// size of an order.
+type Rounding struct {
+	Price trading.RoundingMode
+	Size  trading.RoundingMode
+}
+
+// Normalizer rounds orders to the filters of a market and rejects those that
+// the exchange would not accept, before they are placed.
+type Normalizer struct {
+	// Buy is the rounding of buy orders.
+	Buy Rounding
+
+	// Sell is the rounding of sell orders.
+	Sell Rounding
+}
+
+// NewNormalizer acts as the default constructor for the Normalizer type. By
+// default orders are rounded in the favour of the trader, buy prices down and
+// sell prices up, while sizes are always rounded down so that an order never
+// uses more funds than were intended.
+func NewNormalizer() *Normalizer {
+	return &Normalizer{
+		Buy:  Rounding{Price: trading.RoundDown, Size: trading.RoundDown},
+		Sell: Rounding{Price: trading.RoundUp, Size: trading.RoundDown},
+	}
+}
+
+// Normalize rounds the price of the order to the tick size of the market and
+// the base size to the lot size. Filters that the market does not have fall
+// back to the decimal places of the assets of the pair. The rounded order is
+// returned, or an error if it is below the minimum size or notional of the
+// market, or if its time in force is invalid.
+func (n *Normalizer) Normalize(m trading.Market, o Limit) (Limit, error) {
+	if err := o.Validate(); err != nil {
+		return Limit{}, err
+	}
+
+	rounding := n.Buy
+	if o.Side == SideSell {
+		rounding = n.Sell
+	}
+
+	o.Price = roundTo(o.Price, m.TickSize, m.Pair.Quote, rounding.Price)
+	o.BaseSize = roundTo(o.BaseSize, m.LotSize, m.Pair.Base, rounding.Size)
+
+	if o.Price.Sign() <= 0 {
+		return Limit{}, fmt.Errorf("price %s: %w", o.Price, ErrInvalidPrice)
+	}
+
+	if o.BaseSize.Sign() <= 0 || o.BaseSize.Cmp(m.MinSize) < 0 {
+		return Limit{}, fmt.Errorf("size %s, minimum %s: %w", o.BaseSize, m.MinSize, ErrBelowMinSize)
+	}
+
+	if value := o.Price.Mul(o.BaseSize); value.Cmp(m.MinNotional) < 0 {
+		return Limit{}, fmt.Errorf("value %s, minimum %s: %w", value, m.MinNotional, ErrBelowMinNotional)
+	}
+
+	return o, nil
+}
+
+// NormalizeMarket rounds the base size of the market order to the lot size
+// of the market, the quote size to the decimal places of the quote asset and
+// the price limit to the tick size. The rounded order is returned, or an
+// error if it is below the minimum size or notional of the market.
+func (n *Normalizer) NormalizeMarket(m trading.Market, o Market) (Market, error) {
+	if err := o.Validate(); err != nil {
+		return Market{}, err
+	}
+
+	rounding := n.Buy
+	if o.Side == SideSell {
+		rounding = n.Sell
+	}
+
+	if !o.PriceLimit.IsZero() {
+		o.PriceLimit = roundTo(o.PriceLimit, m.TickSize, m.Pair.Quote, rounding.Price)
+
+		if o.PriceLimit.Sign() <= 0 {
+			return Market{}, fmt.Errorf("price limit %s: %w", o.PriceLimit, ErrInvalidPrice)
+		}
+	}
+
+	if o.QuoteSize.IsZero() {
+		o.BaseSize = roundTo(o.BaseSize, m.LotSize, m.Pair.Base, rounding.Size)
+
+		if o.BaseSize.Sign() <= 0 || o.BaseSize.Cmp(m.MinSize) < 0 {
+			return Market{}, fmt.Errorf("size %s, minimum %s: %w", o.BaseSize, m.MinSize, ErrBelowMinSize)
+		}
+
+		return o, nil
+	}
+
+	o.QuoteSize = roundTo(o.QuoteSize, trading.Amount{}, m.Pair.Quote, rounding.Size)
+
+	if o.QuoteSize.Sign() <= 0 || o.QuoteSize.Cmp(m.MinNotional) < 0 {
+		return Market{}, fmt.Errorf("value %s, minimum %s: %w", o.QuoteSize, m.MinNotional, ErrBelowMinNotional)
+	}
+
+	return o, nil
+}
+
+// NormalizeStopLimit normalizes the limit order of the stop, along with
+// rounding the stop price to the tick size of the market.
+func (n *Normalizer) NormalizeStopLimit(m trading.Market, o StopLimit) (StopLimit, error) {
+	stopPrice, err := n.normalizeStop(m, o.Side, o.StopPrice, o.Direction)
+	if err != nil {
+		return StopLimit{}, err
+	}
+
+	l, err := n.Normalize(m, o.Limit())
+	if err != nil {
+		return StopLimit{}, err
+	}
+
+	o.StopPrice, o.BaseSize, o.Price = stopPrice, l.BaseSize, l.Price
+
+	return o, nil
+}
+
+// NormalizeStopMarket normalizes the market order of the stop, along with
+// rounding the stop price to the tick size of the market.
+func (n *Normalizer) NormalizeStopMarket(m trading.Market, o StopMarket) (StopMarket, error) {
+	stopPrice, err := n.normalizeStop(m, o.Side, o.StopPrice, o.Direction)
+	if err != nil {
+		return StopMarket{}, err
+	}
+
+	mo, err := n.NormalizeMarket(m, o.Market())
+	if err != nil {
+		return StopMarket{}, err
+	}
+
+	o.StopPrice, o.BaseSize = stopPrice, mo.BaseSize
+
+	return o, nil
+}
+
+// NormalizeOCO normalizes both legs of the OCO order to the filters of the
+// market, after checking that the take profit is beyond the stop price.
+func (n *Normalizer) NormalizeOCO(m trading.Market, o OCO) (OCO, error) {
+	if err := o.Validate(); err != nil {
+		return OCO{}, err
+	}
+
+	tp, err := n.Normalize(m, o.TakeProfitLimit())
+	if err != nil {
+		return OCO{}, err
+	}
+
+	if o.StopLimitPrice.IsZero() {
+		sl, err := n.NormalizeStopMarket(m, o.StopLossMarket())
+		if err != nil {
+			return OCO{}, err
+		}
+
+		o.StopPrice = sl.StopPrice
+	} else {
+		sl, err := n.NormalizeStopLimit(m, o.StopLossLimit())
+		if err != nil {
+			return OCO{}, err
+		}
+
+		o.StopPrice, o.StopLimitPrice = sl.StopPrice, sl.Price
+	}
+
+	o.BaseSize, o.TakeProfit = tp.BaseSize, tp.Price
+
+	return o, nil
+}
+
+// NormalizeTrailingStop rounds the base size of the trailing stop to the lot
+// size of the market, and the trail amount and limit offset to the tick size.
+// The stop price itself is only known once the stop is triggered, at which
+// point the order that exits is normalized.
+func (n *Normalizer) NormalizeTrailingStop(m trading.Market, o TrailingStop) (TrailingStop, error) {
+	if err := o.Validate(); err != nil {
+		return TrailingStop{}, err
+	}
+
+	mo, err := n.NormalizeMarket(m, o.Market())
+	if err != nil {
+		return TrailingStop{}, err
+	}
+
+	o.BaseSize = mo.BaseSize
+
+	if !o.TrailAmount.IsZero() {
+		o.TrailAmount = roundTo(o.TrailAmount, m.TickSize, m.Pair.Quote, trading.RoundHalfUp)
+		if o.TrailAmount.Sign() <= 0 {
+			return TrailingStop{}, fmt.Errorf("trail amount %s: %w", o.TrailAmount, ErrInvalidTrail)
+		}
+	}
+
+	o.LimitOffset = roundTo(o.LimitOffset, m.TickSize, m.Pair.Quote, trading.RoundHalfUp)
+
+	return o, nil
+}
+
+// normalizeStop checks the direction of a stop order and rounds its stop
+// price to the tick size of the market.
+func (n *Normalizer) normalizeStop(
+	m trading.Market, side Side, stopPrice trading.Amount, d Direction,
+) (trading.Amount, error) {
+	if !d.Valid() {
+		return trading.Amount{}, fmt.Errorf("direction %q: %w", d, ErrInvalidDirection)
+	}
+
+	rounding := n.Buy
+	if side == SideSell {
+		rounding = n.Sell
+	}
+
+	stopPrice = roundTo(stopPrice, m.TickSize, m.Pair.Quote, rounding.Price)
+	if stopPrice.Sign() <= 0 {
+		return trading.Amount{}, fmt.Errorf("stop price %s: %w", stopPrice, ErrInvalidPrice)
+	}
+
+	return stopPrice, nil
+}
+
+// roundTo rounds the amount to the step, or to the decimal places of the
+// asset if there is no step. Without either the amount is left as is.
+func roundTo(amount, step trading.Amount, asset trading.Asset, mode trading.RoundingMode) trading.Amount {
+	switch {
+	case step.Sign() > 0:
+		return amount.RoundToStep(step, mode)
+	case asset.Known():
+		return asset.Round(amount, mode)
+	default:
+		return amount
+	}
+}
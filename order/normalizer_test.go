@@ -0,0 +1,413 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestNormalizerNormalize(t *testing.T) {
+	market := trading.Market{
+		Pair:        trading.BTCUSD,
+		Symbol:      "BTCUSD",
+		TickSize:    trading.MustParseAmount("0.01"),
+		LotSize:     trading.MustParseAmount("0.00001"),
+		MinSize:     trading.MustParseAmount("0.0001"),
+		MinNotional: trading.MustParseAmount("10"),
+	}
+
+	type want struct {
+		order order.Limit
+		err   error
+	}
+
+	testCases := []struct {
+		name   string
+		market trading.Market
+		input  order.Limit
+		wants  want
+	}{
+		{
+			name:   "buy price and size are rounded down",
+			market: market,
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("0.001239"),
+				Price:    trading.MustParseAmount("17000.129"),
+				PostOnly: true,
+			},
+			wants: want{
+				order: order.Limit{
+					Pair:     trading.BTCUSD,
+					Side:     order.SideBuy,
+					BaseSize: trading.MustParseAmount("0.00123"),
+					Price:    trading.MustParseAmount("17000.12"),
+					PostOnly: true,
+				},
+			},
+		},
+		{
+			name:   "sell price is rounded up and size down",
+			market: market,
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.001239"),
+				Price:    trading.MustParseAmount("17000.121"),
+			},
+			wants: want{
+				order: order.Limit{
+					Pair:     trading.BTCUSD,
+					Side:     order.SideSell,
+					BaseSize: trading.MustParseAmount("0.00123"),
+					Price:    trading.MustParseAmount("17000.13"),
+				},
+			},
+		},
+		{
+			name:   "market without filters rounds to the asset decimals",
+			market: trading.Market{Pair: trading.BTCUSD},
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("0.000000019"),
+				Price:    trading.MustParseAmount("0.019"),
+			},
+			wants: want{
+				order: order.Limit{
+					Pair:     trading.BTCUSD,
+					Side:     order.SideBuy,
+					BaseSize: trading.MustParseAmount("0.00000001"),
+					Price:    trading.MustParseAmount("0.01"),
+				},
+			},
+		},
+		{
+			name:   "price rounded to zero",
+			market: market,
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("1"),
+				Price:    trading.MustParseAmount("0.009"),
+			},
+			wants: want{
+				err: order.ErrInvalidPrice,
+			},
+		},
+		{
+			name:   "size below the minimum size",
+			market: market,
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("0.00009"),
+				Price:    trading.MustParseAmount("170000"),
+			},
+			wants: want{
+				err: order.ErrBelowMinSize,
+			},
+		},
+		{
+			name:   "size rounded to zero without a minimum size",
+			market: trading.Market{Pair: trading.BTCUSD, LotSize: trading.MustParseAmount("0.01")},
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("0.009"),
+				Price:    trading.MustParseAmount("17000"),
+			},
+			wants: want{
+				err: order.ErrBelowMinSize,
+			},
+		},
+		{
+			name:   "value below the minimum notional",
+			market: market,
+			input: order.Limit{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideBuy,
+				BaseSize: trading.MustParseAmount("0.0005"),
+				Price:    trading.MustParseAmount("17000"),
+			},
+			wants: want{
+				err: order.ErrBelowMinNotional,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := order.NewNormalizer().Normalize(tt.market, tt.input)
+
+			assert.ErrorIs(t, err, tt.wants.err)
+			assert.Equal(t, tt.wants.order, res)
+		})
+	}
+}
+
+func TestNormalizerRounding(t *testing.T) {
+	n := &order.Normalizer{
+		Buy: order.Rounding{Price: trading.RoundUp, Size: trading.RoundUp},
+	}
+
+	res, err := n.Normalize(trading.Market{Pair: trading.BTCUSD, TickSize: trading.MustParseAmount("0.5")}, order.Limit{
+		Pair:     trading.BTCUSD,
+		Side:     order.SideBuy,
+		BaseSize: trading.MustParseAmount("0.000000001"),
+		Price:    trading.MustParseAmount("100.1"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, trading.MustParseAmount("0.00000001"), res.BaseSize)
+	assert.Equal(t, trading.MustParseAmount("100.5"), res.Price)
+}
+
+func TestNormalizerNormalizeMarket(t *testing.T) {
+	market := trading.Market{
+		Pair:        trading.BTCUSD,
+		Symbol:      "BTCUSD",
+		TickSize:    trading.MustParseAmount("0.01"),
+		LotSize:     trading.MustParseAmount("0.00001"),
+		MinSize:     trading.MustParseAmount("0.0001"),
+		MinNotional: trading.MustParseAmount("10"),
+	}
+
+	type want struct {
+		order order.Market
+		err   error
+	}
+
+	testCases := []struct {
+		name  string
+		input order.Market
+		wants want
+	}{
+		{
+			name: "base size and sell price limit are rounded",
+			input: order.Market{
+				Pair:       trading.BTCUSD,
+				Side:       order.SideSell,
+				BaseSize:   trading.MustParseAmount("0.001239"),
+				PriceLimit: trading.MustParseAmount("16500.001"),
+			},
+			wants: want{
+				order: order.Market{
+					Pair:       trading.BTCUSD,
+					Side:       order.SideSell,
+					BaseSize:   trading.MustParseAmount("0.00123"),
+					PriceLimit: trading.MustParseAmount("16500.01"),
+				},
+			},
+		},
+		{
+			name: "quote size is rounded to the quote decimals",
+			input: order.Market{
+				Pair:      trading.BTCUSD,
+				Side:      order.SideBuy,
+				QuoteSize: trading.MustParseAmount("25.509"),
+			},
+			wants: want{
+				order: order.Market{
+					Pair:      trading.BTCUSD,
+					Side:      order.SideBuy,
+					QuoteSize: trading.MustParseAmount("25.5"),
+				},
+			},
+		},
+		{
+			name: "base size below the minimum size",
+			input: order.Market{
+				Pair:     trading.BTCUSD,
+				Side:     order.SideSell,
+				BaseSize: trading.MustParseAmount("0.00009"),
+			},
+			wants: want{
+				err: order.ErrBelowMinSize,
+			},
+		},
+		{
+			name: "quote size below the minimum notional",
+			input: order.Market{
+				Pair:      trading.BTCUSD,
+				Side:      order.SideBuy,
+				QuoteSize: trading.MustParseAmount("9.99"),
+			},
+			wants: want{
+				err: order.ErrBelowMinNotional,
+			},
+		},
+		{
+			name: "no size",
+			input: order.Market{
+				Pair: trading.BTCUSD,
+				Side: order.SideBuy,
+			},
+			wants: want{
+				err: order.ErrMarketSize,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := order.NewNormalizer().NormalizeMarket(market, tt.input)
+
+			assert.ErrorIs(t, err, tt.wants.err)
+			assert.Equal(t, tt.wants.order, res)
+		})
+	}
+}
+
+func TestNormalizerNormalizeStop(t *testing.T) {
+	market := trading.Market{
+		Pair:     trading.BTCUSD,
+		TickSize: trading.MustParseAmount("0.5"),
+		LotSize:  trading.MustParseAmount("0.001"),
+	}
+
+	n := order.NewNormalizer()
+
+	stopLimit, err := n.NormalizeStopLimit(market, order.StopLimit{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.0129"),
+		Price:     trading.MustParseAmount("15999.1"),
+		StopPrice: trading.MustParseAmount("16000.2"),
+		Direction: order.DirectionBelow,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.StopLimit{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.012"),
+		Price:     trading.MustParseAmount("15999.5"),
+		StopPrice: trading.MustParseAmount("16000.5"),
+		Direction: order.DirectionBelow,
+	}, stopLimit)
+
+	stopMarket, err := n.NormalizeStopMarket(market, order.StopMarket{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideBuy,
+		BaseSize:  trading.MustParseAmount("0.0129"),
+		StopPrice: trading.MustParseAmount("18000.2"),
+		Direction: order.DirectionAbove,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.StopMarket{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideBuy,
+		BaseSize:  trading.MustParseAmount("0.012"),
+		StopPrice: trading.MustParseAmount("18000"),
+		Direction: order.DirectionAbove,
+	}, stopMarket)
+
+	_, err = n.NormalizeStopMarket(market, order.StopMarket{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideBuy,
+		BaseSize:  trading.MustParseAmount("1"),
+		StopPrice: trading.MustParseAmount("18000"),
+	})
+
+	assert.ErrorIs(t, err, order.ErrInvalidDirection)
+
+	_, err = n.NormalizeStopLimit(market, order.StopLimit{
+		Pair:      trading.BTCUSD,
+		Side:      order.SideBuy,
+		BaseSize:  trading.MustParseAmount("1"),
+		Price:     trading.MustParseAmount("18000"),
+		StopPrice: trading.MustParseAmount("0.2"),
+		Direction: order.DirectionAbove,
+	})
+
+	assert.ErrorIs(t, err, order.ErrInvalidPrice)
+}
+
+func TestNormalizerNormalizeOCO(t *testing.T) {
+	market := trading.Market{
+		Pair:     trading.BTCUSD,
+		TickSize: trading.MustParseAmount("0.5"),
+		LotSize:  trading.MustParseAmount("0.001"),
+	}
+
+	res, err := order.NewNormalizer().NormalizeOCO(market, order.OCO{
+		Pair:           trading.BTCUSD,
+		Side:           order.SideSell,
+		BaseSize:       trading.MustParseAmount("0.0129"),
+		TakeProfit:     trading.MustParseAmount("20000.2"),
+		StopPrice:      trading.MustParseAmount("16000.2"),
+		StopLimitPrice: trading.MustParseAmount("15900.2"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.OCO{
+		Pair:           trading.BTCUSD,
+		Side:           order.SideSell,
+		BaseSize:       trading.MustParseAmount("0.012"),
+		TakeProfit:     trading.MustParseAmount("20000.5"),
+		StopPrice:      trading.MustParseAmount("16000.5"),
+		StopLimitPrice: trading.MustParseAmount("15900.5"),
+	}, res)
+
+	_, err = order.NewNormalizer().NormalizeOCO(market, order.OCO{
+		Pair:       trading.BTCUSD,
+		Side:       order.SideSell,
+		BaseSize:   trading.MustParseAmount("1"),
+		TakeProfit: trading.MustParseAmount("15000"),
+		StopPrice:  trading.MustParseAmount("16000"),
+	})
+
+	assert.ErrorIs(t, err, order.ErrInvalidOCO)
+}
+
+func TestNormalizerNormalizeTrailingStop(t *testing.T) {
+	market := trading.Market{
+		Pair:     trading.BTCUSD,
+		TickSize: trading.MustParseAmount("0.5"),
+		LotSize:  trading.MustParseAmount("0.001"),
+		MinSize:  trading.MustParseAmount("0.01"),
+	}
+
+	res, err := order.NewNormalizer().NormalizeTrailingStop(market, order.TrailingStop{
+		Pair:        trading.BTCUSD,
+		Side:        order.SideSell,
+		BaseSize:    trading.MustParseAmount("0.0129"),
+		TrailAmount: trading.MustParseAmount("100.3"),
+		LimitOffset: trading.MustParseAmount("10.2"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, order.TrailingStop{
+		Pair:        trading.BTCUSD,
+		Side:        order.SideSell,
+		BaseSize:    trading.MustParseAmount("0.012"),
+		TrailAmount: trading.MustParseAmount("100.5"),
+		LimitOffset: trading.MustParseAmount("10"),
+	}, res)
+
+	_, err = order.NewNormalizer().NormalizeTrailingStop(market, order.TrailingStop{
+		Pair:        trading.BTCUSD,
+		Side:        order.SideSell,
+		BaseSize:    trading.MustParseAmount("1"),
+		TrailAmount: trading.MustParseAmount("0.2"),
+	})
+	assert.ErrorIs(t, err, order.ErrInvalidTrail)
+
+	_, err = order.NewNormalizer().NormalizeTrailingStop(market, order.TrailingStop{
+		Pair:         trading.BTCUSD,
+		Side:         order.SideSell,
+		BaseSize:     trading.MustParseAmount("0.001"),
+		TrailPercent: trading.MustParseAmount("5"),
+	})
+	assert.ErrorIs(t, err, order.ErrBelowMinSize)
+}
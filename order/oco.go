@@ -0,0 +1,125 @@
+package order
+
+import (
+	"errors"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// ErrInvalidOCO describes an error in which the take profit price of an OCO
+// order is not on the profitable side of its stop price.
+var ErrInvalidOCO = errors.New("take profit must be beyond the stop price")
+
+// OCO represents a one cancels the other order that closes a position. It is
+// made of a take profit limit order and a stop loss order of the same side
+// and size, where the fill of either leg cancels the other.
+type OCO struct {
+	ClientID string
+	Pair     trading.Pair
+
+	// Side is the side of both legs, which is a sell to close a long position
+	// and a buy to close a short one.
+	Side     Side
+	BaseSize trading.Amount
+
+	// TakeProfit is the price of the take profit limit order.
+	TakeProfit trading.Amount
+
+	// StopPrice is the price that triggers the stop loss order.
+	StopPrice trading.Amount
+
+	// StopLimitPrice is the price of the stop loss limit order. A zero price
+	// makes the stop loss a stop market order.
+	StopLimitPrice trading.Amount
+}
+
+// Validate checks that the take profit price is above the stop price for a
+// sell, or below it for a buy.
+func (o OCO) Validate() error {
+	cmp := o.TakeProfit.Cmp(o.StopPrice)
+
+	if o.Side == SideSell && cmp <= 0 || o.Side != SideSell && cmp >= 0 {
+		return ErrInvalidOCO
+	}
+
+	return nil
+}
+
+// TakeProfitLimit returns the take profit leg of the order.
+func (o OCO) TakeProfitLimit() Limit {
+	return Limit{
+		ClientID: o.ClientID + "-tp",
+		Pair:     o.Pair,
+		Side:     o.Side,
+		BaseSize: o.BaseSize,
+		Price:    o.TakeProfit,
+	}
+}
+
+// StopDirection returns the direction of the stop loss leg, which is below
+// the stop price for a sell and above it for a buy.
+func (o OCO) StopDirection() Direction {
+	if o.Side == SideSell {
+		return DirectionBelow
+	}
+
+	return DirectionAbove
+}
+
+// StopLossLimit returns the stop loss leg of the order when it has a stop
+// limit price.
+func (o OCO) StopLossLimit() StopLimit {
+	return StopLimit{
+		ClientID:  o.ClientID + "-sl",
+		Pair:      o.Pair,
+		Side:      o.Side,
+		BaseSize:  o.BaseSize,
+		Price:     o.StopLimitPrice,
+		StopPrice: o.StopPrice,
+		Direction: o.StopDirection(),
+	}
+}
+
+// StopLossMarket returns the stop loss leg of the order when it has no stop
+// limit price.
+func (o OCO) StopLossMarket() StopMarket {
+	return StopMarket{
+		ClientID:  o.ClientID + "-sl",
+		Pair:      o.Pair,
+		Side:      o.Side,
+		BaseSize:  o.BaseSize,
+		StopPrice: o.StopPrice,
+		Direction: o.StopDirection(),
+	}
+}
+
+// Bracket represents an entry limit order, along with the OCO order that
+// exits the position once the entry has been filled.
+type Bracket struct {
+	Entry Limit
+
+	// TakeProfit, StopPrice and StopLimitPrice are the prices of the exit,
+	// as on the OCO type.
+	TakeProfit     trading.Amount
+	StopPrice      trading.Amount
+	StopLimitPrice trading.Amount
+}
+
+// Exit returns the OCO order that exits the position of the entry, which is
+// of the opposite side and the same size.
+func (b Bracket) Exit() OCO {
+	side := SideSell
+	if b.Entry.Side == SideSell {
+		side = SideBuy
+	}
+
+	return OCO{
+		ClientID:       b.Entry.ClientID + "-exit",
+		Pair:           b.Entry.Pair,
+		Side:           side,
+		BaseSize:       b.Entry.BaseSize,
+		TakeProfit:     b.TakeProfit,
+		StopPrice:      b.StopPrice,
+		StopLimitPrice: b.StopLimitPrice,
+	}
+}
@@ -0,0 +1,113 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestOCOValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		side       order.Side
+		takeProfit string
+		stopPrice  string
+		expected   error
+	}{
+		{name: "sell with take profit above the stop", side: order.SideSell, takeProfit: "20000", stopPrice: "16000"},
+		{name: "buy with take profit below the stop", side: order.SideBuy, takeProfit: "16000", stopPrice: "20000"},
+		{
+			name:       "sell with take profit below the stop",
+			side:       order.SideSell,
+			takeProfit: "16000",
+			stopPrice:  "20000",
+			expected:   order.ErrInvalidOCO,
+		},
+		{
+			name:       "buy with take profit at the stop",
+			side:       order.SideBuy,
+			takeProfit: "16000",
+			stopPrice:  "16000",
+			expected:   order.ErrInvalidOCO,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			o := order.OCO{
+				Side:       tt.side,
+				TakeProfit: trading.MustParseAmount(tt.takeProfit),
+				StopPrice:  trading.MustParseAmount(tt.stopPrice),
+			}
+
+			assert.ErrorIs(t, o.Validate(), tt.expected)
+		})
+	}
+}
+
+func TestOCOLegs(t *testing.T) {
+	o := order.OCO{
+		ClientID:       "foobar",
+		Pair:           trading.BTCUSD,
+		Side:           order.SideSell,
+		BaseSize:       trading.MustParseAmount("0.5"),
+		TakeProfit:     trading.MustParseAmount("20000"),
+		StopPrice:      trading.MustParseAmount("16000"),
+		StopLimitPrice: trading.MustParseAmount("15900"),
+	}
+
+	assert.Equal(t, order.Limit{
+		ClientID: "foobar-tp",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideSell,
+		BaseSize: trading.MustParseAmount("0.5"),
+		Price:    trading.MustParseAmount("20000"),
+	}, o.TakeProfitLimit())
+
+	assert.Equal(t, order.StopLimit{
+		ClientID:  "foobar-sl",
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.5"),
+		Price:     trading.MustParseAmount("15900"),
+		StopPrice: trading.MustParseAmount("16000"),
+		Direction: order.DirectionBelow,
+	}, o.StopLossLimit())
+
+	assert.Equal(t, order.StopMarket{
+		ClientID:  "foobar-sl",
+		Pair:      trading.BTCUSD,
+		Side:      order.SideSell,
+		BaseSize:  trading.MustParseAmount("0.5"),
+		StopPrice: trading.MustParseAmount("16000"),
+		Direction: order.DirectionBelow,
+	}, o.StopLossMarket())
+}
+
+func TestBracketExit(t *testing.T) {
+	b := order.Bracket{
+		Entry: order.Limit{
+			ClientID: "foobar",
+			Pair:     trading.BTCUSD,
+			Side:     order.SideBuy,
+			BaseSize: trading.MustParseAmount("0.5"),
+			Price:    trading.MustParseAmount("17000"),
+		},
+		TakeProfit: trading.MustParseAmount("20000"),
+		StopPrice:  trading.MustParseAmount("16000"),
+	}
+
+	assert.Equal(t, order.OCO{
+		ClientID:   "foobar-exit",
+		Pair:       trading.BTCUSD,
+		Side:       order.SideSell,
+		BaseSize:   trading.MustParseAmount("0.5"),
+		TakeProfit: trading.MustParseAmount("20000"),
+		StopPrice:  trading.MustParseAmount("16000"),
+	}, b.Exit())
+}
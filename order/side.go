@@ -8,5 +8,5 @@ const (
 	SideBuy Side = "BUY"
 
 	// SideSell specifies the sell side of an order
-	SideSell = "SELL"
+	SideSell Side = "SELL"
 )
@@ -0,0 +1,83 @@
+package order
+
+import (
+	"errors"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// ErrInvalidTransition describes an error in which an order is seen to move
+// between statuses that it cannot, such as from filled back to new.
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// Status represents the state of an order on the exchange. Orders start out
+// as new, may be partially filled any number of times, and end up in one of
+// the terminal states.
+type Status string
+
+const (
+	// StatusNew specifies an order that is open and has not been filled.
+	StatusNew Status = "NEW"
+
+	// StatusPartiallyFilled specifies an order that is open and has been
+	// filled in part.
+	StatusPartiallyFilled Status = "PARTIALLY_FILLED"
+
+	// StatusFilled specifies an order that has been filled in full.
+	StatusFilled Status = "FILLED"
+
+	// StatusCancelled specifies an order that was cancelled before it was
+	// filled in full. It may have been filled in part.
+	StatusCancelled Status = "CANCELLED"
+
+	// StatusRejected specifies an order that the exchange did not accept.
+	StatusRejected Status = "REJECTED"
+
+	// StatusExpired specifies an order that was removed by the exchange once
+	// its time in force ran out, such as an immediate or cancel order that
+	// could not be filled.
+	StatusExpired Status = "EXPIRED"
+)
+
+// OpenStatus returns the status of an order that is still open, which is
+// partially filled once any of it has been filled.
+func OpenStatus(filledSize trading.Amount) Status {
+	if filledSize.Sign() > 0 {
+		return StatusPartiallyFilled
+	}
+
+	return StatusNew
+}
+
+// Open reports whether the order is still on the book of the exchange.
+func (s Status) Open() bool {
+	return s == StatusNew || s == StatusPartiallyFilled
+}
+
+// Terminal reports whether the order has reached a state that it can no
+// longer leave.
+func (s Status) Terminal() bool {
+	switch s {
+	case StatusFilled, StatusCancelled, StatusRejected, StatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanTransitionTo reports whether an order is able to move from the status
+// to the next status. An order that is partially filled can be partially
+// filled again, as its filled size grows. An empty status is not known yet,
+// so it can move to any status.
+func (s Status) CanTransitionTo(next Status) bool {
+	switch s {
+	case "":
+		return true
+	case StatusNew:
+		return next != StatusNew
+	case StatusPartiallyFilled:
+		return next != StatusNew && next != StatusRejected
+	default:
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestStatusCanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		from     order.Status
+		to       order.Status
+		expected bool
+	}{
+		{from: "", to: order.StatusFilled, expected: true},
+		{from: order.StatusNew, to: order.StatusPartiallyFilled, expected: true},
+		{from: order.StatusNew, to: order.StatusFilled, expected: true},
+		{from: order.StatusNew, to: order.StatusRejected, expected: true},
+		{from: order.StatusNew, to: order.StatusNew, expected: false},
+		{from: order.StatusPartiallyFilled, to: order.StatusPartiallyFilled, expected: true},
+		{from: order.StatusPartiallyFilled, to: order.StatusCancelled, expected: true},
+		{from: order.StatusPartiallyFilled, to: order.StatusExpired, expected: true},
+		{from: order.StatusPartiallyFilled, to: order.StatusNew, expected: false},
+		{from: order.StatusPartiallyFilled, to: order.StatusRejected, expected: false},
+		{from: order.StatusFilled, to: order.StatusCancelled, expected: false},
+		{from: order.StatusCancelled, to: order.StatusNew, expected: false},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestStatusTerminal(t *testing.T) {
+	for _, s := range []order.Status{order.StatusNew, order.StatusPartiallyFilled} {
+		assert.True(t, s.Open(), s)
+		assert.False(t, s.Terminal(), s)
+	}
+
+	terminal := []order.Status{order.StatusFilled, order.StatusCancelled, order.StatusRejected, order.StatusExpired}
+
+	for _, s := range terminal {
+		assert.False(t, s.Open(), s)
+		assert.True(t, s.Terminal(), s)
+	}
+}
+
+func TestOpenStatus(t *testing.T) {
+	assert.Equal(t, order.StatusNew, order.OpenStatus(trading.Amount{}))
+	assert.Equal(t, order.StatusPartiallyFilled, order.OpenStatus(trading.MustParseAmount("0.5")))
+}
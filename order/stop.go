@@ -0,0 +1,80 @@
+package order
+
+import "github.com/project-code-io/crypto-trading-bot-go/trading"
+
+// Direction represents the way that the price has to move through the stop
+// price of a stop order for it to be triggered.
+type Direction string
+
+const (
+	// DirectionAbove triggers a stop order once the price is at or above the
+	// stop price, such as to buy on a breakout or to take profit on a sell.
+	DirectionAbove Direction = "ABOVE"
+
+	// DirectionBelow triggers a stop order once the price is at or below the
+	// stop price, such as to sell on a stop loss.
+	DirectionBelow Direction = "BELOW"
+)
+
+// Valid reports whether the direction is one of the known directions.
+func (d Direction) Valid() bool {
+	return d == DirectionAbove || d == DirectionBelow
+}
+
+// Triggered reports whether the price has reached the stop price in the
+// direction.
+func (d Direction) Triggered(price trading.Amount, stopPrice trading.Amount) bool {
+	switch d {
+	case DirectionAbove:
+		return price.Cmp(stopPrice) >= 0
+	case DirectionBelow:
+		return price.Cmp(stopPrice) <= 0
+	default:
+		return false
+	}
+}
+
+// StopLimit represents a limit order that is only placed once the price of
+// the pair reaches the stop price in the direction.
+type StopLimit struct {
+	ClientID  string
+	Pair      trading.Pair
+	Side      Side
+	BaseSize  trading.Amount
+	Price     trading.Amount
+	StopPrice trading.Amount
+	Direction Direction
+}
+
+// Limit returns the limit order that is placed once the stop is triggered.
+func (s StopLimit) Limit() Limit {
+	return Limit{
+		ClientID: s.ClientID,
+		Pair:     s.Pair,
+		Side:     s.Side,
+		BaseSize: s.BaseSize,
+		Price:    s.Price,
+	}
+}
+
+// StopMarket represents a market order that is only placed once the price of
+// the pair reaches the stop price in the direction.
+type StopMarket struct {
+	ClientID  string
+	Pair      trading.Pair
+	Side      Side
+	BaseSize  trading.Amount
+	StopPrice trading.Amount
+	Direction Direction
+}
+
+// Market returns the market order that is placed once the stop is
+// triggered.
+func (s StopMarket) Market() Market {
+	return Market{
+		ClientID: s.ClientID,
+		Pair:     s.Pair,
+		Side:     s.Side,
+		BaseSize: s.BaseSize,
+	}
+}
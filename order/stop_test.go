@@ -0,0 +1,37 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestDirectionTriggered(t *testing.T) {
+	testCases := []struct {
+		name      string
+		direction order.Direction
+		price     string
+		expected  bool
+	}{
+		{name: "above the stop price", direction: order.DirectionAbove, price: "101", expected: true},
+		{name: "at the stop price going up", direction: order.DirectionAbove, price: "100", expected: true},
+		{name: "below the stop price going up", direction: order.DirectionAbove, price: "99.99", expected: false},
+		{name: "below the stop price", direction: order.DirectionBelow, price: "99", expected: true},
+		{name: "at the stop price going down", direction: order.DirectionBelow, price: "100", expected: true},
+		{name: "above the stop price going down", direction: order.DirectionBelow, price: "100.01", expected: false},
+		{name: "unknown direction", direction: order.Direction("SIDEWAYS"), price: "100", expected: false},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			res := tt.direction.Triggered(trading.MustParseAmount(tt.price), trading.MustParseAmount("100"))
+
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
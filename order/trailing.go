@@ -0,0 +1,110 @@
+package order
+
+import (
+	"errors"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// ErrInvalidTrail describes an error in which a trailing stop does not have
+// exactly one of a trail amount and a trail percent above zero.
+var ErrInvalidTrail = errors.New("trailing stop needs either a trail amount or a trail percent")
+
+// TrailingStop represents a stop order whose stop price follows the best price
+// that the pair has reached since the order was placed, which is the highest
+// price for a sell and the lowest price for a buy. The order is triggered once
+// the price retraces from its best by the trail.
+type TrailingStop struct {
+	ClientID string
+	Pair     trading.Pair
+	Side     Side
+	BaseSize trading.Amount
+
+	// TrailAmount is the distance of the stop price from the best price, in
+	// the quote asset.
+	TrailAmount trading.Amount
+
+	// TrailPercent is the distance of the stop price from the best price, as
+	// a percentage of the best price, i.e. 5 for 5%.
+	TrailPercent trading.Amount
+
+	// LimitOffset is the distance beyond the stop price at which the limit
+	// order that exits is priced. A zero offset exits with a market order
+	// instead.
+	LimitOffset trading.Amount
+}
+
+// Validate checks that the trailing stop has exactly one kind of trail and
+// that none of its amounts are negative.
+func (t TrailingStop) Validate() error {
+	if t.BaseSize.Sign() < 0 || t.TrailAmount.Sign() < 0 || t.TrailPercent.Sign() < 0 || t.LimitOffset.Sign() < 0 {
+		return ErrNegativeAmount
+	}
+
+	if t.TrailAmount.IsZero() == t.TrailPercent.IsZero() {
+		return ErrInvalidTrail
+	}
+
+	return nil
+}
+
+// Direction returns the direction in which the price triggers the stop, which
+// is below the stop price for a sell and above it for a buy.
+func (t TrailingStop) Direction() Direction {
+	if t.Side == SideSell {
+		return DirectionBelow
+	}
+
+	return DirectionAbove
+}
+
+// Improves reports whether the price is better than the best price so far,
+// such that the stop price should follow it.
+func (t TrailingStop) Improves(best trading.Amount, price trading.Amount) bool {
+	if t.Side == SideSell {
+		return price.Cmp(best) > 0
+	}
+
+	return price.Cmp(best) < 0
+}
+
+// StopPrice returns the stop price that trails the best price.
+func (t TrailingStop) StopPrice(best trading.Amount) trading.Amount {
+	trail := t.TrailAmount
+	if trail.IsZero() {
+		trail = best.Mul(t.TrailPercent).Mul(trading.NewAmount(1, 2))
+	}
+
+	if t.Side == SideSell {
+		return best.Sub(trail)
+	}
+
+	return best.Add(trail)
+}
+
+// Limit returns the limit order that exits once the stop is triggered at the
+// stop price.
+func (t TrailingStop) Limit(stopPrice trading.Amount) Limit {
+	price := stopPrice.Add(t.LimitOffset)
+	if t.Side == SideSell {
+		price = stopPrice.Sub(t.LimitOffset)
+	}
+
+	return Limit{
+		ClientID: t.ClientID,
+		Pair:     t.Pair,
+		Side:     t.Side,
+		BaseSize: t.BaseSize,
+		Price:    price,
+	}
+}
+
+// Market returns the market order that exits once the stop is triggered.
+func (t TrailingStop) Market() Market {
+	return Market{
+		ClientID: t.ClientID,
+		Pair:     t.Pair,
+		Side:     t.Side,
+		BaseSize: t.BaseSize,
+	}
+}
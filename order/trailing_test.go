@@ -0,0 +1,106 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestTrailingStopValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   string
+		percent  string
+		expected error
+	}{
+		{name: "trail amount", amount: "100", percent: "0"},
+		{name: "trail percent", amount: "0", percent: "5"},
+		{name: "no trail", amount: "0", percent: "0", expected: order.ErrInvalidTrail},
+		{name: "both trails", amount: "100", percent: "5", expected: order.ErrInvalidTrail},
+		{name: "negative trail", amount: "-100", percent: "0", expected: order.ErrNegativeAmount},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			o := order.TrailingStop{
+				Side:         order.SideSell,
+				BaseSize:     trading.MustParseAmount("1"),
+				TrailAmount:  trading.MustParseAmount(tt.amount),
+				TrailPercent: trading.MustParseAmount(tt.percent),
+			}
+
+			assert.ErrorIs(t, o.Validate(), tt.expected)
+		})
+	}
+}
+
+func TestTrailingStopStopPrice(t *testing.T) {
+	testCases := []struct {
+		name     string
+		side     order.Side
+		amount   string
+		percent  string
+		expected string
+	}{
+		{name: "sell by amount", side: order.SideSell, amount: "500", percent: "0", expected: "19500"},
+		{name: "sell by percent", side: order.SideSell, amount: "0", percent: "2.5", expected: "19500"},
+		{name: "buy by amount", side: order.SideBuy, amount: "500", percent: "0", expected: "20500"},
+		{name: "buy by percent", side: order.SideBuy, amount: "0", percent: "1", expected: "20200"},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			o := order.TrailingStop{
+				Side:         tt.side,
+				TrailAmount:  trading.MustParseAmount(tt.amount),
+				TrailPercent: trading.MustParseAmount(tt.percent),
+			}
+
+			stopPrice := o.StopPrice(trading.MustParseAmount("20000"))
+			assert.Zero(t, stopPrice.Cmp(trading.MustParseAmount(tt.expected)), stopPrice.String())
+		})
+	}
+}
+
+func TestTrailingStopImproves(t *testing.T) {
+	best := trading.MustParseAmount("20000")
+	higher := trading.MustParseAmount("20001")
+	lower := trading.MustParseAmount("19999")
+
+	sell := order.TrailingStop{Side: order.SideSell}
+	assert.True(t, sell.Improves(best, higher))
+	assert.False(t, sell.Improves(best, lower))
+	assert.False(t, sell.Improves(best, best))
+	assert.Equal(t, order.DirectionBelow, sell.Direction())
+
+	buy := order.TrailingStop{Side: order.SideBuy}
+	assert.True(t, buy.Improves(best, lower))
+	assert.False(t, buy.Improves(best, higher))
+	assert.Equal(t, order.DirectionAbove, buy.Direction())
+}
+
+func TestTrailingStopLimit(t *testing.T) {
+	o := order.TrailingStop{
+		ClientID:    "foobar",
+		Pair:        trading.BTCUSD,
+		Side:        order.SideSell,
+		BaseSize:    trading.MustParseAmount("0.5"),
+		TrailAmount: trading.MustParseAmount("500"),
+		LimitOffset: trading.MustParseAmount("10"),
+	}
+
+	assert.Equal(t, order.Limit{
+		ClientID: "foobar",
+		Pair:     trading.BTCUSD,
+		Side:     order.SideSell,
+		BaseSize: trading.MustParseAmount("0.5"),
+		Price:    trading.MustParseAmount("19490"),
+	}, o.Limit(trading.MustParseAmount("19500")))
+}
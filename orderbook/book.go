@@ -0,0 +1,281 @@
+package orderbook
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+var (
+	// ErrEmptyBook describes an error in which the book has no price levels
+	// to answer a query with, such as before it has been synced.
+	ErrEmptyBook = errors.New("order book is empty")
+
+	// ErrInsufficientDepth describes an error in which the book does not
+	// hold enough size to fill the size of a query.
+	ErrInsufficientDepth = errors.New("not enough depth in order book")
+
+	// ErrInvalidSize describes an error in which the size of a query is not
+	// above zero.
+	ErrInvalidSize = errors.New("size must be above zero")
+)
+
+// imbalanceScale is the number of decimal places of the imbalance of a book.
+const imbalanceScale = 8
+
+// Book represents the L2 order book of a pair, which holds the total size of
+// the orders at each price. The bids are held from the highest price and the
+// asks from the lowest. It is safe for concurrent use.
+type Book struct {
+	pair trading.Pair
+
+	mu       sync.RWMutex
+	bids     []marketdata.Level
+	asks     []marketdata.Level
+	updateID int64
+	time     time.Time
+	synced   bool
+}
+
+// NewBook acts as the default constructor for the Book type. The book is
+// empty and not synced until a snapshot has been applied.
+func NewBook(pair trading.Pair) *Book {
+	return &Book{pair: pair}
+}
+
+// Pair returns the pair of the book.
+func (b *Book) Pair() trading.Pair {
+	return b.pair
+}
+
+// Synced reports whether the book matches that of the exchange, which it
+// does from its snapshot until it misses an update.
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.synced
+}
+
+// UpdateID returns the ID of the last update that has been applied to the
+// book, on exchanges that number their updates.
+func (b *Book) UpdateID() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.updateID
+}
+
+// Time returns the time of the last snapshot or update that has been applied
+// to the book.
+func (b *Book) Time() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.time
+}
+
+// BestBid returns the price level with the highest bid, and whether there is
+// one.
+func (b *Book) BestBid() (marketdata.Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.bids) == 0 {
+		return marketdata.Level{}, false
+	}
+
+	return b.bids[0], true
+}
+
+// BestAsk returns the price level with the lowest ask, and whether there is
+// one.
+func (b *Book) BestAsk() (marketdata.Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.asks) == 0 {
+		return marketdata.Level{}, false
+	}
+
+	return b.asks[0], true
+}
+
+// Depth returns up to n of the best price levels of each side of the book,
+// or every level if n is not above zero. The levels are copies, which are
+// not changed by later updates.
+func (b *Book) Depth(n int) (bids []marketdata.Level, asks []marketdata.Level) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return top(b.bids, n), top(b.asks, n)
+}
+
+func top(levels []marketdata.Level, n int) []marketdata.Level {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+
+	return append([]marketdata.Level(nil), levels[:n]...)
+}
+
+// VWAP returns the average price that an order of the side and size would be
+// filled at, were it to take the liquidity of the book. A buy is filled by
+// the asks and a sell by the bids. The price is rounded to the decimal places
+// of the quote asset.
+func (b *Book) VWAP(side order.Side, size trading.Amount) (trading.Amount, error) {
+	if size.Sign() <= 0 {
+		return trading.Amount{}, ErrInvalidSize
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	levels := b.bids
+	if side == order.SideBuy {
+		levels = b.asks
+	}
+
+	remaining := size
+	cost := trading.Amount{}
+
+	for _, l := range levels {
+		filled := l.Size
+		if filled.Cmp(remaining) > 0 {
+			filled = remaining
+		}
+
+		cost = cost.Add(filled.Mul(l.Price))
+		remaining = remaining.Sub(filled)
+
+		if remaining.Sign() == 0 {
+			return cost.Div(size, b.pair.Quote.Decimals(), trading.RoundHalfEven)
+		}
+	}
+
+	return trading.Amount{}, ErrInsufficientDepth
+}
+
+// Imbalance returns the difference between the size of the bids and of the
+// asks over up to n of the best price levels of each side, as a share of
+// their total size. It ranges from -1, where there are only asks, to 1, where
+// there are only bids, and uses every level if n is not above zero.
+func (b *Book) Imbalance(n int) (trading.Amount, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	bids := total(top(b.bids, n))
+	asks := total(top(b.asks, n))
+
+	sum := bids.Add(asks)
+	if sum.Sign() == 0 {
+		return trading.Amount{}, ErrEmptyBook
+	}
+
+	return bids.Sub(asks).Div(sum, imbalanceScale, trading.RoundHalfEven)
+}
+
+func total(levels []marketdata.Level) trading.Amount {
+	sum := trading.Amount{}
+
+	for _, l := range levels {
+		sum = sum.Add(l.Size)
+	}
+
+	return sum
+}
+
+// reset replaces the levels of the book with those of the snapshot, after
+// which the book is synced.
+func (b *Book) reset(snapshot exchange.OrderBook) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = b.bids[:0]
+	b.asks = b.asks[:0]
+
+	for _, l := range snapshot.Bids {
+		b.bids = setLevel(b.bids, l, higher)
+	}
+
+	for _, l := range snapshot.Asks {
+		b.asks = setLevel(b.asks, l, lower)
+	}
+
+	b.updateID = snapshot.LastUpdateID
+	b.time = snapshot.Time
+	b.synced = true
+}
+
+// apply sets the levels of the update in the book.
+func (b *Book) apply(u marketdata.BookUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, l := range u.Bids {
+		b.bids = setLevel(b.bids, l, higher)
+	}
+
+	for _, l := range u.Asks {
+		b.asks = setLevel(b.asks, l, lower)
+	}
+
+	if u.LastUpdateID != 0 {
+		b.updateID = u.LastUpdateID
+	}
+
+	b.time = u.Time
+}
+
+// clear empties the book, which is no longer synced until the next snapshot.
+func (b *Book) clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = nil
+	b.asks = nil
+	b.updateID = 0
+	b.synced = false
+}
+
+func higher(a trading.Amount, b trading.Amount) bool {
+	return a.Cmp(b) > 0
+}
+
+func lower(a trading.Amount, b trading.Amount) bool {
+	return a.Cmp(b) < 0
+}
+
+// setLevel sets the level in the levels, which are ordered by price with
+// better first. A level with a size of zero is removed.
+func setLevel(
+	levels []marketdata.Level, l marketdata.Level, better func(a, b trading.Amount) bool,
+) []marketdata.Level {
+	i := sort.Search(len(levels), func(i int) bool {
+		return !better(levels[i].Price, l.Price)
+	})
+
+	found := i < len(levels) && levels[i].Price.Cmp(l.Price) == 0
+
+	switch {
+	case l.Size.Sign() <= 0 && found:
+		return append(levels[:i], levels[i+1:]...)
+	case l.Size.Sign() <= 0:
+		return levels
+	case found:
+		levels[i] = l
+		return levels
+	default:
+		levels = append(levels, marketdata.Level{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = l
+
+		return levels
+	}
+}
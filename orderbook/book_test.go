@@ -0,0 +1,194 @@
+package orderbook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/orderbook"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func level(price string, size string) marketdata.Level {
+	return marketdata.Level{Price: trading.MustParseAmount(price), Size: trading.MustParseAmount(size)}
+}
+
+// newBook returns a synced book of BTC/USD with the levels of the snapshot.
+func newBook(t *testing.T, bids []marketdata.Level, asks []marketdata.Level) *orderbook.Book {
+	t.Helper()
+
+	books := orderbook.NewBooks(nil)
+
+	err := books.Apply(context.Background(), marketdata.BookUpdate{
+		Pair:     trading.BTCUSD,
+		Snapshot: true,
+		Bids:     bids,
+		Asks:     asks,
+	})
+	assert.NoError(t, err)
+
+	book, ok := books.Book(trading.BTCUSD)
+	assert.True(t, ok)
+
+	return book
+}
+
+func TestBookLevels(t *testing.T) {
+	// The levels of the snapshot are out of order, and one of them is empty.
+	book := newBook(t,
+		[]marketdata.Level{level("99", "2"), level("100", "1"), level("98", "0"), level("97", "3")},
+		[]marketdata.Level{level("102", "4"), level("101", "5")},
+	)
+
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, level("100", "1"), bid)
+
+	ask, ok := book.BestAsk()
+	assert.True(t, ok)
+	assert.Equal(t, level("101", "5"), ask)
+
+	bids, asks := book.Depth(2)
+	assert.Equal(t, []marketdata.Level{level("100", "1"), level("99", "2")}, bids)
+	assert.Equal(t, []marketdata.Level{level("101", "5"), level("102", "4")}, asks)
+
+	bids, asks = book.Depth(0)
+	assert.Equal(t, []marketdata.Level{level("100", "1"), level("99", "2"), level("97", "3")}, bids)
+	assert.Equal(t, []marketdata.Level{level("101", "5"), level("102", "4")}, asks)
+}
+
+func TestBookEmpty(t *testing.T) {
+	book := orderbook.NewBook(trading.BTCUSD)
+
+	assert.False(t, book.Synced())
+
+	_, ok := book.BestBid()
+	assert.False(t, ok)
+
+	_, ok = book.BestAsk()
+	assert.False(t, ok)
+
+	_, err := book.Imbalance(0)
+	assert.ErrorIs(t, err, orderbook.ErrEmptyBook)
+
+	_, err = book.VWAP(order.SideBuy, trading.MustParseAmount("1"))
+	assert.ErrorIs(t, err, orderbook.ErrInsufficientDepth)
+}
+
+func TestBookVWAP(t *testing.T) {
+	book := newBook(t,
+		[]marketdata.Level{level("100", "1"), level("99", "2")},
+		[]marketdata.Level{level("101", "1"), level("102", "1"), level("104", "2")},
+	)
+
+	testCases := []struct {
+		name     string
+		side     order.Side
+		size     string
+		expected trading.Amount
+		err      error
+	}{
+		{
+			name:     "buy within the best ask",
+			side:     order.SideBuy,
+			size:     "0.5",
+			expected: trading.MustParseAmount("101"),
+		},
+		{
+			name:     "buy across levels",
+			side:     order.SideBuy,
+			size:     "3",
+			expected: trading.MustParseAmount("102.33"),
+		},
+		{
+			name:     "sell across levels",
+			side:     order.SideSell,
+			size:     "2",
+			expected: trading.MustParseAmount("99.5"),
+		},
+		{
+			name: "size beyond the depth",
+			side: order.SideSell,
+			size: "3.1",
+			err:  orderbook.ErrInsufficientDepth,
+		},
+		{
+			name: "size of zero",
+			side: order.SideBuy,
+			size: "0",
+			err:  orderbook.ErrInvalidSize,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			vwap, err := book.VWAP(tt.side, trading.MustParseAmount(tt.size))
+
+			assert.ErrorIs(t, err, tt.err)
+
+			if tt.err == nil {
+				assert.Zero(t, tt.expected.Cmp(vwap), "expected %s, got %s", tt.expected, vwap)
+			}
+		})
+	}
+}
+
+func TestBookImbalance(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bids     []marketdata.Level
+		asks     []marketdata.Level
+		levels   int
+		expected trading.Amount
+	}{
+		{
+			name:     "balanced",
+			bids:     []marketdata.Level{level("100", "1")},
+			asks:     []marketdata.Level{level("101", "1")},
+			expected: trading.MustParseAmount("0"),
+		},
+		{
+			name:     "more asks across levels",
+			bids:     []marketdata.Level{level("100", "3"), level("99", "6")},
+			asks:     []marketdata.Level{level("101", "1"), level("102", "10")},
+			levels:   2,
+			expected: trading.MustParseAmount("-0.1"),
+		},
+		{
+			name:     "more bids at the top level",
+			bids:     []marketdata.Level{level("100", "3"), level("99", "6")},
+			asks:     []marketdata.Level{level("101", "1"), level("102", "10")},
+			levels:   1,
+			expected: trading.MustParseAmount("0.5"),
+		},
+		{
+			name:     "only bids",
+			bids:     []marketdata.Level{level("100", "3")},
+			expected: trading.MustParseAmount("1"),
+		},
+		{
+			name:     "inexact",
+			bids:     []marketdata.Level{level("100", "1")},
+			asks:     []marketdata.Level{level("101", "2")},
+			expected: trading.MustParseAmount("-0.33333333"),
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			book := newBook(t, tt.bids, tt.asks)
+
+			imbalance, err := book.Imbalance(tt.levels)
+
+			assert.NoError(t, err)
+			assert.Zero(t, tt.expected.Cmp(imbalance), "expected %s, got %s", tt.expected, imbalance)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+const (
+	// defaultDepth is the number of price levels of each side of the book
+	// that are fetched in a snapshot.
+	defaultDepth = 1000
+
+	// defaultRetry is how long to wait before fetching another snapshot when
+	// the last one was older than the updates that are held.
+	defaultRetry = time.Second
+)
+
+// SnapshotSource represents a client that is able to fetch a snapshot of the
+// order book of a pair, i.e. exchange.Binance or exchange.Coinbase.
+type SnapshotSource interface {
+	GetOrderBook(ctx context.Context, p trading.Pair, depth int) (exchange.OrderBook, error)
+}
+
+var (
+	_ SnapshotSource = (*exchange.Binance)(nil)
+	_ SnapshotSource = (*exchange.Coinbase)(nil)
+)
+
+// Option allows for overriding of the defaults of the books.
+type Option func(b *Books)
+
+// WithSnapshotDepth overrides the number of price levels of each side of the
+// book that are fetched in a snapshot, which is 1000 by default.
+func WithSnapshotDepth(depth int) Option {
+	return func(b *Books) {
+		b.depth = depth
+	}
+}
+
+// WithSnapshotRetry overrides how long to wait before fetching another
+// snapshot when the last one was older than the updates that are held, which
+// is a second by default.
+func WithSnapshotRetry(wait time.Duration) Option {
+	return func(b *Books) {
+		b.retry = wait
+	}
+}
+
+// Books maintains the order book of each pair that a market data stream
+// sends updates for. Books are synced in one of two ways:
+//
+//   - Streams that send a snapshot before their updates, such as the level2
+//     channel of coinbase, sync the book with it. The stream checks the
+//     sequence numbers of its messages, and reconnects after a gap, which
+//     sends a new snapshot.
+//   - Streams that number their updates, such as the depth streams of
+//     binance, are synced with a snapshot from the source. The snapshot is
+//     fetched in the background, so that applying events never waits on the
+//     source. The updates that arrive before the snapshot are held, and
+//     those that the snapshot already holds are dropped. A gap between the
+//     IDs of the updates fetches a new snapshot.
+type Books struct {
+	source SnapshotSource
+	depth  int
+	retry  time.Duration
+
+	// applyMu is held while an event or a snapshot is applied, and guards
+	// the state of the syncs, while mu only guards the map of books.
+	applyMu  sync.Mutex
+	pending  map[trading.Pair][]marketdata.BookUpdate
+	fetching map[trading.Pair]bool
+	err      error
+	fetches  sync.WaitGroup
+
+	mu    sync.RWMutex
+	books map[trading.Pair]*Book
+}
+
+// NewBooks acts as the default constructor for the Books type. The source is
+// only needed for streams that number their updates, so it may be nil.
+func NewBooks(source SnapshotSource, opts ...Option) *Books {
+	b := &Books{
+		source:   source,
+		depth:    defaultDepth,
+		retry:    defaultRetry,
+		pending:  make(map[trading.Pair][]marketdata.BookUpdate),
+		fetching: make(map[trading.Pair]bool),
+		books:    make(map[trading.Pair]*Book),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Book returns the order book of the pair, and whether it is synced. Books
+// that are not synced may be empty or out of date, so should not be traded
+// on.
+func (b *Books) Book(pair trading.Pair) (*Book, bool) {
+	b.mu.RLock()
+	book, ok := b.books[pair]
+	b.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return book, book.Synced()
+}
+
+func (b *Books) book(pair trading.Pair) *Book {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	book, ok := b.books[pair]
+	if !ok {
+		book = NewBook(pair)
+		b.books[pair] = book
+	}
+
+	return book
+}
+
+// Apply applies an event of a market data stream to the books. Book updates
+// change the book of their pair, while a reconnect leaves every book out of
+// sync until it has been synced again. Any other event is ignored. A snapshot
+// that could not be fetched is returned as an error by the next call, in
+// which case the book is synced again on a later update.
+func (b *Books) Apply(ctx context.Context, event marketdata.Event) error {
+	b.applyMu.Lock()
+	defer b.applyMu.Unlock()
+
+	switch e := event.(type) {
+	case marketdata.BookUpdate:
+		b.update(ctx, e)
+	case marketdata.Reconnect:
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+
+		for pair, book := range b.books {
+			book.clear()
+			delete(b.pending, pair)
+		}
+	}
+
+	err := b.err
+	b.err = nil
+
+	return err
+}
+
+// Wait blocks until the snapshots that are being fetched have been applied,
+// or have failed.
+func (b *Books) Wait() {
+	b.fetches.Wait()
+}
+
+func (b *Books) update(ctx context.Context, u marketdata.BookUpdate) {
+	book := b.book(u.Pair)
+
+	switch {
+	case u.Snapshot:
+		book.reset(exchange.OrderBook{
+			Pair:         u.Pair,
+			Time:         u.Time,
+			LastUpdateID: u.LastUpdateID,
+			Bids:         u.Bids,
+			Asks:         u.Asks,
+		})
+		delete(b.pending, u.Pair)
+	case !book.Synced():
+		b.sync(ctx, book, u)
+	case u.LastUpdateID == 0:
+		// The stream does not number its updates, and has checked the
+		// sequence of its messages instead.
+		book.apply(u)
+	case u.LastUpdateID <= book.UpdateID():
+		// The update is already held by the snapshot.
+	case u.FirstUpdateID > book.UpdateID()+1:
+		book.clear()
+		b.sync(ctx, book, u)
+	default:
+		book.apply(u)
+	}
+}
+
+// sync holds the update until the book has been synced, and starts fetching
+// a snapshot of the book if one is not already being fetched.
+func (b *Books) sync(ctx context.Context, book *Book, u marketdata.BookUpdate) {
+	// Books of streams that do not number their updates are synced by the
+	// snapshot that the stream sends.
+	if u.LastUpdateID == 0 || b.source == nil {
+		return
+	}
+
+	b.pending[u.Pair] = append(b.pending[u.Pair], u)
+
+	if b.fetching[u.Pair] {
+		return
+	}
+
+	b.fetching[u.Pair] = true
+	b.fetches.Add(1)
+
+	go b.fetch(ctx, book)
+}
+
+// fetch fetches snapshots of the book until one is not older than the first
+// of the updates that are held, waiting between them.
+func (b *Books) fetch(ctx context.Context, book *Book) {
+	defer b.fetches.Done()
+
+	for {
+		snapshot, err := b.source.GetOrderBook(ctx, book.Pair(), b.depth)
+
+		if b.applySnapshot(book, snapshot, err) {
+			return
+		}
+
+		select {
+		case <-time.After(b.retry):
+		case <-ctx.Done():
+			b.applySnapshot(book, exchange.OrderBook{}, ctx.Err())
+			return
+		}
+	}
+}
+
+// applySnapshot syncs the book with the snapshot, and applies the held
+// updates that are newer than it on top. It returns false if the snapshot is
+// older than the first of the held updates, in which case another is needed.
+func (b *Books) applySnapshot(book *Book, snapshot exchange.OrderBook, err error) bool {
+	b.applyMu.Lock()
+	defer b.applyMu.Unlock()
+
+	pair := book.Pair()
+	pending := b.pending[pair]
+
+	switch {
+	case err != nil:
+		b.err = fmt.Errorf("get order book: %w", err)
+		delete(b.pending, pair)
+	case len(pending) == 0:
+		// The held updates were dropped while the snapshot was fetched, such
+		// as by a reconnect, so the snapshot cannot be checked against them.
+	case snapshot.LastUpdateID+1 < pending[0].FirstUpdateID:
+		return false
+	default:
+		book.reset(snapshot)
+		delete(b.pending, pair)
+
+		for _, p := range pending {
+			if p.LastUpdateID > snapshot.LastUpdateID {
+				book.apply(p)
+			}
+		}
+	}
+
+	delete(b.fetching, pair)
+
+	return true
+}
@@ -0,0 +1,218 @@
+package orderbook_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/orderbook"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// snapshots is a SnapshotSource that returns its snapshots in turn, and
+// records the depths that were asked for. If release is set, the snapshots
+// are held back until it is closed.
+type snapshots struct {
+	books   []exchange.OrderBook
+	err     error
+	depths  []int
+	release chan struct{}
+}
+
+func (s *snapshots) GetOrderBook(_ context.Context, _ trading.Pair, depth int) (exchange.OrderBook, error) {
+	if s.release != nil {
+		<-s.release
+	}
+
+	s.depths = append(s.depths, depth)
+
+	if s.err != nil {
+		return exchange.OrderBook{}, s.err
+	}
+
+	book := s.books[0]
+	if len(s.books) > 1 {
+		s.books = s.books[1:]
+	}
+
+	return book, nil
+}
+
+func diff(first int64, last int64, bids []marketdata.Level, asks []marketdata.Level) marketdata.BookUpdate {
+	return marketdata.BookUpdate{
+		Pair:          trading.BTCUSD,
+		FirstUpdateID: first,
+		LastUpdateID:  last,
+		Bids:          bids,
+		Asks:          asks,
+	}
+}
+
+func apply(t *testing.T, books *orderbook.Books, events ...marketdata.Event) {
+	t.Helper()
+
+	for _, e := range events {
+		assert.NoError(t, books.Apply(context.Background(), e))
+	}
+}
+
+func TestBooksSyncWithSnapshot(t *testing.T) {
+	source := &snapshots{
+		books: []exchange.OrderBook{{
+			Pair:         trading.BTCUSD,
+			LastUpdateID: 105,
+			Bids:         []exchange.Level{level("100", "1")},
+			Asks:         []exchange.Level{level("101", "1")},
+		}},
+		release: make(chan struct{}),
+	}
+
+	books := orderbook.NewBooks(source, orderbook.WithSnapshotDepth(50))
+
+	// The updates are held while the snapshot is fetched, without waiting on
+	// it, and only one snapshot is fetched. The first update is dropped as
+	// the snapshot already holds it. The second is partly held by the
+	// snapshot, so it is applied on top.
+	apply(t, books,
+		diff(101, 103, []marketdata.Level{level("90", "1")}, nil),
+		diff(104, 107, []marketdata.Level{level("99", "2")}, nil),
+		diff(108, 110, nil, []marketdata.Level{level("101", "0"), level("102", "3")}),
+	)
+
+	_, ok := books.Book(trading.BTCUSD)
+	assert.False(t, ok)
+
+	close(source.release)
+	books.Wait()
+
+	book, ok := books.Book(trading.BTCUSD)
+	assert.True(t, ok)
+	assert.Equal(t, []int{50}, source.depths)
+	assert.Equal(t, int64(110), book.UpdateID())
+
+	bids, asks := book.Depth(0)
+	assert.Equal(t, []marketdata.Level{level("100", "1"), level("99", "2")}, bids)
+	assert.Equal(t, []marketdata.Level{level("102", "3")}, asks)
+
+	// An update that is older than the book is dropped.
+	apply(t, books, diff(109, 110, []marketdata.Level{level("98", "1")}, nil))
+
+	bids, _ = book.Depth(0)
+	assert.Len(t, bids, 2)
+}
+
+func TestBooksSnapshotTooOld(t *testing.T) {
+	source := &snapshots{
+		books: []exchange.OrderBook{
+			{Pair: trading.BTCUSD, LastUpdateID: 90, Bids: []exchange.Level{level("80", "1")}},
+			{Pair: trading.BTCUSD, LastUpdateID: 102, Bids: []exchange.Level{level("100", "1")}},
+		},
+		release: make(chan struct{}),
+	}
+
+	books := orderbook.NewBooks(source, orderbook.WithSnapshotRetry(time.Millisecond))
+
+	// The first snapshot is older than the first update, so a new one is
+	// fetched after the retry wait, and the held updates applied on top.
+	apply(t, books,
+		diff(95, 100, nil, []marketdata.Level{level("110", "1")}),
+		diff(101, 103, nil, []marketdata.Level{level("111", "1")}),
+	)
+
+	close(source.release)
+	books.Wait()
+
+	book, ok := books.Book(trading.BTCUSD)
+	assert.True(t, ok)
+	assert.Equal(t, []int{1000, 1000}, source.depths)
+
+	bids, asks := book.Depth(0)
+	assert.Equal(t, []marketdata.Level{level("100", "1")}, bids)
+	assert.Equal(t, []marketdata.Level{level("111", "1")}, asks)
+}
+
+func TestBooksGap(t *testing.T) {
+	source := &snapshots{books: []exchange.OrderBook{
+		{Pair: trading.BTCUSD, LastUpdateID: 100, Bids: []exchange.Level{level("100", "1")}},
+		{Pair: trading.BTCUSD, LastUpdateID: 120, Bids: []exchange.Level{level("95", "1")}},
+	}}
+
+	books := orderbook.NewBooks(source)
+
+	apply(t, books, diff(99, 101, nil, nil))
+	books.Wait()
+
+	// The update after 101 has been missed, so the book is synced again from
+	// a new snapshot.
+	apply(t, books, diff(110, 121, nil, []marketdata.Level{level("96", "1")}))
+	books.Wait()
+
+	book, ok := books.Book(trading.BTCUSD)
+	assert.True(t, ok)
+	assert.Equal(t, int64(121), book.UpdateID())
+
+	bids, asks := book.Depth(0)
+	assert.Equal(t, []marketdata.Level{level("95", "1")}, bids)
+	assert.Equal(t, []marketdata.Level{level("96", "1")}, asks)
+}
+
+func TestBooksSnapshotError(t *testing.T) {
+	errDepth := errors.New("depth unavailable")
+
+	books := orderbook.NewBooks(&snapshots{err: errDepth})
+
+	// The error of the snapshot is returned once it has been fetched.
+	apply(t, books, diff(1, 2, nil, nil))
+	books.Wait()
+
+	_, ok := books.Book(trading.BTCUSD)
+	assert.False(t, ok)
+
+	err := books.Apply(context.Background(), diff(3, 4, nil, nil))
+	assert.ErrorIs(t, err, errDepth)
+}
+
+func TestBooksStreamSnapshot(t *testing.T) {
+	// Streams that send their own snapshot, such as coinbase, do not number
+	// their updates, so the books never fetch a snapshot.
+	books := orderbook.NewBooks(nil)
+
+	apply(t, books, marketdata.BookUpdate{Pair: trading.BTCUSD, Bids: []marketdata.Level{level("1", "1")}})
+
+	_, ok := books.Book(trading.BTCUSD)
+	assert.False(t, ok, "updates before the snapshot are dropped")
+
+	apply(t, books,
+		marketdata.BookUpdate{
+			Pair:     trading.BTCUSD,
+			Snapshot: true,
+			Bids:     []marketdata.Level{level("100", "1")},
+			Asks:     []marketdata.Level{level("101", "1")},
+		},
+		marketdata.BookUpdate{
+			Pair: trading.BTCUSD,
+			Bids: []marketdata.Level{level("100", "0"), level("99.5", "2")},
+		},
+	)
+
+	book, ok := books.Book(trading.BTCUSD)
+	assert.True(t, ok)
+
+	bid, _ := book.BestBid()
+	assert.Equal(t, level("99.5", "2"), bid)
+
+	// A reconnect leaves the book out of sync until the stream sends a new
+	// snapshot.
+	apply(t, books, marketdata.Reconnect{Err: errors.New("connection lost")})
+
+	_, ok = books.Book(trading.BTCUSD)
+	assert.False(t, ok)
+
+	_, ok = book.BestBid()
+	assert.False(t, ok)
+}
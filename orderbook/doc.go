@@ -0,0 +1,5 @@
+// Package orderbook maintains the L2 order books of pairs from the snapshots
+// of the exchange clients and the updates of the market data streams, which
+// strategies query for the best bid and ask, the depth, the average price of
+// filling a size and the imbalance between the bids and asks.
+package orderbook
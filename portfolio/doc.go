@@ -0,0 +1,5 @@
+// Package portfolio keeps track of the positions that the fills of the bot
+// have built up, along with their cost basis and their realized and
+// unrealized profit and loss. The disposals of the lots can be exported as
+// CSV for each tax year.
+package portfolio
@@ -0,0 +1,284 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Method represents the way that the lots of a position are matched against
+// a sell, which decides the cost of what is sold.
+type Method string
+
+const (
+	// MethodFIFO sells the oldest lots first.
+	MethodFIFO Method = "FIFO"
+
+	// MethodLIFO sells the newest lots first.
+	MethodLIFO Method = "LIFO"
+
+	// MethodHIFO sells the lots with the highest cost first, which keeps the
+	// realized gains as low as possible.
+	MethodHIFO Method = "HIFO"
+
+	// MethodSpecificID sells the lots that have been identified for each sell
+	// with Identify, and the oldest lots for any part of a sell that has not.
+	MethodSpecificID Method = "SPECIFIC_ID"
+
+	// MethodAverageCost merges every buy into a single lot at the average
+	// cost of the position.
+	MethodAverageCost Method = "AVERAGE_COST"
+)
+
+// ErrUnknownMethod describes an error in which a portfolio is created with a
+// method that is not one of the known methods.
+var ErrUnknownMethod = errors.New("unknown cost basis method")
+
+// Lot represents an amount of the base asset that was bought in one go. The
+// price is the cost of each unit of the lot, including the fees of the buy.
+// The ID is the ID of the fill of the buy, or of the latest buy for a lot at
+// the average cost.
+type Lot struct {
+	ID    string
+	Time  time.Time
+	Size  trading.Amount
+	Price trading.Amount
+}
+
+// Disposal represents the part of a sell that was matched against a lot,
+// such as for reporting the realized gains for tax. The proceeds are net of
+// the part of the fee of the sell that belongs to the disposal. The part of a
+// sell that no lot was found for, such as holdings from before the fills that
+// were applied, is an unmatched disposal, which has no lot, acquisition time,
+// cost basis or gain.
+type Disposal struct {
+	Pair      trading.Pair
+	LotID     string
+	SellID    string
+	Acquired  time.Time
+	Disposed  time.Time
+	Size      trading.Amount
+	Proceeds  trading.Amount
+	CostBasis trading.Amount
+	Gain      trading.Amount
+	Method    Method
+	Unmatched bool
+}
+
+// Position represents the holding of the base asset of a pair, valued in the
+// quote asset. The unrealized PnL is only known once the position has been
+// marked with a price.
+type Position struct {
+	Pair          trading.Pair
+	Size          trading.Amount
+	CostBasis     trading.Amount
+	AverageCost   trading.Amount
+	RealizedPnL   trading.Amount
+	MarkPrice     trading.Amount
+	UnrealizedPnL trading.Amount
+	Lots          []Lot
+}
+
+// Snapshot represents the positions of the portfolio at a point in time.
+type Snapshot struct {
+	Time      time.Time
+	Positions []Position
+}
+
+// position holds the lots of a pair, along with the PnL that the sells of the
+// pair have realized.
+type position struct {
+	lots      []Lot
+	disposals []Disposal
+	realized  trading.Amount
+	mark      trading.Amount
+}
+
+// Portfolio builds up positions from fills, using its method to work out the
+// cost of each sell. Only the fills of long positions are tracked, so the part
+// of a sell that is larger than the position has no known cost and is left
+// out of the realized PnL, though it is still recorded as an unmatched
+// disposal. It is safe for concurrent use.
+type Portfolio struct {
+	mu         sync.Mutex
+	method     Method
+	positions  map[trading.Pair]*position
+	applied    map[string]bool
+	identified map[string][]string
+	lastFill   time.Time
+}
+
+// New acts as the default constructor for the Portfolio type. ErrUnknownMethod
+// is returned if the method is not one of the known methods.
+func New(method Method) (*Portfolio, error) {
+	switch method {
+	case MethodFIFO, MethodLIFO, MethodHIFO, MethodSpecificID, MethodAverageCost:
+	default:
+		return nil, fmt.Errorf("method %q: %w", method, ErrUnknownMethod)
+	}
+
+	return &Portfolio{
+		method:     method,
+		positions:  make(map[trading.Pair]*position),
+		applied:    make(map[string]bool),
+		identified: make(map[string][]string),
+	}, nil
+}
+
+// Apply adds the fills to the positions of the portfolio, in the order they
+// are given. Fills that have already been applied are skipped, so the same
+// fills can be applied again, such as when listing the fills since the last
+// fill time.
+func (p *Portfolio) Apply(fills ...exchange.Fill) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, f := range fills {
+		// The IDs of fills are only unique within a pair on some exchanges.
+		key := fmt.Sprintf("%s/%s/%s", f.Pair.Base, f.Pair.Quote, f.ID)
+		if p.applied[key] {
+			continue
+		}
+
+		p.applied[key] = true
+
+		if f.Time.After(p.lastFill) {
+			p.lastFill = f.Time
+		}
+
+		pos := p.position(f.Pair)
+
+		if f.Side == order.SideBuy {
+			pos.buy(f, p.method)
+		} else {
+			pos.sell(f, p.method, p.identified[f.ID])
+		}
+	}
+}
+
+// Identify sets the lots that the sell with the given fill ID is matched
+// against when the method is MethodSpecificID, by the IDs of the lots. The
+// lots are used in the order given, and must be identified before the sell is
+// applied.
+func (p *Portfolio) Identify(sellID string, lotIDs ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.identified[sellID] = lotIDs
+}
+
+// Disposals returns the disposals of every pair, in the order they happened.
+func (p *Portfolio) Disposals() []Disposal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	disposals := make([]Disposal, 0)
+
+	for _, pos := range p.positions {
+		disposals = append(disposals, pos.disposals...)
+	}
+
+	sort.SliceStable(disposals, func(i, j int) bool {
+		return disposals[i].Disposed.Before(disposals[j].Disposed)
+	})
+
+	return disposals
+}
+
+// LastFillTime returns the time of the latest fill that has been applied,
+// which is zero if there are none.
+func (p *Portfolio) LastFillTime() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastFill
+}
+
+// SetMark sets the price that the position of the pair is marked at.
+func (p *Portfolio) SetMark(pair trading.Pair, price trading.Amount) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.position(pair).mark = price
+}
+
+// Mark marks every position at the last price of its pair.
+func (p *Portfolio) Mark(ctx context.Context, feed exchange.PriceFeed) error {
+	for _, pair := range p.pairs() {
+		price, err := feed.GetLastPrice(ctx, pair)
+		if err != nil {
+			return fmt.Errorf("get last price of %s/%s: %w", pair.Base, pair.Quote, err)
+		}
+
+		amount, err := trading.ParseAmount(price)
+		if err != nil {
+			return fmt.Errorf("parse price: %w", err)
+		}
+
+		p.SetMark(pair, amount)
+	}
+
+	return nil
+}
+
+// Position returns the position of the pair.
+func (p *Portfolio) Position(pair trading.Pair) Position {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pos, exists := p.positions[pair]
+	if !exists {
+		return Position{Pair: pair}
+	}
+
+	return pos.snapshot(pair)
+}
+
+// Snapshot returns every position of the portfolio, ordered by pair.
+func (p *Portfolio) Snapshot(now time.Time) Snapshot {
+	positions := make([]Position, 0)
+
+	for _, pair := range p.pairs() {
+		positions = append(positions, p.Position(pair))
+	}
+
+	return Snapshot{Time: now, Positions: positions}
+}
+
+func (p *Portfolio) pairs() []trading.Pair {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pairs := make([]trading.Pair, 0, len(p.positions))
+
+	for pair := range p.positions {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Base != pairs[j].Base {
+			return pairs[i].Base < pairs[j].Base
+		}
+
+		return pairs[i].Quote < pairs[j].Quote
+	})
+
+	return pairs
+}
+
+func (p *Portfolio) position(pair trading.Pair) *position {
+	pos, exists := p.positions[pair]
+	if !exists {
+		pos = &position{}
+		p.positions[pair] = pos
+	}
+
+	return pos
+}
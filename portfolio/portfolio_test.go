@@ -0,0 +1,246 @@
+package portfolio_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/portfolio"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+var start = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func fill(id string, side order.Side, price string, size string, fee string, minutes int) exchange.Fill {
+	return exchange.Fill{
+		ID:       id,
+		Pair:     trading.BTCUSD,
+		Side:     side,
+		Price:    trading.MustParseAmount(price),
+		Size:     trading.MustParseAmount(size),
+		Fee:      trading.MustParseAmount(fee),
+		FeeAsset: trading.USD,
+		Time:     start.Add(time.Duration(minutes) * time.Minute),
+	}
+}
+
+func TestPortfolioMethods(t *testing.T) {
+	testCases := []struct {
+		method     portfolio.Method
+		realized   string
+		costBasis  string
+		unrealized string
+		lots       []portfolio.Lot
+	}{
+		{
+			method:     portfolio.MethodFIFO,
+			realized:   "149",
+			costBasis:  "200",
+			unrealized: "100",
+			lots: []portfolio.Lot{
+				{Time: start.Add(time.Minute), Size: trading.MustParseAmount("1"), Price: trading.MustParseAmount("200")},
+			},
+		},
+		{
+			method:     portfolio.MethodLIFO,
+			realized:   "50",
+			costBasis:  "101",
+			unrealized: "199",
+			lots: []portfolio.Lot{
+				{Time: start, Size: trading.MustParseAmount("1"), Price: trading.MustParseAmount("101")},
+			},
+		},
+		{
+			method:     portfolio.MethodHIFO,
+			realized:   "50",
+			costBasis:  "101",
+			unrealized: "199",
+			lots: []portfolio.Lot{
+				{Time: start, Size: trading.MustParseAmount("1"), Price: trading.MustParseAmount("101")},
+			},
+		},
+		{
+			method:     portfolio.MethodSpecificID,
+			realized:   "149",
+			costBasis:  "200",
+			unrealized: "100",
+			lots: []portfolio.Lot{
+				{Time: start.Add(time.Minute), Size: trading.MustParseAmount("1"), Price: trading.MustParseAmount("200")},
+			},
+		},
+		{
+			method:     portfolio.MethodAverageCost,
+			realized:   "99.5",
+			costBasis:  "150.5",
+			unrealized: "149.5",
+			lots: []portfolio.Lot{
+				{Time: start.Add(time.Minute), Size: trading.MustParseAmount("1"), Price: trading.MustParseAmount("150.5")},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(string(tt.method), func(t *testing.T) {
+			p, err := portfolio.New(tt.method)
+			assert.NoError(t, err)
+
+			p.Apply(
+				fill("1", order.SideBuy, "100", "1", "1", 0),
+				fill("2", order.SideBuy, "200", "1", "0", 1),
+				fill("3", order.SideSell, "250", "1", "0", 2),
+			)
+			p.SetMark(trading.BTCUSD, trading.MustParseAmount("300"))
+
+			pos := p.Position(trading.BTCUSD)
+
+			assert.Zero(t, pos.Size.Cmp(trading.MustParseAmount("1")), pos.Size.String())
+			assert.Zero(t, pos.RealizedPnL.Cmp(trading.MustParseAmount(tt.realized)), pos.RealizedPnL.String())
+			assert.Zero(t, pos.CostBasis.Cmp(trading.MustParseAmount(tt.costBasis)), pos.CostBasis.String())
+			assert.Zero(t, pos.AverageCost.Cmp(trading.MustParseAmount(tt.costBasis)), pos.AverageCost.String())
+			assert.Zero(t, pos.UnrealizedPnL.Cmp(trading.MustParseAmount(tt.unrealized)), pos.UnrealizedPnL.String())
+			assert.Len(t, pos.Lots, len(tt.lots))
+
+			for i, lot := range tt.lots {
+				assert.Equal(t, lot.Time, pos.Lots[i].Time)
+				assert.Zero(t, lot.Size.Cmp(pos.Lots[i].Size), pos.Lots[i].Size.String())
+				assert.Zero(t, lot.Price.Cmp(pos.Lots[i].Price), pos.Lots[i].Price.String())
+			}
+		})
+	}
+}
+
+func TestPortfolioSpecificID(t *testing.T) {
+	p, err := portfolio.New(portfolio.MethodSpecificID)
+	assert.NoError(t, err)
+
+	p.Identify("4", "2")
+	p.Apply(
+		fill("1", order.SideBuy, "100", "1", "0", 0),
+		fill("2", order.SideBuy, "300", "1", "0", 1),
+		fill("3", order.SideBuy, "200", "1", "0", 2),
+		fill("4", order.SideSell, "250", "1.5", "0", 3),
+	)
+
+	disposals := p.Disposals()
+	assert.Len(t, disposals, 2)
+	assert.Equal(t, "2", disposals[0].LotID)
+	assert.Zero(t, disposals[0].Gain.Cmp(trading.MustParseAmount("-50")), disposals[0].Gain.String())
+	assert.Equal(t, "1", disposals[1].LotID)
+	assert.Zero(t, disposals[1].Size.Cmp(trading.MustParseAmount("0.5")), disposals[1].Size.String())
+	assert.Zero(t, disposals[1].Gain.Cmp(trading.MustParseAmount("75")), disposals[1].Gain.String())
+
+	pos := p.Position(trading.BTCUSD)
+	assert.Zero(t, pos.RealizedPnL.Cmp(trading.MustParseAmount("25")), pos.RealizedPnL.String())
+	assert.Len(t, pos.Lots, 2)
+	assert.Equal(t, "1", pos.Lots[0].ID)
+	assert.Equal(t, "3", pos.Lots[1].ID)
+}
+
+func TestPortfolioDisposals(t *testing.T) {
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	p.Apply(
+		fill("1", order.SideBuy, "100", "1", "0", 0),
+		fill("2", order.SideBuy, "200", "1", "0", 1),
+		fill("3", order.SideSell, "250", "2", "10", 2),
+	)
+
+	disposals := p.Disposals()
+	assert.Len(t, disposals, 2)
+
+	for i, d := range disposals {
+		assert.Equal(t, trading.BTCUSD, d.Pair)
+		assert.Equal(t, "3", d.SellID)
+		assert.Equal(t, start.Add(time.Duration(i)*time.Minute), d.Acquired)
+		assert.Equal(t, start.Add(2*time.Minute), d.Disposed)
+		assert.Equal(t, portfolio.MethodFIFO, d.Method)
+		assert.Zero(t, d.Proceeds.Cmp(trading.MustParseAmount("245")), d.Proceeds.String())
+	}
+
+	assert.Zero(t, disposals[0].CostBasis.Cmp(trading.MustParseAmount("100")), disposals[0].CostBasis.String())
+	assert.Zero(t, disposals[0].Gain.Cmp(trading.MustParseAmount("145")), disposals[0].Gain.String())
+	assert.Zero(t, disposals[1].Gain.Cmp(trading.MustParseAmount("45")), disposals[1].Gain.String())
+}
+
+func TestPortfolioApplyIsIdempotent(t *testing.T) {
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	buy := fill("1", order.SideBuy, "100", "1", "0", 0)
+
+	p.Apply(buy)
+	p.Apply(buy, fill("2", order.SideBuy, "100", "1", "0", 5))
+
+	assert.Zero(t, p.Position(trading.BTCUSD).Size.Cmp(trading.MustParseAmount("2")))
+	assert.Equal(t, start.Add(5*time.Minute), p.LastFillTime())
+
+	other := buy
+	other.Pair = trading.ETHUSD
+
+	p.Apply(other)
+	assert.Zero(t, p.Position(trading.ETHUSD).Size.Cmp(trading.MustParseAmount("1")),
+		"fills with the same ID on another pair should be applied")
+}
+
+func TestPortfolioOversold(t *testing.T) {
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	p.Apply(
+		fill("1", order.SideBuy, "100", "2", "0", 0),
+		fill("2", order.SideSell, "150", "4", "6", 1),
+	)
+
+	pos := p.Position(trading.BTCUSD)
+
+	// Only half of the sell is matched against the position, so only half of
+	// its proceeds and fee are realized.
+	assert.True(t, pos.Size.IsZero())
+	assert.Empty(t, pos.Lots)
+	assert.Zero(t, pos.RealizedPnL.Cmp(trading.MustParseAmount("97")), pos.RealizedPnL.String())
+}
+
+func TestPortfolioFeeInBaseAsset(t *testing.T) {
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	buy := fill("1", order.SideBuy, "99", "1", "0.01", 0)
+	buy.FeeAsset = trading.BTC
+
+	p.Apply(buy)
+
+	pos := p.Position(trading.BTCUSD)
+	assert.Zero(t, pos.Size.Cmp(trading.MustParseAmount("0.99")), pos.Size.String())
+	assert.Zero(t, pos.AverageCost.Cmp(trading.MustParseAmount("100")), pos.AverageCost.String())
+	assert.Zero(t, pos.CostBasis.Cmp(trading.MustParseAmount("99")), pos.CostBasis.String())
+}
+
+func TestPortfolioMark(t *testing.T) {
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	p.Apply(fill("1", order.SideBuy, "100", "1", "0", 0))
+
+	feed := exchange.NewRecordedPrices(map[trading.Pair][]string{trading.BTCUSD: {"90.00"}})
+
+	assert.NoError(t, p.Mark(context.Background(), feed))
+
+	snapshot := p.Snapshot(start)
+	assert.Equal(t, start, snapshot.Time)
+	assert.Len(t, snapshot.Positions, 1)
+	assert.Zero(t, snapshot.Positions[0].UnrealizedPnL.Cmp(trading.MustParseAmount("-10")))
+
+	assert.ErrorIs(t, p.Mark(context.Background(), feed), exchange.ErrPriceFeedEnded)
+}
+
+func TestNewUnknownMethod(t *testing.T) {
+	_, err := portfolio.New("LOFO")
+	assert.ErrorIs(t, err, portfolio.ErrUnknownMethod)
+}
@@ -0,0 +1,186 @@
+package portfolio
+
+import (
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// priceScale returns the number of decimal places that the price of a lot is
+// kept to, which is enough for the cost of the smallest unit of the base
+// asset to be kept to the smallest unit of the quote asset.
+func priceScale(pair trading.Pair) int {
+	return pair.Base.Decimals() + pair.Quote.Decimals()
+}
+
+// buy adds the fill as a lot. Fees in the quote asset are added to the cost
+// of the lot, while fees in the base asset are taken from its size. Fees in
+// any other asset are not counted.
+func (p *position) buy(f exchange.Fill, method Method) {
+	size, cost := f.Size, f.Price.Mul(f.Size)
+
+	switch f.FeeAsset {
+	case f.Pair.Quote:
+		cost = cost.Add(f.Fee)
+	case f.Pair.Base:
+		size = size.Sub(f.Fee)
+	}
+
+	if size.Sign() <= 0 {
+		return
+	}
+
+	if method == MethodAverageCost && len(p.lots) > 0 {
+		lot := p.lots[0]
+		size, cost = size.Add(lot.Size), cost.Add(lot.Price.Mul(lot.Size))
+		p.lots = p.lots[:0]
+	}
+
+	// The size is above zero, so there is no division by zero.
+	price, _ := cost.Div(size, priceScale(f.Pair), trading.RoundHalfEven)
+
+	p.lots = append(p.lots, Lot{ID: f.ID, Time: f.Time, Size: size, Price: price})
+}
+
+// sell takes the size of the fill from the lots in the order of the method,
+// and realizes the proceeds of the fill less the cost of the lots, recording
+// a disposal for each lot. Fees in either asset of the pair are taken from
+// the proceeds. Any part of the fill that no lot is left for is recorded as an
+// unmatched disposal, which is not realized as its cost is unknown.
+func (p *position) sell(f exchange.Fill, method Method, identified []string) {
+	fee := trading.Amount{}
+
+	switch f.FeeAsset {
+	case f.Pair.Quote:
+		fee = f.Fee
+	case f.Pair.Base:
+		fee = f.Fee.Mul(f.Price)
+	}
+
+	remaining := f.Size
+
+	for remaining.Sign() > 0 && len(p.lots) > 0 {
+		i := p.nextLot(method, identified)
+		lot := &p.lots[i]
+
+		taken := lot.Size
+		if taken.Cmp(remaining) > 0 {
+			taken = remaining
+		}
+
+		d := Disposal{
+			Pair:      f.Pair,
+			LotID:     lot.ID,
+			SellID:    f.ID,
+			Acquired:  lot.Time,
+			Disposed:  f.Time,
+			Size:      taken,
+			Proceeds:  proceeds(f, fee, taken),
+			CostBasis: lot.Price.Mul(taken),
+			Method:    method,
+		}
+		d.Gain = d.Proceeds.Sub(d.CostBasis)
+
+		p.disposals = append(p.disposals, d)
+		p.realized = p.realized.Add(d.Gain)
+
+		remaining = remaining.Sub(taken)
+		lot.Size = lot.Size.Sub(taken)
+
+		if lot.Size.Sign() == 0 {
+			p.lots = append(p.lots[:i], p.lots[i+1:]...)
+		}
+	}
+
+	// The rest of the sell has no lot, such as holdings from before the
+	// fills that were applied, which is still reported.
+	if remaining.Sign() > 0 {
+		p.disposals = append(p.disposals, Disposal{
+			Pair:      f.Pair,
+			SellID:    f.ID,
+			Disposed:  f.Time,
+			Size:      remaining,
+			Proceeds:  proceeds(f, fee, remaining),
+			Method:    method,
+			Unmatched: true,
+		})
+	}
+}
+
+// proceeds returns the proceeds of the part of the fill, less the part of the
+// fee that belongs to it.
+func proceeds(f exchange.Fill, fee trading.Amount, size trading.Amount) trading.Amount {
+	partFee := fee
+
+	// The size of the fill is above zero as a part of it is being sold.
+	if size.Cmp(f.Size) != 0 {
+		partFee, _ = fee.Mul(size).Div(f.Size, priceScale(f.Pair), trading.RoundHalfEven)
+	}
+
+	return f.Price.Mul(size).Sub(partFee)
+}
+
+// nextLot returns the index of the lot that a sell is matched against next.
+func (p *position) nextLot(method Method, identified []string) int {
+	switch method {
+	case MethodLIFO:
+		return len(p.lots) - 1
+	case MethodHIFO:
+		highest := 0
+
+		for i, lot := range p.lots {
+			if lot.Price.Cmp(p.lots[highest].Price) > 0 {
+				highest = i
+			}
+		}
+
+		return highest
+	case MethodSpecificID:
+		for _, id := range identified {
+			for i, lot := range p.lots {
+				if lot.ID == id {
+					return i
+				}
+			}
+		}
+
+		return 0
+	default:
+		return 0
+	}
+}
+
+// snapshot returns the position, with the amounts in the quote asset rounded
+// to its decimal places.
+func (p *position) snapshot(pair trading.Pair) Position {
+	quote := pair.Quote
+
+	var size, cost trading.Amount
+
+	lots := make([]Lot, len(p.lots))
+	copy(lots, p.lots)
+
+	for _, lot := range lots {
+		size = size.Add(lot.Size)
+		cost = cost.Add(lot.Price.Mul(lot.Size))
+	}
+
+	pos := Position{
+		Pair:        pair,
+		Size:        size,
+		CostBasis:   quote.Round(cost, trading.RoundHalfEven),
+		RealizedPnL: quote.Round(p.realized, trading.RoundHalfEven),
+		MarkPrice:   p.mark,
+		Lots:        lots,
+	}
+
+	if size.Sign() > 0 {
+		// The size is above zero, so there is no division by zero.
+		pos.AverageCost, _ = cost.Div(size, quote.Decimals(), trading.RoundHalfEven)
+	}
+
+	if p.mark.Sign() > 0 {
+		pos.UnrealizedPnL = quote.Round(p.mark.Mul(size).Sub(cost), trading.RoundHalfEven)
+	}
+
+	return pos
+}
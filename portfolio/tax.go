@@ -0,0 +1,133 @@
+package portfolio
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// TaxYear represents the period that disposals are reported for, from the
+// start up to but not including the end. Tax years that do not follow the
+// calendar, such as those starting in April, can be given as they are.
+type TaxYear struct {
+	Start time.Time
+	End   time.Time
+}
+
+// CalendarYear returns the tax year that runs from the first of January of
+// the year to the first of January of the next, in the location.
+func CalendarYear(year int, loc *time.Location) TaxYear {
+	return TaxYear{
+		Start: time.Date(year, time.January, 1, 0, 0, 0, 0, loc),
+		End:   time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc),
+	}
+}
+
+// Contains reports whether the time falls within the tax year.
+func (y TaxYear) Contains(t time.Time) bool {
+	return !t.Before(y.Start) && t.Before(y.End)
+}
+
+// dateLayout is the layout of the acquisition and disposal dates in the CSV.
+const dateLayout = "2006-01-02"
+
+// WriteDisposalsCSV writes the disposals that happened in the tax year as CSV
+// with a header row. The dates are given in the location of the start of the
+// tax year, and the proceeds, cost basis and gain are rounded to the decimal
+// places of the quote asset, such that the gain is always the proceeds less
+// the cost basis. Unmatched disposals leave the acquisition date, cost basis
+// and gain empty, as they are not known.
+func WriteDisposalsCSV(w io.Writer, year TaxYear, disposals []Disposal) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"asset", "currency", "size", "acquired", "disposed", "proceeds", "cost_basis", "gain", "method",
+	}
+
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	for _, d := range disposals {
+		if !year.Contains(d.Disposed) {
+			continue
+		}
+
+		if err := writer.Write(disposalRecord(d, year.Start.Location())); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	return nil
+}
+
+func disposalRecord(d Disposal, loc *time.Location) []string {
+	quote := d.Pair.Quote
+	proceeds := quote.Round(d.Proceeds, trading.RoundHalfEven)
+	cost := quote.Round(d.CostBasis, trading.RoundHalfEven)
+
+	record := []string{
+		string(d.Pair.Base),
+		string(quote),
+		d.Size.String(),
+		d.Acquired.In(loc).Format(dateLayout),
+		d.Disposed.In(loc).Format(dateLayout),
+		proceeds.StringFixed(quote.Decimals()),
+		cost.StringFixed(quote.Decimals()),
+		proceeds.Sub(cost).StringFixed(quote.Decimals()),
+		string(d.Method),
+	}
+
+	if d.Unmatched {
+		record[3], record[6], record[7] = "", "", ""
+	}
+
+	return record
+}
+
+// FillLister represents a client that is able to list the fills of a pair,
+// i.e. exchange.Binance or exchange.Coinbase.
+type FillLister interface {
+	ListFills(ctx context.Context, p trading.Pair, since time.Time) ([]exchange.Fill, error)
+}
+
+var (
+	_ FillLister = (*exchange.Binance)(nil)
+	_ FillLister = (*exchange.Coinbase)(nil)
+	_ FillLister = (*exchange.Paper)(nil)
+)
+
+// WriteTaxReport lists every fill of the pairs, applies them to a portfolio
+// with the method, and writes the disposals that happened in the tax year as
+// CSV. Every fill is listed, rather than those of the tax year, so that the
+// disposals are matched against lots that were bought in earlier years.
+func WriteTaxReport(
+	ctx context.Context, w io.Writer, client FillLister, method Method, year TaxYear, pairs ...trading.Pair,
+) error {
+	p, err := New(method)
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		fills, err := client.ListFills(ctx, pair, time.Time{})
+		if err != nil {
+			return fmt.Errorf("list fills: %w", err)
+		}
+
+		p.Apply(fills...)
+	}
+
+	return WriteDisposalsCSV(w, year, p.Disposals())
+}
@@ -0,0 +1,131 @@
+package portfolio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/portfolio"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestCalendarYear(t *testing.T) {
+	year := portfolio.CalendarYear(2023, time.UTC)
+
+	assert.True(t, year.Contains(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, year.Contains(time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC)))
+	assert.False(t, year.Contains(time.Date(2022, 12, 31, 23, 59, 59, 0, time.UTC)))
+	assert.False(t, year.Contains(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestWriteDisposalsCSV(t *testing.T) {
+	testCases := []struct {
+		method   portfolio.Method
+		expected string
+	}{
+		{
+			method: portfolio.MethodFIFO,
+			expected: "asset,currency,size,acquired,disposed,proceeds,cost_basis,gain,method\n" +
+				"BTC,USD,1,2022-12-30,2023-01-02,249.67,100.00,149.67,FIFO\n" +
+				"BTC,USD,0.5,2023-01-02,2023-01-02,124.83,150.00,-25.17,FIFO\n",
+		},
+		{
+			method: portfolio.MethodHIFO,
+			expected: "asset,currency,size,acquired,disposed,proceeds,cost_basis,gain,method\n" +
+				"BTC,USD,1,2023-01-02,2023-01-02,249.67,300.00,-50.33,HIFO\n" +
+				"BTC,USD,0.5,2022-12-30,2023-01-02,124.83,50.00,74.83,HIFO\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(string(tt.method), func(t *testing.T) {
+			p, err := portfolio.New(tt.method)
+			assert.NoError(t, err)
+
+			p.Apply(
+				fill("1", order.SideBuy, "100", "1", "0", -3*24*60),
+				fill("2", order.SideBuy, "300", "1", "0", 0),
+				fill("3", order.SideSell, "250", "1.5", "0.5", 1),
+				fill("4", order.SideSell, "250", "0.5", "0", 366*24*60),
+			)
+
+			var buf bytes.Buffer
+
+			assert.NoError(t, portfolio.WriteDisposalsCSV(&buf, portfolio.CalendarYear(2023, time.UTC), p.Disposals()))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+type failingWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestWriteDisposalsCSVError(t *testing.T) {
+	err := portfolio.WriteDisposalsCSV(failingWriter{}, portfolio.CalendarYear(2023, time.UTC), nil)
+	assert.ErrorIs(t, err, errWrite)
+}
+
+func TestWriteDisposalsCSVUnmatched(t *testing.T) {
+	p, err := portfolio.New(portfolio.MethodFIFO)
+	assert.NoError(t, err)
+
+	// Half of the sell is of holdings from before the fills that were
+	// applied, which is reported without a cost basis.
+	p.Apply(
+		fill("1", order.SideBuy, "100", "1", "0", 0),
+		fill("2", order.SideSell, "250", "2", "10", 1),
+	)
+
+	var buf bytes.Buffer
+
+	assert.NoError(t, portfolio.WriteDisposalsCSV(&buf, portfolio.CalendarYear(2023, time.UTC), p.Disposals()))
+	assert.Equal(t, "asset,currency,size,acquired,disposed,proceeds,cost_basis,gain,method\n"+
+		"BTC,USD,1,2023-01-02,2023-01-02,245.00,100.00,145.00,FIFO\n"+
+		"BTC,USD,1,,2023-01-02,245.00,,,FIFO\n", buf.String())
+
+	assert.Zero(t, p.Position(trading.BTCUSD).RealizedPnL.Cmp(trading.MustParseAmount("145")),
+		"unmatched disposals should not be realized")
+}
+
+// fillLister is a FillLister that returns the fills of each pair.
+type fillLister map[trading.Pair][]exchange.Fill
+
+func (l fillLister) ListFills(_ context.Context, p trading.Pair, since time.Time) ([]exchange.Fill, error) {
+	if !since.IsZero() {
+		return nil, errors.New("every fill should be listed")
+	}
+
+	return l[p], nil
+}
+
+func TestWriteTaxReport(t *testing.T) {
+	lister := fillLister{trading.BTCUSD: {
+		fill("1", order.SideBuy, "100", "1", "0", -3*24*60),
+		fill("2", order.SideSell, "250", "1", "0", 0),
+	}}
+
+	var buf bytes.Buffer
+
+	err := portfolio.WriteTaxReport(context.Background(), &buf, lister, portfolio.MethodFIFO,
+		portfolio.CalendarYear(2023, time.UTC), trading.BTCUSD)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "asset,currency,size,acquired,disposed,proceeds,cost_basis,gain,method\n"+
+		"BTC,USD,1,2022-12-30,2023-01-02,250.00,100.00,150.00,FIFO\n", buf.String())
+
+	err = portfolio.WriteTaxReport(context.Background(), &buf, lister, "", portfolio.CalendarYear(2023, time.UTC))
+	assert.ErrorIs(t, err, portfolio.ErrUnknownMethod)
+}
@@ -0,0 +1,4 @@
+// Package strategy provides the interface between the trading logic and the
+// application. A strategy receives market events and responds with the
+// orders that it intends to place, which the application then carries out.
+package strategy
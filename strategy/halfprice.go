@@ -0,0 +1,70 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// HalfPrice is the reference strategy of the bot. On every tick it bids a
+// fraction of the quote balance at a fraction of the last price, with a
+// post only order that is cancelled shortly after.
+type HalfPrice struct {
+	// FundUse is the fraction of the quote balance that is used for the
+	// order, i.e. 0.1 uses 10% of the balance.
+	FundUse trading.Amount
+
+	// PriceFactor is the fraction of the last price that the bid is placed
+	// at, i.e. 0.5 bids at half of the last price.
+	PriceFactor trading.Amount
+
+	// CancelAfter is how long the order is left on the book for.
+	CancelAfter time.Duration
+}
+
+// NewHalfPrice acts as the default constructor for the HalfPrice strategy.
+// The strategy uses 10% of the quote balance to bid at half the last price,
+// cancelling the order after 200ms.
+func NewHalfPrice() *HalfPrice {
+	const cancelAfter = time.Millisecond * 200
+
+	return &HalfPrice{
+		FundUse:     trading.MustParseAmount("0.1"),
+		PriceFactor: trading.MustParseAmount("0.5"),
+		CancelAfter: cancelAfter,
+	}
+}
+
+// OnTick bids for the base asset of the pair below the last price.
+func (s *HalfPrice) OnTick(ctx context.Context, account Account, tick Tick) ([]Intent, error) {
+	pair := tick.Pair
+
+	balance, err := account.GetBalance(ctx, pair.Quote)
+	if err != nil {
+		return nil, fmt.Errorf("get balance: %w", err)
+	}
+
+	quoteAmount := balance.Mul(s.FundUse)
+	desiredPrice := pair.Quote.Round(tick.Price.Mul(s.PriceFactor), trading.RoundDown)
+
+	baseSize, err := quoteAmount.Div(desiredPrice, pair.Base.Decimals(), trading.RoundDown)
+	if err != nil {
+		return nil, fmt.Errorf("base size: %w", err)
+	}
+
+	return []Intent{
+		PlaceLimit{
+			Order: order.Limit{
+				Pair:     pair,
+				Side:     order.SideBuy,
+				BaseSize: baseSize,
+				Price:    desiredPrice,
+				PostOnly: true,
+			},
+			CancelAfter: s.CancelAfter,
+		},
+	}, nil
+}
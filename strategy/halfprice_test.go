@@ -0,0 +1,95 @@
+package strategy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/strategy"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+type account struct {
+	balances map[trading.Asset]trading.Amount
+	err      error
+}
+
+func (a *account) GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error) {
+	return a.balances[asset], a.err
+}
+
+func (a *account) ListOpenOrders(ctx context.Context) ([]exchange.Order, error) {
+	return nil, a.err
+}
+
+func TestHalfPrice(t *testing.T) {
+	errBalance := errors.New("balance unavailable")
+
+	type want struct {
+		intents []strategy.Intent
+		err     error
+	}
+
+	testCases := []struct {
+		name    string
+		account *account
+		tick    strategy.Tick
+		wants   want
+	}{
+		{
+			name: "bids 10% of the balance at half the price",
+			account: &account{
+				balances: map[trading.Asset]trading.Amount{trading.USD: trading.MustParseAmount("50")},
+			},
+			tick: strategy.Tick{Pair: trading.BTCUSD, Price: trading.MustParseAmount("1000.00")},
+			wants: want{
+				intents: []strategy.Intent{
+					strategy.PlaceLimit{
+						Order: order.Limit{
+							Pair:     trading.BTCUSD,
+							Side:     order.SideBuy,
+							BaseSize: trading.MustParseAmount("0.01"),
+							Price:    trading.MustParseAmount("500"),
+							PostOnly: true,
+						},
+						CancelAfter: time.Millisecond * 200,
+					},
+				},
+			},
+		},
+		{
+			name:    "balance error",
+			account: &account{err: errBalance},
+			tick:    strategy.Tick{Pair: trading.BTCUSD, Price: trading.MustParseAmount("1000.00")},
+			wants: want{
+				err: errBalance,
+			},
+		},
+		{
+			name:    "zero price",
+			account: &account{},
+			tick:    strategy.Tick{Pair: trading.BTCUSD, Price: trading.Amount{}},
+			wants: want{
+				err: trading.ErrDivisionByZero,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			s := strategy.NewHalfPrice()
+
+			intents, err := s.OnTick(context.Background(), tt.account, tt.tick)
+
+			assert.ErrorIs(t, err, tt.wants.err)
+			assert.Equal(t, tt.wants.intents, intents)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package strategy
+
+import (
+	"context"
+	"time"
+
+	"github.com/project-code-io/crypto-trading-bot-go/exchange"
+	"github.com/project-code-io/crypto-trading-bot-go/marketdata"
+	"github.com/project-code-io/crypto-trading-bot-go/order"
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+// Strategy represents the trading logic that is driven by the application.
+// Strategies should not place orders themselves, instead they return intents
+// which are carried out by the application.
+type Strategy interface {
+	OnTick(ctx context.Context, account Account, tick Tick) ([]Intent, error)
+}
+
+// Account gives a strategy read access to the state of the account on the
+// exchange.
+type Account interface {
+	GetBalance(ctx context.Context, asset trading.Asset) (trading.Amount, error)
+	ListOpenOrders(ctx context.Context) ([]exchange.Order, error)
+}
+
+// Tick represents a market event in which the last price of a pair has been
+// obtained.
+type Tick struct {
+	Pair  trading.Pair
+	Price trading.Amount
+	Time  time.Time
+}
+
+// OrderObserver represents a strategy that wants to know when the orders that
+// it placed change state, such as when they are filled. The application only
+// tracks orders for strategies that observe them, as tracking costs a request
+// to the exchange on every tick that there are open orders.
+type OrderObserver interface {
+	OnOrderUpdate(ctx context.Context, account Account, update OrderUpdate) ([]Intent, error)
+}
+
+// MarketDataObserver represents a strategy that wants the events of the
+// market data stream of the application as they arrive, such as trades and
+// changes to the order book, rather than only the last price on each tick.
+// The events are only delivered when the application has been given a
+// stream.
+type MarketDataObserver interface {
+	OnMarketData(ctx context.Context, account Account, event marketdata.Event) ([]Intent, error)
+}
+
+// OrderUpdate represents an order event in which an order has moved from the
+// Previous status to its current one, or has been filled further while
+// partially filled.
+type OrderUpdate struct {
+	Order    exchange.Order
+	Previous order.Status
+}
+
+// Intent represents an action that a strategy wants the application to
+// carry out.
+type Intent interface {
+	intent()
+}
+
+// PlaceLimit is an intent to place a limit order. The client ID of the order
+// is set by the application. If CancelAfter is set, the application cancels
+// the order once the duration has passed.
+type PlaceLimit struct {
+	Order       order.Limit
+	CancelAfter time.Duration
+}
+
+// PlaceMarket is an intent to place a market order, such as to exit a
+// position straight away. The client ID of the order is set by the
+// application.
+type PlaceMarket struct {
+	Order order.Market
+}
+
+// PlaceStopLimit is an intent to place a stop limit order, such as to protect
+// a position. The client ID of the order is kept if it is set, so that the
+// strategy can cancel the stop by it, or else is set by the application. Stops
+// on exchanges that do not support them natively are watched by the
+// application, which only has the price of the pair that it trades.
+type PlaceStopLimit struct {
+	Order order.StopLimit
+}
+
+// PlaceStopMarket is an intent to place a stop market order, which is
+// carried out in the same way as PlaceStopLimit.
+type PlaceStopMarket struct {
+	Order order.StopMarket
+}
+
+// PlaceOCO is an intent to place an OCO order, such as to close a position
+// at either a profit or a loss. The client ID of the order is set by the
+// application, which links the legs itself on exchanges that do not support
+// OCO orders natively.
+type PlaceOCO struct {
+	Order order.OCO
+}
+
+// PlaceBracket is an intent to place the entry of a bracket order. The
+// application places the exit once the entry has been filled.
+type PlaceBracket struct {
+	Order order.Bracket
+}
+
+// PlaceTrailingStop is an intent to place a trailing stop order, such as to
+// protect the profit of a position as the price moves in its favour. The
+// client ID of the order is kept if it is set, so that the strategy can cancel
+// the stop by it, or else is set by the application, which tracks the best
+// price of the pair itself.
+type PlaceTrailingStop struct {
+	Order order.TrailingStop
+}
+
+// CancelOrders is an intent to cancel the orders with the given IDs, which
+// are the IDs of orders on the exchange, or the client IDs of the stops and
+// trailing stops that the application tracks itself.
+type CancelOrders struct {
+	OrderIDs []string
+}
+
+func (PlaceLimit) intent()        {}
+func (PlaceMarket) intent()       {}
+func (PlaceStopLimit) intent()    {}
+func (PlaceStopMarket) intent()   {}
+func (PlaceOCO) intent()          {}
+func (PlaceBracket) intent()      {}
+func (PlaceTrailingStop) intent() {}
+func (CancelOrders) intent()      {}
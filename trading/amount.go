@@ -0,0 +1,368 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidAmount describes an error in which a string could not be parsed
+// as an amount.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrDivisionByZero describes an error in which an amount is divided by zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// RoundingMode specifies how an amount is rounded when digits are removed
+// from it.
+type RoundingMode int
+
+const (
+	// RoundDown rounds towards zero, truncating the removed digits.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+	// RoundHalfUp rounds to the nearest neighbour, with ties rounded away
+	// from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest neighbour, with ties rounded to the
+	// even neighbour. This is also known as bankers rounding.
+	RoundHalfEven
+)
+
+// Amount represents an exact decimal number, such as a price, a size or a
+// balance. The value of an amount is its coefficient multiplied by ten to the
+// power of minus its scale, i.e. a coefficient of 5001 with a scale of 2 is
+// 50.01. The coefficient is a big integer, so that amounts of assets with
+// many decimal places, such as ETH, cannot overflow. Amounts are immutable
+// and are always stored without trailing zeros. The zero value is an amount
+// of zero.
+type Amount struct {
+	// coef is nil when the amount is zero, so that equal amounts are also
+	// deeply equal.
+	coef  *big.Int
+	scale int
+}
+
+const decimalBase = 10
+
+// NewAmount creates an amount from a coefficient and a scale, i.e. a
+// coefficient of 5001 with a scale of 2 is 50.01.
+func NewAmount(coef int64, scale int) Amount {
+	return NewAmountFromBig(big.NewInt(coef), scale)
+}
+
+// NewAmountFromBig creates an amount from a big integer coefficient and a
+// scale. The coefficient is copied, so it can be changed afterwards without
+// changing the amount.
+func NewAmountFromBig(coef *big.Int, scale int) Amount {
+	c := new(big.Int).Set(coef)
+
+	if scale < 0 {
+		c.Mul(c, pow10(-scale))
+		scale = 0
+	}
+
+	return Amount{coef: c, scale: scale}.normalize()
+}
+
+// ParseAmount parses a decimal string, such as "-50.01", into an amount. The
+// string is parsed exactly, without going through a floating point number.
+func ParseAmount(s string) (Amount, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return Amount{}, fmt.Errorf("%q: %w", s, ErrInvalidAmount)
+	}
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return Amount{}, fmt.Errorf("%q: %w", s, ErrInvalidAmount)
+	}
+
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return Amount{}, fmt.Errorf("%q: %w", s, ErrInvalidAmount)
+		}
+	}
+
+	coef, ok := new(big.Int).SetString(whole+frac, decimalBase)
+	if !ok {
+		return Amount{}, fmt.Errorf("%q: %w", s, ErrInvalidAmount)
+	}
+
+	if strings.HasPrefix(s, "-") {
+		coef.Neg(coef)
+	}
+
+	return Amount{coef: coef, scale: len(frac)}.normalize(), nil
+}
+
+// MustParseAmount parses a decimal string into an amount, panicking if the
+// string is not a valid amount. Use this method for amounts which are known
+// to be valid, such as constants.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+// normalize removes the trailing zeros from the amount. The coefficient of
+// the amount must not be shared, as it is changed in place.
+func (a Amount) normalize() Amount {
+	if a.coef == nil || a.coef.Sign() == 0 {
+		return Amount{}
+	}
+
+	ten := big.NewInt(decimalBase)
+	quo, rem := new(big.Int), new(big.Int)
+
+	for a.scale > 0 {
+		quo.QuoRem(a.coef, ten, rem)
+		if rem.Sign() != 0 {
+			break
+		}
+
+		a.coef.Set(quo)
+		a.scale--
+	}
+
+	return a
+}
+
+// bigInt returns the coefficient of the amount, which is never nil.
+func (a Amount) bigInt() *big.Int {
+	if a.coef == nil {
+		return new(big.Int)
+	}
+
+	return a.coef
+}
+
+// rescale returns a copy of the coefficient of the amount at a larger scale.
+func (a Amount) rescale(scale int) *big.Int {
+	return new(big.Int).Mul(a.bigInt(), pow10(scale-a.scale))
+}
+
+// Scale returns the number of digits after the decimal point of the amount.
+func (a Amount) Scale() int {
+	return a.scale
+}
+
+// Sign returns -1 if the amount is negative, 0 if it is zero and +1 if it is
+// positive.
+func (a Amount) Sign() int {
+	return a.bigInt().Sign()
+}
+
+// IsZero reports whether the amount is zero.
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Neg returns the negated amount.
+func (a Amount) Neg() Amount {
+	return Amount{coef: new(big.Int).Neg(a.bigInt()), scale: a.scale}.normalize()
+}
+
+// Abs returns the absolute value of the amount.
+func (a Amount) Abs() Amount {
+	return Amount{coef: new(big.Int).Abs(a.bigInt()), scale: a.scale}.normalize()
+}
+
+// Cmp compares two amounts, returning -1 if a is less than b, 0 if they are
+// equal and +1 if a is greater than b.
+func (a Amount) Cmp(b Amount) int {
+	scale := maxInt(a.scale, b.scale)
+
+	return a.rescale(scale).Cmp(b.rescale(scale))
+}
+
+// Add returns the sum of the amounts.
+func (a Amount) Add(b Amount) Amount {
+	scale := maxInt(a.scale, b.scale)
+	sum := a.rescale(scale)
+
+	return Amount{coef: sum.Add(sum, b.rescale(scale)), scale: scale}.normalize()
+}
+
+// Sub returns the difference of the amounts.
+func (a Amount) Sub(b Amount) Amount {
+	return a.Add(b.Neg())
+}
+
+// Mul returns the product of the amounts. The product is exact, so its scale
+// is the sum of the scales of the amounts.
+func (a Amount) Mul(b Amount) Amount {
+	return Amount{coef: new(big.Int).Mul(a.bigInt(), b.bigInt()), scale: a.scale + b.scale}.normalize()
+}
+
+// Div returns the quotient of the amounts, rounded to the given scale with
+// the rounding mode.
+func (a Amount) Div(b Amount, scale int, mode RoundingMode) (Amount, error) {
+	if b.IsZero() {
+		return Amount{}, ErrDivisionByZero
+	}
+
+	// Both sides are scaled so that the quotient has one more digit than
+	// needed, which along with the sign of the remainder is enough to round.
+	num := new(big.Int).Set(a.bigInt())
+	den := new(big.Int).Set(b.bigInt())
+
+	if shift := scale + 1 - a.scale + b.scale; shift > 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// A remainder means that the digits beyond the extra one are not all
+	// zeros, which is kept track of by appending a digit to the quotient.
+	if rem.Sign() == 0 {
+		return Amount{coef: quo, scale: scale + 1}.Round(scale, mode), nil
+	}
+
+	quo.Mul(quo, big.NewInt(decimalBase))
+	quo.Add(quo, big.NewInt(int64(num.Sign()*den.Sign())))
+
+	return Amount{coef: quo, scale: scale + 2}.Round(scale, mode), nil
+}
+
+// Round returns the amount rounded to the given number of digits after the
+// decimal point, using the rounding mode.
+func (a Amount) Round(scale int, mode RoundingMode) Amount {
+	if scale < 0 {
+		scale = 0
+	}
+
+	if a.scale <= scale {
+		return a
+	}
+
+	d := pow10(a.scale - scale)
+	quo, rem := new(big.Int).QuoRem(a.bigInt(), d, new(big.Int))
+
+	// The removed digits are compared against half of the value of the last
+	// remaining digit.
+	twice := new(big.Int).Abs(rem)
+	half := twice.Lsh(twice, 1).Cmp(d)
+
+	if rem.Sign() != 0 && roundAway(mode, a.Sign(), half, quo.Bit(0) == 1) {
+		quo.Add(quo, big.NewInt(int64(a.Sign())))
+	}
+
+	return Amount{coef: quo, scale: scale}.normalize()
+}
+
+// RoundToStep returns the amount rounded to a multiple of the step using the
+// rounding mode, such as to the tick size of a market. The amount is returned
+// as is if the step is not positive.
+func (a Amount) RoundToStep(step Amount, mode RoundingMode) Amount {
+	if step.Sign() <= 0 {
+		return a
+	}
+
+	steps, _ := a.Div(step, 0, mode)
+
+	return steps.Mul(step)
+}
+
+// roundAway reports whether a truncated value should be rounded away from
+// zero under the rounding mode.
+func roundAway(mode RoundingMode, sign int, half int, odd bool) bool {
+	switch mode {
+	case RoundUp:
+		return true
+	case RoundFloor:
+		return sign < 0
+	case RoundCeiling:
+		return sign > 0
+	case RoundHalfUp:
+		return half >= 0
+	case RoundHalfEven:
+		return half > 0 || half == 0 && odd
+	default:
+		return false
+	}
+}
+
+// Float64 returns the nearest floating point number to the amount. Use this
+// method only for statistics, where an approximation is good enough.
+func (a Amount) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(a.bigInt(), pow10(a.scale)).Float64()
+
+	return f
+}
+
+// String formats the amount as a decimal string without trailing zeros,
+// i.e. "50.01" or "500".
+func (a Amount) String() string {
+	return a.StringFixed(a.scale)
+}
+
+// StringFixed formats the amount as a decimal string with exactly the given
+// number of digits after the decimal point, rounding half to even if digits
+// need to be removed.
+func (a Amount) StringFixed(places int) string {
+	r := a.Round(places, RoundHalfEven)
+
+	prefix := ""
+	if r.Sign() < 0 {
+		prefix = "-"
+	}
+
+	digits := new(big.Int).Abs(r.bigInt()).Text(decimalBase)
+	if places <= 0 {
+		return prefix + digits
+	}
+
+	digits = strings.Repeat("0", maxInt(0, r.scale-len(digits)+1)) + digits + strings.Repeat("0", places-r.scale)
+	point := len(digits) - places
+
+	return prefix + digits[:point] + "." + digits[point:]
+}
+
+// MarshalText encodes the amount as a decimal string, which is also how it is
+// encoded in JSON.
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses a decimal string into the amount. An empty string is
+// parsed as zero, as exchanges use them for amounts that are not set.
+func (a *Amount) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*a = Amount{}
+
+		return nil
+	}
+
+	parsed, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(decimalBase), big.NewInt(int64(n)), nil)
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,377 @@
+package trading_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestParseAmount(t *testing.T) {
+	type want struct {
+		err error
+		res string
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected want
+	}{
+		{
+			name:     "whole number",
+			input:    "500",
+			expected: want{res: "500"},
+		},
+		{
+			name:     "decimal",
+			input:    "50.01",
+			expected: want{res: "50.01"},
+		},
+		{
+			name:     "trailing zeros are removed",
+			input:    "17000.00000000",
+			expected: want{res: "17000"},
+		},
+		{
+			name:     "negative",
+			input:    "-0.29",
+			expected: want{res: "-0.29"},
+		},
+		{
+			name:     "explicit plus sign",
+			input:    "+1.5",
+			expected: want{res: "1.5"},
+		},
+		{
+			name:     "no whole part",
+			input:    ".5",
+			expected: want{res: "0.5"},
+		},
+		{
+			name:     "no fraction part",
+			input:    "5.",
+			expected: want{res: "5"},
+		},
+		{
+			name:     "zero",
+			input:    "-0.000",
+			expected: want{res: "0"},
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: want{err: trading.ErrInvalidAmount},
+		},
+		{
+			name:     "only a point",
+			input:    ".",
+			expected: want{err: trading.ErrInvalidAmount},
+		},
+		{
+			name:     "letters",
+			input:    "abcde",
+			expected: want{err: trading.ErrInvalidAmount},
+		},
+		{
+			name:     "exponent",
+			input:    "1e8",
+			expected: want{err: trading.ErrInvalidAmount},
+		},
+		{
+			name:     "double sign",
+			input:    "--1",
+			expected: want{err: trading.ErrInvalidAmount},
+		},
+		{
+			name:     "two points",
+			input:    "1.2.3",
+			expected: want{err: trading.ErrInvalidAmount},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := trading.ParseAmount(tt.input)
+
+			assert.ErrorIs(t, err, tt.expected.err)
+
+			if tt.expected.err == nil {
+				assert.Equal(t, tt.expected.res, res.String())
+			}
+		})
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := trading.MustParseAmount("0.29")
+	b := trading.MustParseAmount("100")
+
+	assert.Equal(t, "29", a.Mul(b).String())
+	assert.Equal(t, "100.29", a.Add(b).String())
+	assert.Equal(t, "-99.71", a.Sub(b).String())
+	assert.Equal(t, "0.3", trading.MustParseAmount("0.1").Add(trading.MustParseAmount("0.2")).String())
+	assert.Equal(t, trading.MustParseAmount("1.50"), trading.NewAmount(15, 1))
+	assert.Equal(t, trading.MustParseAmount("1500"), trading.NewAmount(15, -2))
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.Equal(t, 1, b.Cmp(a))
+	assert.Equal(t, 0, a.Cmp(trading.NewAmount(29, 2)))
+	assert.Equal(t, 2, a.Scale())
+	assert.Equal(t, -1, a.Neg().Sign())
+	assert.True(t, trading.Amount{}.IsZero())
+}
+
+func TestAmountRound(t *testing.T) {
+	modes := []trading.RoundingMode{
+		trading.RoundDown,
+		trading.RoundUp,
+		trading.RoundFloor,
+		trading.RoundCeiling,
+		trading.RoundHalfUp,
+		trading.RoundHalfEven,
+	}
+
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "5.5", expected: []string{"5", "6", "5", "6", "6", "6"}},
+		{input: "2.5", expected: []string{"2", "3", "2", "3", "3", "2"}},
+		{input: "1.6", expected: []string{"1", "2", "1", "2", "2", "2"}},
+		{input: "1.1", expected: []string{"1", "2", "1", "2", "1", "1"}},
+		{input: "1.0", expected: []string{"1", "1", "1", "1", "1", "1"}},
+		{input: "-1.1", expected: []string{"-1", "-2", "-2", "-1", "-1", "-1"}},
+		{input: "-1.6", expected: []string{"-1", "-2", "-2", "-1", "-2", "-2"}},
+		{input: "-2.5", expected: []string{"-2", "-3", "-3", "-2", "-3", "-2"}},
+		{input: "-5.5", expected: []string{"-5", "-6", "-6", "-5", "-6", "-6"}},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.input, func(t *testing.T) {
+			for i, mode := range modes {
+				res := trading.MustParseAmount(tt.input).Round(0, mode)
+
+				assert.Equal(t, tt.expected[i], res.String(), "rounding mode %d", mode)
+			}
+		})
+	}
+}
+
+func TestAmountDiv(t *testing.T) {
+	type want struct {
+		err error
+		res string
+	}
+
+	testCases := []struct {
+		name     string
+		a        string
+		b        string
+		scale    int
+		mode     trading.RoundingMode
+		expected want
+	}{
+		{
+			name:     "exact",
+			a:        "5",
+			b:        "500",
+			scale:    8,
+			mode:     trading.RoundDown,
+			expected: want{res: "0.01"},
+		},
+		{
+			name:     "truncated",
+			a:        "2",
+			b:        "3",
+			scale:    4,
+			mode:     trading.RoundDown,
+			expected: want{res: "0.6666"},
+		},
+		{
+			name:     "rounded half up",
+			a:        "2",
+			b:        "3",
+			scale:    4,
+			mode:     trading.RoundHalfUp,
+			expected: want{res: "0.6667"},
+		},
+		{
+			name:     "remainder beyond a zero digit rounds up",
+			a:        "1.001",
+			b:        "1",
+			scale:    2,
+			mode:     trading.RoundUp,
+			expected: want{res: "1.01"},
+		},
+		{
+			name:     "remainder beyond a five digit is over half",
+			a:        "0.0051",
+			b:        "1",
+			scale:    2,
+			mode:     trading.RoundHalfEven,
+			expected: want{res: "0.01"},
+		},
+		{
+			name:     "exact half rounds to even",
+			a:        "0.005",
+			b:        "1",
+			scale:    2,
+			mode:     trading.RoundHalfEven,
+			expected: want{res: "0"},
+		},
+		{
+			name:     "negative rounded to floor",
+			a:        "-1",
+			b:        "3",
+			scale:    2,
+			mode:     trading.RoundFloor,
+			expected: want{res: "-0.34"},
+		},
+		{
+			name:     "division by zero",
+			a:        "1",
+			b:        "0",
+			scale:    2,
+			expected: want{err: trading.ErrDivisionByZero, res: "0"},
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := trading.MustParseAmount(tt.a).Div(trading.MustParseAmount(tt.b), tt.scale, tt.mode)
+
+			assert.ErrorIs(t, err, tt.expected.err)
+			assert.Equal(t, tt.expected.res, res.String())
+		})
+	}
+}
+
+func TestAmountRoundToStep(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		step     string
+		mode     trading.RoundingMode
+		expected string
+	}{
+		{
+			name:     "tick size rounded down",
+			input:    "17000.567",
+			step:     "0.01",
+			mode:     trading.RoundDown,
+			expected: "17000.56",
+		},
+		{
+			name:     "tick size rounded up",
+			input:    "17000.561",
+			step:     "0.01",
+			mode:     trading.RoundUp,
+			expected: "17000.57",
+		},
+		{
+			name:     "step that is not a power of ten",
+			input:    "1.37",
+			step:     "0.25",
+			mode:     trading.RoundDown,
+			expected: "1.25",
+		},
+		{
+			name:     "step larger than one",
+			input:    "1234",
+			step:     "5",
+			mode:     trading.RoundHalfUp,
+			expected: "1235",
+		},
+		{
+			name:     "already a multiple",
+			input:    "0.003",
+			step:     "0.001",
+			mode:     trading.RoundUp,
+			expected: "0.003",
+		},
+		{
+			name:     "zero step",
+			input:    "1.2345",
+			step:     "0",
+			mode:     trading.RoundDown,
+			expected: "1.2345",
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			res := trading.MustParseAmount(tt.input).RoundToStep(trading.MustParseAmount(tt.step), tt.mode)
+
+			assert.Equal(t, tt.expected, res.String())
+		})
+	}
+}
+
+func TestAmountStringFixed(t *testing.T) {
+	testCases := []struct {
+		input    string
+		places   int
+		expected string
+	}{
+		{input: "0", places: 2, expected: "0.00"},
+		{input: "0.01", places: 8, expected: "0.01000000"},
+		{input: "-0.5", places: 2, expected: "-0.50"},
+		{input: "1234.5678", places: 2, expected: "1234.57"},
+		{input: "0.125", places: 2, expected: "0.12"},
+		{input: "17000", places: 0, expected: "17000"},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, trading.MustParseAmount(tt.input).StringFixed(tt.places))
+		})
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	type body struct {
+		Price trading.Amount `json:"price"`
+	}
+
+	var b body
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"price":"0.29"}`), &b))
+	assert.Equal(t, trading.NewAmount(29, 2), b.Price)
+
+	data, err := json.Marshal(b)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price":"0.29"}`, string(data))
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"price":"abc"}`), &b), trading.ErrInvalidAmount)
+}
+
+func TestAmountBeyondInt64(t *testing.T) {
+	balance := trading.MustParseAmount("250000.123456789012345678")
+	deposit := trading.MustParseAmount("9.300000000000000001")
+
+	assert.Equal(t, "250009.423456789012345679", balance.Add(deposit).String())
+	assert.Equal(t, "249990.823456789012345677", balance.Sub(deposit).String())
+	assert.Equal(t, 1, balance.Cmp(deposit))
+
+	value := trading.ETHUSD.Value(trading.MustParseAmount("1850.25"), balance)
+	assert.Equal(t, "462562728.42", value.String())
+
+	share, err := balance.Div(trading.MustParseAmount("3"), trading.ETH.Decimals(), trading.RoundDown)
+	assert.NoError(t, err)
+	assert.Equal(t, "83333.374485596337448559", share.String())
+
+	assert.InDelta(t, 250000.123456789, balance.Float64(), 1e-9)
+	assert.Equal(t, balance, trading.MustParseAmount("250000.1234567890123456780"))
+}
@@ -2,8 +2,8 @@ package trading
 
 import (
 	"fmt"
-	"math"
-	"strconv"
+	"math/big"
+	"sync"
 )
 
 // Asset represents a coin or currency that is used in trading pairs.
@@ -18,49 +18,89 @@ const (
 	USD Asset = "USD"
 )
 
-// Decimals stores the number of decimal places that an asset has.
+const (
+	btcDecimals = 8
+	ethDecimals = 18
+	usdDecimals = 2
+)
+
+// assets holds the number of decimal places of every known asset. Assets are
+// added to it with RegisterAsset, typically from the metadata of a market
+// registry.
+var assets = struct {
+	sync.RWMutex
+	decimals map[Asset]int
+}{
+	decimals: map[Asset]int{
+		BTC: btcDecimals,
+		ETH: ethDecimals,
+		USD: usdDecimals,
+	},
+}
+
+// RegisterAsset sets the number of decimal places of an asset, so that
+// amounts of the asset can be rounded to its smallest unit. Registering an
+// asset that is already known overrides its decimal places.
+func RegisterAsset(a Asset, decimals int) {
+	assets.Lock()
+	defer assets.Unlock()
+
+	assets.decimals[a] = decimals
+}
+
+// Known reports whether the decimal places of the asset are known, either
+// because it is built in or because it has been registered.
+func (a Asset) Known() bool {
+	assets.RLock()
+	defer assets.RUnlock()
+
+	_, known := assets.decimals[a]
+
+	return known
+}
+
+// Decimals returns the number of decimal places that an asset has. Assets
+// which are not known have no decimal places.
 func (a Asset) Decimals() int {
-	switch a {
-	case BTC:
-		const BTCDecimals = 8
-		return BTCDecimals
-	case ETH:
-		const ETHDecimals = 18
-		return ETHDecimals
-	case USD:
-		const USDDecimals = 2
-		return USDDecimals
-	}
+	assets.RLock()
+	defer assets.RUnlock()
 
-	return 0
+	return assets.decimals[a]
 }
 
-const floatBits = 64
+// Amount returns the amount of the asset that the units make up, i.e. for
+// USD if the given units are 5001, then the amount is 50.01.
+func (a Asset) Amount(units *big.Int) Amount {
+	return NewAmountFromBig(units, a.Decimals())
+}
 
-// Unit returns the normalized unit value for an asset. i.e. for USD
-// if the given value is: 50.00, then the normalized value is 5000, due
-// to the number of cents in the value.
-func (a Asset) Unit(u float64) int64 {
-	return int64(u * math.Pow10(a.Decimals()))
+// Round rounds the amount to the number of decimal places of the asset,
+// using the rounding mode.
+func (a Asset) Round(amount Amount, mode RoundingMode) Amount {
+	return amount.Round(a.Decimals(), mode)
+}
+
+// Unit returns the normalized unit value for an amount of the asset. i.e.
+// for USD if the given amount is: 50.00, then the normalized value is 5000,
+// due to the number of cents in the value. Any precision beyond the decimal
+// places of the asset is truncated.
+func (a Asset) Unit(amount Amount) *big.Int {
+	return a.Round(amount, RoundDown).rescale(a.Decimals())
 }
 
 // Format will convert the units into a string that has a floating point
 // denomination.
-func (a Asset) Format(i int64) string {
-	const precision = -1
-
-	f := float64(i) * math.Pow10(a.Decimals()*-1)
-
-	return strconv.FormatFloat(f, 'f', precision, floatBits)
+func (a Asset) Format(units *big.Int) string {
+	return a.Amount(units).String()
 }
 
 // UnitStr will produce a normalized unit from a string value. See
 // Unit for more information.
-func (a Asset) UnitStr(s string) (int64, error) {
-	f, err := strconv.ParseFloat(s, floatBits)
+func (a Asset) UnitStr(s string) (*big.Int, error) {
+	amount, err := ParseAmount(s)
 	if err != nil {
-		return 0, fmt.Errorf("parsing string: %w", err)
+		return nil, fmt.Errorf("parsing string: %w", err)
 	}
 
-	return a.Unit(f), nil
+	return a.Unit(amount), nil
 }
@@ -1,7 +1,7 @@
 package trading_test
 
 import (
-	"strconv"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,20 +13,44 @@ func TestAssetUnit(t *testing.T) {
 	testCases := []struct {
 		name     string
 		asset    trading.Asset
-		input    float64
-		expected int64
+		input    trading.Amount
+		expected string
 	}{
 		{
 			name:     "USD units",
 			asset:    trading.USD,
-			input:    50.01,
-			expected: 5001,
+			input:    trading.MustParseAmount("50.01"),
+			expected: "5001",
+		},
+		{
+			name:     "USD units that are inexact as a float",
+			asset:    trading.USD,
+			input:    trading.MustParseAmount("0.29"),
+			expected: "29",
 		},
 		{
 			name:     "BTC units",
 			asset:    trading.BTC,
-			input:    0.01,
-			expected: 1000000,
+			input:    trading.MustParseAmount("0.01"),
+			expected: "1000000",
+		},
+		{
+			name:     "USD units beyond the decimal places are truncated",
+			asset:    trading.USD,
+			input:    trading.MustParseAmount("50.019"),
+			expected: "5001",
+		},
+		{
+			name:     "ETH units beyond the range of an int64",
+			asset:    trading.ETH,
+			input:    trading.MustParseAmount("123456.789"),
+			expected: "123456789000000000000000",
+		},
+		{
+			name:     "ETH units of a large token supply",
+			asset:    trading.ETH,
+			input:    trading.MustParseAmount("1000000000000.123456789012345678"),
+			expected: "1000000000000123456789012345678",
 		},
 	}
 
@@ -34,7 +58,7 @@ func TestAssetUnit(t *testing.T) {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, tt.asset.Unit(tt.input))
+			assert.Equal(t, tt.expected, tt.asset.Unit(tt.input).String())
 		})
 	}
 }
@@ -43,34 +67,48 @@ func TestAssetFormat(t *testing.T) {
 	testCases := []struct {
 		name     string
 		asset    trading.Asset
-		input    int64
+		input    string
 		expected string
 	}{
 		{
 			name:     "USD units",
 			asset:    trading.USD,
-			input:    5001,
+			input:    "5001",
 			expected: "50.01",
 		},
 		{
 			name:     "BTC units",
 			asset:    trading.BTC,
-			input:    1000000,
+			input:    "1000000",
 			expected: "0.01",
 		},
 		{
 			name:     "BTC units small",
 			asset:    trading.BTC,
-			input:    58823,
+			input:    "58823",
 			expected: "0.00058823",
 		},
+		{
+			name:     "ETH units beyond the range of an int64",
+			asset:    trading.ETH,
+			input:    "9300000000000000000",
+			expected: "9.3",
+		},
+		{
+			name:     "ETH units of a large treasury",
+			asset:    trading.ETH,
+			input:    "250000123000000000000000",
+			expected: "250000.123",
+		},
 	}
 
 	for _, tt := range testCases {
 		tt := tt
 
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, tt.asset.Format(tt.input))
+			units, ok := new(big.Int).SetString(tt.input, 10)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, tt.asset.Format(units))
 		})
 	}
 }
@@ -78,7 +116,7 @@ func TestAssetFormat(t *testing.T) {
 func TestAssetUnitStr(t *testing.T) {
 	type want struct {
 		err error
-		res int64
+		res string
 	}
 
 	testCases := []struct {
@@ -92,7 +130,7 @@ func TestAssetUnitStr(t *testing.T) {
 			asset: trading.USD,
 			input: "50.01",
 			expected: want{
-				res: 5001,
+				res: "5001",
 			},
 		},
 		{
@@ -100,7 +138,23 @@ func TestAssetUnitStr(t *testing.T) {
 			asset: trading.BTC,
 			input: "0.01",
 			expected: want{
-				res: 1000000,
+				res: "1000000",
+			},
+		},
+		{
+			name:  "USD units that are inexact as a float",
+			asset: trading.USD,
+			input: "0.29",
+			expected: want{
+				res: "29",
+			},
+		},
+		{
+			name:  "ETH units beyond the range of an int64",
+			asset: trading.ETH,
+			input: "10.000000000000000001",
+			expected: want{
+				res: "10000000000000000001",
 			},
 		},
 		{
@@ -108,7 +162,7 @@ func TestAssetUnitStr(t *testing.T) {
 			asset: trading.USD,
 			input: "abcde",
 			expected: want{
-				err: strconv.ErrSyntax,
+				err: trading.ErrInvalidAmount,
 			},
 		},
 	}
@@ -120,7 +174,23 @@ func TestAssetUnitStr(t *testing.T) {
 			res, err := tt.asset.UnitStr(tt.input)
 
 			assert.ErrorIs(t, err, tt.expected.err)
-			assert.Equal(t, tt.expected.res, res)
+
+			if tt.expected.err == nil {
+				assert.Equal(t, tt.expected.res, res.String())
+			}
 		})
 	}
 }
+
+func TestRegisterAsset(t *testing.T) {
+	asset := trading.Asset("DOT")
+
+	assert.False(t, asset.Known())
+	assert.Equal(t, 0, asset.Decimals())
+
+	trading.RegisterAsset(asset, 10)
+
+	assert.True(t, asset.Known())
+	assert.Equal(t, 10, asset.Decimals())
+	assert.Equal(t, "1.0000000001", asset.Format(big.NewInt(10000000001)))
+}
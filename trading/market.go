@@ -0,0 +1,175 @@
+package trading
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownMarket describes an error in which a pair or symbol is not in a
+// market registry.
+var ErrUnknownMarket = errors.New("unknown market")
+
+// Market describes how a pair is traded on an exchange. A zero amount for any
+// of the filters means that the exchange does not apply that filter.
+type Market struct {
+	Pair Pair
+
+	// Symbol is the name of the pair on the exchange, i.e. "BTCUSD" on
+	// binance or "BTC-USD" on coinbase.
+	Symbol string
+
+	// TickSize is the increment that the price of an order must be a
+	// multiple of.
+	TickSize Amount
+
+	// LotSize is the increment that the base size of an order must be a
+	// multiple of.
+	LotSize Amount
+
+	// MinSize is the smallest base size of an order.
+	MinSize Amount
+
+	// MinNotional is the smallest value of an order in the quote asset.
+	MinNotional Amount
+}
+
+// Registry holds the markets of a single exchange, which are looked up by
+// pair or by the exchange's symbol. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	markets map[Pair]Market
+	symbols map[string]Pair
+}
+
+// NewRegistry acts as the default constructor for the Registry type, adding
+// the given markets to it.
+func NewRegistry(markets ...Market) *Registry {
+	r := &Registry{
+		markets: make(map[Pair]Market),
+		symbols: make(map[string]Pair),
+	}
+
+	for _, m := range markets {
+		r.Add(m)
+	}
+
+	return r
+}
+
+// Add adds the market to the registry, replacing any market of the same pair.
+func (r *Registry) Add(m Market) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if old, exists := r.markets[m.Pair]; exists {
+		delete(r.symbols, old.Symbol)
+	}
+
+	r.markets[m.Pair] = m
+	r.symbols[m.Symbol] = m.Pair
+}
+
+// Market returns the market of the pair.
+func (r *Registry) Market(p Pair) (Market, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, exists := r.markets[p]
+	if !exists {
+		return Market{}, fmt.Errorf("%s/%s: %w", p.Base, p.Quote, ErrUnknownMarket)
+	}
+
+	return m, nil
+}
+
+// MarketBySymbol returns the market with the exchange's symbol.
+func (r *Registry) MarketBySymbol(symbol string) (Market, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, exists := r.symbols[symbol]
+	if !exists {
+		return Market{}, fmt.Errorf("%s: %w", symbol, ErrUnknownMarket)
+	}
+
+	return r.markets[p], nil
+}
+
+// Markets returns every market in the registry, ordered by symbol.
+func (r *Registry) Markets() []Market {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	markets := make([]Market, 0, len(r.markets))
+
+	for _, m := range r.markets {
+		markets = append(markets, m)
+	}
+
+	sort.Slice(markets, func(i, j int) bool {
+		return markets[i].Symbol < markets[j].Symbol
+	})
+
+	return markets
+}
+
+// registryConfig is the format of a registry config file.
+type registryConfig struct {
+	Assets  map[Asset]int `json:"assets"`
+	Markets []struct {
+		Base        Asset  `json:"base"`
+		Quote       Asset  `json:"quote"`
+		Symbol      string `json:"symbol"`
+		TickSize    Amount `json:"tick_size"`
+		LotSize     Amount `json:"lot_size"`
+		MinSize     Amount `json:"min_size"`
+		MinNotional Amount `json:"min_notional"`
+	} `json:"markets"`
+}
+
+// LoadRegistry reads a registry from a JSON config file. The decimal places
+// of the assets in the file are registered with RegisterAsset. The format of
+// the file is:
+//
+//	{
+//		"assets": {"SOL": 9, "USDT": 6},
+//		"markets": [{
+//			"base": "SOL",
+//			"quote": "USDT",
+//			"symbol": "SOLUSDT",
+//			"tick_size": "0.01",
+//			"lot_size": "0.001",
+//			"min_size": "0.001",
+//			"min_notional": "5"
+//		}]
+//	}
+func LoadRegistry(r io.Reader) (*Registry, error) {
+	var config registryConfig
+
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, fmt.Errorf("decode registry config: %w", err)
+	}
+
+	for asset, decimals := range config.Assets {
+		RegisterAsset(asset, decimals)
+	}
+
+	registry := NewRegistry()
+
+	for _, m := range config.Markets {
+		registry.Add(Market{
+			Pair:        Pair{Base: m.Base, Quote: m.Quote},
+			Symbol:      m.Symbol,
+			TickSize:    m.TickSize,
+			LotSize:     m.LotSize,
+			MinSize:     m.MinSize,
+			MinNotional: m.MinNotional,
+		})
+	}
+
+	return registry, nil
+}
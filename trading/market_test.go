@@ -0,0 +1,116 @@
+package trading_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/project-code-io/crypto-trading-bot-go/trading"
+)
+
+func TestRegistry(t *testing.T) {
+	btcusd := trading.Market{Pair: trading.BTCUSD, Symbol: "BTC-USD", TickSize: trading.MustParseAmount("0.01")}
+	ethusd := trading.Market{Pair: trading.ETHUSD, Symbol: "ETH-USD"}
+
+	r := trading.NewRegistry(ethusd, btcusd)
+
+	m, err := r.Market(trading.BTCUSD)
+	assert.NoError(t, err)
+	assert.Equal(t, btcusd, m)
+
+	m, err = r.MarketBySymbol("ETH-USD")
+	assert.NoError(t, err)
+	assert.Equal(t, ethusd, m)
+
+	assert.Equal(t, []trading.Market{btcusd, ethusd}, r.Markets())
+
+	_, err = r.Market(trading.Pair{Base: "SOL", Quote: trading.USD})
+	assert.ErrorIs(t, err, trading.ErrUnknownMarket)
+
+	_, err = r.MarketBySymbol("SOL-USD")
+	assert.ErrorIs(t, err, trading.ErrUnknownMarket)
+}
+
+func TestRegistryAddReplaces(t *testing.T) {
+	r := trading.NewRegistry(trading.Market{Pair: trading.BTCUSD, Symbol: "BTCUSD"})
+
+	r.Add(trading.Market{Pair: trading.BTCUSD, Symbol: "XBTUSD"})
+
+	_, err := r.MarketBySymbol("BTCUSD")
+	assert.ErrorIs(t, err, trading.ErrUnknownMarket)
+
+	m, err := r.MarketBySymbol("XBTUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, trading.BTCUSD, m.Pair)
+	assert.Len(t, r.Markets(), 1)
+}
+
+func TestLoadRegistry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []trading.Market
+		err      bool
+	}{
+		{
+			name: "markets and assets",
+			input: `{
+				"assets": {"SOL": 9, "USDT": 6},
+				"markets": [{
+					"base": "SOL",
+					"quote": "USDT",
+					"symbol": "SOLUSDT",
+					"tick_size": "0.01",
+					"lot_size": "0.001",
+					"min_size": "0.001",
+					"min_notional": "5"
+				}, {
+					"base": "BTC",
+					"quote": "USD",
+					"symbol": "BTCUSD"
+				}]
+			}`,
+			expected: []trading.Market{
+				{Pair: trading.BTCUSD, Symbol: "BTCUSD"},
+				{
+					Pair:        trading.Pair{Base: "SOL", Quote: "USDT"},
+					Symbol:      "SOLUSDT",
+					TickSize:    trading.MustParseAmount("0.01"),
+					LotSize:     trading.MustParseAmount("0.001"),
+					MinSize:     trading.MustParseAmount("0.001"),
+					MinNotional: trading.MustParseAmount("5"),
+				},
+			},
+		},
+		{
+			name:  "invalid amount",
+			input: `{"markets": [{"base": "SOL", "quote": "USDT", "symbol": "SOLUSDT", "tick_size": "abc"}]}`,
+			err:   true,
+		},
+		{
+			name:  "invalid json",
+			input: `{"markets":`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range testCases {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := trading.LoadRegistry(strings.NewReader(tt.input))
+
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, r.Markets())
+		})
+	}
+
+	assert.Equal(t, 9, trading.Asset("SOL").Decimals())
+	assert.Equal(t, 6, trading.Asset("USDT").Decimals())
+}
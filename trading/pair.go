@@ -20,3 +20,10 @@ var (
 		Quote: USD,
 	}
 )
+
+// Value returns the value in the quote asset of the size of the base asset
+// at the price. The value is truncated to the decimal places of the quote
+// asset.
+func (p Pair) Value(price Amount, size Amount) Amount {
+	return p.Quote.Round(price.Mul(size), RoundDown)
+}